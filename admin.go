@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/config"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/service"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	AdminPasswordEnv = "SPS_ADMIN_PASSWORD"
+)
+
+// runAdminCommand handles `sps admin <create|list>`. Admins are created
+// explicitly here instead of being reset from config on every boot. An
+// existing user only becomes an admin with --promote.
+func runAdminCommand(
+	ctx context.Context,
+	logger zerolog.Logger,
+	args []string,
+	cfg *config.Config,
+	authService service.AuthService,
+	permissionService service.PermissionService,
+) error {
+	if len(args) == 0 {
+		return errors.New("usage: sps admin <create|list> [flags]")
+	}
+
+	switch args[0] {
+	case "create":
+		return createAdmin(ctx, logger, args[1:], cfg, authService, permissionService)
+	case "list":
+		return listAdmins(ctx, cfg, permissionService)
+	default:
+		return fmt.Errorf("unknown admin command %q", args[0])
+	}
+}
+
+func createAdmin(
+	ctx context.Context,
+	logger zerolog.Logger,
+	args []string,
+	cfg *config.Config,
+	authService service.AuthService,
+	permissionService service.PermissionService,
+) error {
+	req := requests.CreateAdmin{}
+
+	fs := flag.NewFlagSet("admin create", flag.ContinueOnError)
+	fs.StringVar(&req.Email, "email", "", "admin email, used as login")
+	fs.StringVar(&req.Password, "password", "", "admin password, read from "+AdminPasswordEnv+" or stdin when empty")
+	fs.StringVar(&req.Name, "name", "", "admin name")
+	fs.StringVar(&req.SecondName, "second-name", "", "admin second name")
+	fs.StringVar(&req.Patronymic, "patronymic", "", "admin patronymic")
+	fs.BoolVar(&req.Promote, "promote", false, "make an existing user with the email an admin, keeping their password")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if req.Password == "" {
+		req.Password = os.Getenv(AdminPasswordEnv)
+	}
+
+	// A promoted user keeps the password, so it is only asked for when a new
+	// user may be created.
+	if req.Password == "" && !req.Promote {
+		password, err := readPassword(os.Stdin, os.Stderr)
+		if err != nil {
+			return err
+		}
+		req.Password = password
+	}
+
+	userUUID, promoted, err := authService.CreateAdmin(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if err = permissionService.AddUserToRole(ctx, userUUID, cfg.Secure.AdminRoleName); err != nil {
+		return err
+	}
+
+	if promoted {
+		logger.Warn().Str("uuid", userUUID).Str("email", req.Email).Msg("existing user promoted to admin")
+	}
+
+	fmt.Fprintf(os.Stdout, "admin %s (%s) is ready\n", req.Email, userUUID)
+	return nil
+}
+
+func listAdmins(ctx context.Context, cfg *config.Config, permissionService service.PermissionService) error {
+	members, err := permissionService.GetRoleMembersByName(ctx, cfg.Secure.AdminRoleName)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		fmt.Fprintf(os.Stdout, "%s\t%s %s %s\n", member.UUID, member.SecondName, member.Name, member.Patronymic)
+	}
+
+	return nil
+}
+
+// bootstrapAdmin creates the first admin from config when SPS_SECURE_BOOTSTRAP_ADMIN is set.
+// It does nothing once the admin role has at least one member, so passwords
+// changed through the API are never overwritten on restart.
+func bootstrapAdmin(
+	ctx context.Context,
+	logger zerolog.Logger,
+	cfg *config.Config,
+	authService service.AuthService,
+	permissionService service.PermissionService,
+) error {
+	members, err := permissionService.GetRoleMembersByName(ctx, cfg.Secure.AdminRoleName)
+	if err != nil {
+		return err
+	}
+
+	if len(members) > 0 {
+		logger.Warn().Msg("admin bootstrap skipped: admin role already has members, unset SPS_SECURE_BOOTSTRAP_ADMIN")
+		return nil
+	}
+
+	userUUID, _, err := authService.CreateAdmin(ctx, requests.CreateAdmin{
+		Email:    cfg.Secure.AdminEmail,
+		Password: cfg.Secure.AdminPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = permissionService.AddUserToRole(ctx, userUUID, cfg.Secure.AdminRoleName); err != nil {
+		return err
+	}
+
+	logger.Info().Str("uuid", userUUID).Msg("bootstrap admin created")
+	return nil
+}
+
+func readPassword(in io.Reader, out io.Writer) (string, error) {
+	fmt.Fprint(out, "password: ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
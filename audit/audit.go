@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"github.com/igntnk/scholarship_point_system/jwk"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+)
+
+// contextKey keeps the request metadata apart from the values other packages
+// put in the context.
+type contextKey string
+
+const (
+	requestIDContextKey contextKey = "request_id"
+	clientIPContextKey  contextKey = "client_ip"
+)
+
+const (
+	EntityAchievement = "achievement"
+	EntityCategory    = "category"
+	EntityGroup       = "group"
+	EntitySetting     = "setting"
+	EntityPeriod      = "period"
+	EntitySnapshot    = "rating_snapshot"
+	EntityOrgUnit     = "org_unit"
+)
+
+const (
+	ActionAchievementApprove  = "achievement.approve"
+	ActionAchievementDecline  = "achievement.decline"
+	ActionAchievementRemove   = "achievement.remove"
+	ActionAchievementResubmit = "achievement.resubmit"
+	ActionCategoryUpdate      = "category.update"
+	ActionGroupUpdate         = "group.update"
+	ActionSettingUpdate       = "setting.update"
+	ActionPeriodCreate        = "period.create"
+	ActionPeriodUpdate        = "period.update"
+	ActionPeriodDelete        = "period.delete"
+	ActionPeriodClose         = "period.close"
+	ActionSnapshotCreate      = "rating_snapshot.create"
+	ActionOrgUnitCreate       = "org_unit.create"
+	ActionOrgUnitUpdate       = "org_unit.update"
+	ActionOrgUnitDelete       = "org_unit.delete"
+	ActionOrgUnitAssign       = "org_unit.assign"
+	ActionOrgUnitUnassign     = "org_unit.unassign"
+)
+
+// Meta describes who made the request and where it came from.
+type Meta struct {
+	ActorUUID string
+	IP        string
+	RequestID string
+}
+
+func WithRequest(ctx context.Context, requestID, ip string) context.Context {
+	if c, ok := ctx.(interface{ Set(any, any) }); ok {
+		c.Set(requestIDContextKey, requestID)
+		c.Set(clientIPContextKey, ip)
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, requestIDContextKey, requestID)
+	return context.WithValue(ctx, clientIPContextKey, ip)
+}
+
+func MetaFromContext(ctx context.Context) Meta {
+	meta := Meta{}
+
+	if claims := jwk.ClaimsFromContext(ctx); claims != nil {
+		meta.ActorUUID = claims.User.UUID
+	}
+
+	if requestID, ok := contextValue(ctx, requestIDContextKey).(string); ok {
+		meta.RequestID = requestID
+	}
+
+	if ip, ok := contextValue(ctx, clientIPContextKey).(string); ok {
+		meta.IP = ip
+	}
+
+	return meta
+}
+
+// contextValue reads a value set by WithRequest. The gin context only looks up
+// string keys in Value, so its own storage is read directly.
+func contextValue(ctx context.Context, key contextKey) any {
+	if c, ok := ctx.(interface{ Get(any) (any, bool) }); ok {
+		if value, exists := c.Get(key); exists {
+			return value
+		}
+	}
+
+	return ctx.Value(key)
+}
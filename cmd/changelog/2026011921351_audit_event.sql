@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table audit_event
+(
+    uuid        uuid primary key     default uuid_generate_v4(),
+    actor_uuid  uuid references sys_user (uuid),
+    action      varchar(100) not null,
+    entity_type varchar(100) not null,
+    entity_id   varchar(100) not null,
+    before_data jsonb,
+    after_data  jsonb,
+    ip          varchar(64),
+    request_id  varchar(100),
+    created_at  timestamptz  not null default now()
+);
+
+create index audit_event_entity_idx on audit_event (entity_type, entity_id);
+create index audit_event_created_at_idx on audit_event (created_at);
+
+create function audit_event_append_only() returns trigger as
+$$
+begin
+    raise exception 'audit_event is append-only';
+end;
+$$ language plpgsql;
+
+create trigger audit_event_append_only
+    before update or delete
+    on audit_event
+    for each row
+execute function audit_event_append_only();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop trigger audit_event_append_only on audit_event;
+drop function audit_event_append_only();
+drop table audit_event;
+
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table achievement_review
+(
+    uuid             uuid primary key     default uuid_generate_v4(),
+    achievement_uuid uuid references achievement (uuid) not null,
+    reviewer_uuid    uuid references sys_user (uuid),
+    from_status_uuid uuid references status (uuid),
+    to_status_uuid   uuid references status (uuid)      not null,
+    comment          text,
+    created_at       timestamptz not null default now()
+);
+
+create index achievement_review_achievement_idx on achievement_review (achievement_uuid, created_at);
+
+insert into resource (value)
+select 'PUT - /achievement/resubmit/:var'
+where not exists (select 1 from resource where value = 'PUT - /achievement/resubmit/:var');
+
+insert into group_resource (group_uuid, resource_uuid)
+values ((select uuid from auth_group where name = 'Пользователи'),
+        (select uuid from resource where value = 'PUT - /achievement/resubmit/:var'));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+delete
+from group_resource
+where resource_uuid = (select uuid from resource where value = 'PUT - /achievement/resubmit/:var');
+delete
+from resource
+where value = 'PUT - /achievement/resubmit/:var';
+drop table achievement_review;
+
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table achievement
+    add column created_at timestamptz;
+
+-- The earliest recorded trace of an existing achievement is the closest to
+-- its submission, the achievement date is the last resort.
+update achievement a
+set created_at = coalesce(
+        least((select min(e.created_at)
+               from audit_event e
+               where e.entity_type = 'achievement'
+                 and e.entity_id = a.uuid::varchar),
+              (select min(r.created_at)
+               from achievement_review r
+               where r.achievement_uuid = a.uuid)),
+        a.achievement_date::timestamptz);
+
+alter table achievement
+    alter column created_at set default now(),
+    alter column created_at set not null;
+
+create index achievement_status_created_idx on achievement (status_uuid, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index achievement_status_created_idx;
+alter table achievement
+    drop column created_at;
+
+-- +goose StatementEnd
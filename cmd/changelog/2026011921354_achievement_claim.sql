@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table achievement_claim
+(
+    achievement_uuid uuid primary key references achievement (uuid),
+    reviewer_uuid    uuid references sys_user (uuid) not null,
+    claimed_at       timestamptz                     not null default now(),
+    expires_at       timestamptz                     not null
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop table achievement_claim;
+
+-- +goose StatementEnd
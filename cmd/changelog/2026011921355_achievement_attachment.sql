@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table achievement_attachment
+(
+    uuid             uuid primary key,
+    achievement_uuid uuid references achievement (uuid) not null,
+    file_name        varchar                            not null,
+    content_type     varchar                            not null,
+    size             bigint                             not null,
+    storage_key      varchar                            not null unique,
+    uploaded_by      uuid references sys_user (uuid),
+    created_at       timestamptz                        not null default now()
+);
+
+create index achievement_attachment_achievement_idx on achievement_attachment (achievement_uuid);
+
+insert into resource (value)
+select v.value
+from (values ('POST - /achievement/:var/attachments'),
+       ('GET - /achievement/:var/attachments'),
+       ('GET - /achievement/:var/attachments/:var'),
+       ('DELETE - /achievement/:var/attachments/:var')) as v(value)
+where not exists (select 1 from resource r where r.value = v.value);
+
+insert into group_resource (group_uuid, resource_uuid)
+select (select uuid from auth_group where name = 'Пользователи'), r.uuid
+from resource r
+where r.value in ('POST - /achievement/:var/attachments',
+                'GET - /achievement/:var/attachments',
+                'GET - /achievement/:var/attachments/:var',
+                'DELETE - /achievement/:var/attachments/:var');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+delete
+from group_resource
+where resource_uuid in (select uuid
+                        from resource
+                        where value in ('POST - /achievement/:var/attachments',
+                'GET - /achievement/:var/attachments',
+                'GET - /achievement/:var/attachments/:var',
+                'DELETE - /achievement/:var/attachments/:var'));
+drop table achievement_attachment;
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table achievement_attachment
+    add column sha256 varchar(64);
+
+create index achievement_attachment_sha256_idx on achievement_attachment (sha256);
+
+alter table achievement
+    add column attachment_link_normalized varchar;
+
+create index achievement_attachment_link_normalized_idx on achievement (attachment_link_normalized);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index achievement_attachment_link_normalized_idx;
+alter table achievement
+    drop column attachment_link_normalized;
+
+drop index achievement_attachment_sha256_idx;
+alter table achievement_attachment
+    drop column sha256;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table achievement
+    add column possible_duplicate_of uuid references achievement (uuid);
+
+create index achievement_user_date_idx on achievement (user_uuid, achievement_date);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index achievement_user_date_idx;
+alter table achievement
+    drop column possible_duplicate_of;
+
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table period
+(
+    uuid                uuid primary key     default uuid_generate_v4(),
+    name                varchar     not null unique,
+    start_date          date        not null,
+    end_date            date        not null,
+    submission_deadline date        not null,
+    status              varchar     not null default 'open' check (status in ('open', 'closed', 'archived')),
+    winner_count        int         not null check (winner_count > 0),
+    created_at          timestamptz not null default now(),
+    check (start_date <= end_date),
+    check (submission_deadline >= start_date)
+);
+
+create index period_dates_idx on period (start_date, end_date);
+
+insert into resource (value)
+select v.value
+from (values ('GET - /period'),
+             ('GET - /period/:var')) as v(value)
+where not exists (select 1 from resource r where r.value = v.value);
+
+insert into group_resource (group_uuid, resource_uuid)
+select (select uuid from auth_group where name = 'Пользователи'), r.uuid
+from resource r
+where r.value in ('GET - /period',
+                  'GET - /period/:var');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+delete
+from group_resource
+where resource_uuid in (select uuid
+                        from resource
+                        where value in ('GET - /period',
+                                        'GET - /period/:var'));
+drop table period;
+
+-- +goose StatementEnd
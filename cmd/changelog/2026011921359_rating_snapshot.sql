@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table rating_snapshot
+(
+    uuid        uuid primary key     default uuid_generate_v4(),
+    period_uuid uuid references period (uuid),
+    reason      varchar     not null check (reason in ('close_out', 'manual')),
+    created_by  uuid references sys_user (uuid),
+    created_at  timestamptz not null default now()
+);
+
+create index rating_snapshot_period_idx on rating_snapshot (period_uuid);
+
+create table rating_snapshot_entry
+(
+    snapshot_uuid      uuid references rating_snapshot (uuid) on delete cascade not null,
+    user_uuid          uuid references sys_user (uuid)                         not null,
+    position           int                                                     not null,
+    point_amount       numeric                                                 not null,
+    achievement_amount int                                                     not null,
+    is_winner          boolean                                                 not null default false,
+    primary key (snapshot_uuid, user_uuid)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop table rating_snapshot_entry;
+drop table rating_snapshot;
+
+-- +goose StatementEnd
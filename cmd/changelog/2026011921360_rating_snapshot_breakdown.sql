@@ -0,0 +1,65 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table rating_snapshot
+    add column rule_version varchar not null default '';
+
+alter table rating_snapshot_entry
+    add column full_name        varchar not null default '',
+    add column gradebook_number varchar not null default '';
+
+create table rating_snapshot_achievement
+(
+    snapshot_uuid    uuid references rating_snapshot (uuid) on delete cascade not null,
+    achievement_uuid uuid references achievement (uuid)                      not null,
+    user_uuid        uuid references sys_user (uuid)                         not null,
+    category_uuid    uuid references category (uuid),
+    category_name    varchar                                                 not null,
+    point_amount     numeric                                                 not null,
+    primary key (snapshot_uuid, achievement_uuid)
+);
+
+create index rating_snapshot_achievement_user_idx on rating_snapshot_achievement (snapshot_uuid, user_uuid);
+
+create function rating_snapshot_immutable() returns trigger as
+$$
+begin
+    raise exception 'rating snapshots are immutable';
+end;
+$$ language plpgsql;
+
+create trigger rating_snapshot_immutable
+    before update or delete
+    on rating_snapshot
+    for each row
+execute function rating_snapshot_immutable();
+
+create trigger rating_snapshot_entry_immutable
+    before update or delete
+    on rating_snapshot_entry
+    for each row
+execute function rating_snapshot_immutable();
+
+create trigger rating_snapshot_achievement_immutable
+    before update or delete
+    on rating_snapshot_achievement
+    for each row
+execute function rating_snapshot_immutable();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop trigger rating_snapshot_achievement_immutable on rating_snapshot_achievement;
+drop trigger rating_snapshot_entry_immutable on rating_snapshot_entry;
+drop trigger rating_snapshot_immutable on rating_snapshot;
+drop function rating_snapshot_immutable();
+drop table rating_snapshot_achievement;
+alter table rating_snapshot_entry
+    drop column full_name,
+    drop column gradebook_number;
+alter table rating_snapshot
+    drop column rule_version;
+
+-- +goose StatementEnd
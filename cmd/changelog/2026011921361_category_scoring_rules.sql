@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table category
+    add column max_points         numeric(22, 3) check (max_points > 0),
+    add column max_achievements   int check (max_achievements > 0),
+    add column multiplier         numeric(10, 3) not null default 1 check (multiplier > 0),
+    add column diminishing_factor numeric(5, 4)  not null default 1 check (diminishing_factor > 0 and diminishing_factor <= 1);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table category
+    drop column max_points,
+    drop column max_achievements,
+    drop column multiplier,
+    drop column diminishing_factor;
+
+-- +goose StatementEnd
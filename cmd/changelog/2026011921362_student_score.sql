@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table student_score
+(
+    user_uuid           uuid primary key references sys_user (uuid) on delete cascade,
+    point_amount        numeric     not null default 0,
+    achievement_amount  int         not null default 0,
+    achievement_proofed bool        not null default true,
+    last_submitted_at   timestamptz,
+    updated_at          timestamptz not null default now()
+);
+
+create index student_score_point_amount_idx on student_score (point_amount desc);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop table student_score;
+
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+insert into resource (value)
+select 'GET - /rating/breakdown/me'
+where not exists (select 1 from resource r where r.value = 'GET - /rating/breakdown/me');
+
+insert into group_resource (group_uuid, resource_uuid)
+select (select uuid from auth_group where name = 'Пользователи'), r.uuid
+from resource r
+where r.value = 'GET - /rating/breakdown/me';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+delete
+from group_resource
+where resource_uuid in (select uuid from resource where value = 'GET - /rating/breakdown/me');
+
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create table org_unit
+(
+    uuid         uuid primary key     default uuid_generate_v4(),
+    name         varchar     not null,
+    kind         varchar     not null check (kind in ('faculty', 'department', 'course', 'group')),
+    parent_uuid  uuid references org_unit (uuid),
+    winner_quota int check (winner_quota > 0),
+    created_at   timestamptz not null default now(),
+    check (parent_uuid is distinct from uuid)
+);
+
+create unique index org_unit_parent_name_idx on org_unit (coalesce(parent_uuid, '00000000-0000-0000-0000-000000000000'), name);
+create index org_unit_parent_idx on org_unit (parent_uuid);
+
+alter table sys_user
+    add column org_unit_uuid uuid references org_unit (uuid);
+
+create index sys_user_org_unit_idx on sys_user (org_unit_uuid);
+
+insert into resource (value)
+select v.value
+from (values ('GET - /org_unit'),
+             ('GET - /org_unit/:var')) as v(value)
+where not exists (select 1 from resource r where r.value = v.value);
+
+insert into group_resource (group_uuid, resource_uuid)
+select (select uuid from auth_group where name = 'Пользователи'), r.uuid
+from resource r
+where r.value in ('GET - /org_unit',
+                  'GET - /org_unit/:var');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+delete
+from group_resource
+where resource_uuid in (select uuid
+                        from resource
+                        where value in ('GET - /org_unit',
+                                        'GET - /org_unit/:var'));
+delete
+from resource
+where value in ('GET - /org_unit',
+                'GET - /org_unit/:var');
+alter table sys_user
+    drop column org_unit_uuid;
+drop table org_unit;
+
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+
+alter table constants rename to setting;
+
+alter table setting
+    add column updated_at timestamptz not null default now(),
+    add column updated_by uuid references sys_user (uuid);
+
+create table setting_change
+(
+    uuid       uuid primary key     default uuid_generate_v4(),
+    name       varchar     not null,
+    old_value  varchar     not null,
+    new_value  varchar     not null,
+    changed_by uuid references sys_user (uuid),
+    changed_at timestamptz not null default now()
+);
+
+create index setting_change_name_idx on setting_change (name, changed_at);
+
+insert into resource (value)
+select 'GET - /settings/:var'
+where not exists (select 1 from resource r where r.value = 'GET - /settings/:var');
+
+insert into group_resource (group_uuid, resource_uuid)
+select (select uuid from auth_group where name = 'Пользователи'), r.uuid
+from resource r
+where r.value = 'GET - /settings/:var';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+delete
+from group_resource
+where resource_uuid in (select uuid from resource where value = 'GET - /settings/:var');
+delete
+from resource
+where value = 'GET - /settings/:var';
+
+drop table setting_change;
+
+alter table setting
+    drop column updated_by,
+    drop column updated_at;
+
+alter table setting rename to constants;
+
+-- +goose StatementEnd
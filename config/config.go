@@ -32,9 +32,42 @@ type Config struct {
 		AdminGroupName       string `mapstructure:"admin_group_name"`
 		AdminRoleName        string `mapstructure:"admin_role_name"`
 		JWTPrivateKeyPath    string `mapstructure:"jwt_private_key_path"`
+		BootstrapAdmin       bool   `mapstructure:"bootstrap_admin"`
 		AdminPassword        string `mapstructure:"admin_password"`
 		AdminEmail           string `mapstructure:"admin_email"`
 	} `yaml:"secure" mapstructure:"secure"`
+	Rating struct {
+		RankMode    string   `mapstructure:"rank_mode"`
+		TieBreakers []string `mapstructure:"tie_breakers"`
+	} `yaml:"rating" mapstructure:"rating"`
+	Review struct {
+		ClaimDuration int `mapstructure:"claim_duration"`
+	} `yaml:"review" mapstructure:"review"`
+	DuplicateCheck struct {
+		Disabled          bool    `mapstructure:"disabled"`
+		DateWindowDays    int     `mapstructure:"date_window_days"`
+		CommentSimilarity float64 `mapstructure:"comment_similarity"`
+	} `yaml:"duplicate_check" mapstructure:"duplicate_check"`
+	Storage struct {
+		Driver              string   `mapstructure:"driver"`
+		LocalPath           string   `mapstructure:"local_path"`
+		MaxFileSize         int64    `mapstructure:"max_file_size"`
+		MaxAttachments      int      `mapstructure:"max_attachments"`
+		AllowedContentTypes []string `mapstructure:"allowed_content_types"`
+		S3                  struct {
+			Endpoint  string `mapstructure:"endpoint"`
+			AccessKey string `mapstructure:"access_key"`
+			SecretKey string `mapstructure:"secret_key"`
+			Bucket    string `mapstructure:"bucket"`
+			Region    string `mapstructure:"region"`
+			UseSSL    bool   `mapstructure:"use_ssl"`
+		} `yaml:"s3" mapstructure:"s3"`
+	} `yaml:"storage" mapstructure:"storage"`
+	Report struct {
+		FontPath     string `mapstructure:"font_path"`
+		BoldFontPath string `mapstructure:"bold_font_path"`
+		HeaderText   string `mapstructure:"header_text"`
+	} `yaml:"report" mapstructure:"report"`
 	CORS CorsConfig `yaml:"cors" mapstructure:"cors"`
 }
 
@@ -101,6 +134,50 @@ func Get(logger zerolog.Logger) *Config {
 		cfg.Secure.RefreshTokenDuration = 172800
 	}
 
+	if cfg.Rating.RankMode == "" {
+		cfg.Rating.RankMode = "competition"
+	}
+
+	if cfg.Rating.TieBreakers == nil {
+		cfg.Rating.TieBreakers = []string{"achievements", "earliest_submission"}
+	}
+
+	if cfg.Review.ClaimDuration == 0 {
+		cfg.Review.ClaimDuration = 900
+	}
+
+	if cfg.DuplicateCheck.DateWindowDays == 0 {
+		cfg.DuplicateCheck.DateWindowDays = 14
+	}
+
+	if cfg.DuplicateCheck.CommentSimilarity == 0 {
+		cfg.DuplicateCheck.CommentSimilarity = 0.6
+	}
+
+	if cfg.Storage.Driver == "" {
+		cfg.Storage.Driver = "local"
+	}
+
+	if cfg.Storage.LocalPath == "" {
+		cfg.Storage.LocalPath = "./data/attachments"
+	}
+
+	if cfg.Storage.MaxFileSize == 0 {
+		cfg.Storage.MaxFileSize = 10 << 20
+	}
+
+	if cfg.Storage.MaxAttachments == 0 {
+		cfg.Storage.MaxAttachments = 10
+	}
+
+	if len(cfg.Storage.AllowedContentTypes) == 0 {
+		cfg.Storage.AllowedContentTypes = []string{"application/pdf", "image/jpeg", "image/png", "image/webp"}
+	}
+
+	if cfg.Report.HeaderText == "" {
+		cfg.Report.HeaderText = "Список студентов, рекомендованных стипендиальной комиссией к назначению стипендии"
+	}
+
 	if cfg.Secure.JWTPrivateKeyPath == "" {
 		cfg.Secure.JWTPrivateKeyPath = "./cert/jwtRS256.key"
 	}
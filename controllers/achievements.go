@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/igntnk/scholarship_point_system/controllers/requests"
@@ -11,22 +12,37 @@ import (
 	"github.com/igntnk/scholarship_point_system/jwk"
 	"github.com/igntnk/scholarship_point_system/middleware"
 	"github.com/igntnk/scholarship_point_system/service"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 )
 
+const (
+	defaultQueueLimit = 50
+
+	achievementFormField = "achievement"
+)
+
 type achievementController struct {
 	achievementService service.AchievementService
+	attachmentService  service.AttachmentService
 	m                  middleware.Middleware
+	maxUploadSize      int64
 }
 
 func NewAchievementController(
 	achievementService service.AchievementService,
+	attachmentService service.AttachmentService,
 	m middleware.Middleware,
+	maxUploadSize int64,
 ) Controller {
 	return &achievementController{
 		achievementService: achievementService,
+		attachmentService:  attachmentService,
 		m:                  m,
+		maxUploadSize:      maxUploadSize,
 	}
 }
 
@@ -34,9 +50,14 @@ func (c *achievementController) Register(r *gin.Engine) {
 	group := r.Group("/achievement", c.m.CheckAccess)
 	group.GET("/by_token", c.ListMyAchievements)
 	group.GET("/by_user_uuid/:uuid", c.ListUserAchievements)
+	group.GET("/queue", c.ListAchievementQueue)
 	group.GET("/:uuid", c.GetAchievementByUUID)
+	group.POST("/:uuid/claim", c.ClaimAchievement)
+	group.DELETE("/:uuid/claim", c.ReleaseAchievementClaim)
 	group.PUT("/approve/:uuid", c.ApproveAchievement)
 	group.PUT("/decline/:uuid", c.DeclineAchievement)
+	group.PUT("/resubmit/:uuid", c.ResubmitAchievement)
+	group.POST("/review/batch", c.ReviewAchievements)
 	group.POST("", c.CreateAchievement)
 	group.DELETE("/:uuid", c.DeleteAchievement)
 	group.PUT("", c.UpdateAchievement)
@@ -101,6 +122,53 @@ func (c *achievementController) ListMyAchievements(context *gin.Context) {
 	context.JSON(http.StatusOK, createResponseWithPagination(achievements, limit, offset, totalRecords))
 }
 
+func (c *achievementController) ListAchievementQueue(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	queryParams := context.Request.URL.Query()
+
+	limit := defaultQueueLimit
+	if strLimit := queryParams.Get("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	offset := 0
+	if strOffset := queryParams.Get("offset"); strOffset != "" {
+		offset, err = strconv.Atoi(strOffset)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	filter := models.AchievementQueueFilter{
+		Status:       queryParams.Get("status"),
+		CategoryUUID: queryParams.Get("category_uuid"),
+		CreatedFrom:  queryParams.Get("from"),
+		CreatedTo:    queryParams.Get("to"),
+		GroupUUID:    queryParams.Get("group_uuid"),
+	}
+
+	achievements, counts, totalRecords, err := c.achievementService.GetAchievementQueue(context, filter, limit, offset)
+	if err != nil {
+		return
+	}
+
+	resp := createResponseWithPagination(achievements, limit, offset, totalRecords)
+	resp["status_counts"] = counts
+	context.JSON(http.StatusOK, resp)
+}
+
 func (c *achievementController) GetAchievementByUUID(context *gin.Context) {
 	var err error
 
@@ -183,6 +251,9 @@ func (c *achievementController) ListUserAchievements(context *gin.Context) {
 	context.JSON(http.StatusOK, createResponseWithPagination(achievements, limit, offset, totalRecords))
 }
 
+// CreateAchievement accepts the achievement as JSON, or as multipart form with
+// the achievement JSON in the "achievement" field and the evidence files in
+// "file" parts. Either a link or at least one file is required.
 func (c *achievementController) CreateAchievement(context *gin.Context) {
 	var err error
 
@@ -192,7 +263,29 @@ func (c *achievementController) CreateAchievement(context *gin.Context) {
 		}
 	}()
 	req := requests.UpsertAchievement{}
-	if err := context.ShouldBindBodyWithJSON(&req); err != nil {
+
+	var files []*multipart.FileHeader
+	if context.ContentType() == gin.MIMEMultipartPOSTForm {
+		var form *multipart.Form
+		form, err = parseMultipartForm(context, c.maxUploadSize)
+		if err != nil {
+			return
+		}
+
+		fields := form.Value[achievementFormField]
+		if len(fields) != 1 {
+			err = errors.Join(validation.WrongInputErr, errors.New("Не передано достижение"))
+			return
+		}
+
+		if err = json.Unmarshal([]byte(fields[0]), &req); err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+
+		files = form.File[attachmentFormField]
+		req.HasAttachments = len(files) > 0
+	} else if err = context.ShouldBindBodyWithJSON(&req); err != nil {
 		err = errors.Join(err, parsing.InputDataErr)
 		return
 	}
@@ -208,6 +301,17 @@ func (c *achievementController) CreateAchievement(context *gin.Context) {
 		return
 	}
 
+	if len(files) > 0 {
+		// An achievement whose evidence failed to upload is removed again, so
+		// it does not wait for review without any.
+		if _, err = uploadAttachments(context, c.attachmentService, uuid, files); err != nil {
+			if removeErr := c.achievementService.RemoveAchievement(context, uuid); removeErr != nil {
+				err = errors.Join(err, removeErr)
+			}
+			return
+		}
+	}
+
 	context.JSON(http.StatusOK, createResponse(uuid))
 }
 
@@ -267,12 +371,17 @@ func (c *achievementController) ApproveAchievement(context *gin.Context) {
 		return
 	}
 
-	err = c.achievementService.ApproveAchievement(context, uuid)
+	req, err := bindReviewAchievement(context)
+	if err != nil {
+		return
+	}
+
+	err = c.achievementService.ApproveAchievement(context, uuid, req.Comment)
 	if err != nil {
 		return
 	}
 
-	context.JSON(http.StatusOK, createResponse("Достижение успешно принятоg"))
+	context.JSON(http.StatusOK, createResponse("Достижение успешно принято"))
 }
 
 func (c *achievementController) DeclineAchievement(context *gin.Context) {
@@ -289,10 +398,118 @@ func (c *achievementController) DeclineAchievement(context *gin.Context) {
 		return
 	}
 
-	err = c.achievementService.DeclineAchievement(context, uuid)
+	req, err := bindReviewAchievement(context)
+	if err != nil {
+		return
+	}
+
+	err = c.achievementService.DeclineAchievement(context, uuid, req.Comment)
 	if err != nil {
 		return
 	}
 
 	context.JSON(http.StatusOK, createResponse("Достижение успешно отклонено"))
 }
+
+func (c *achievementController) ResubmitAchievement(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+	uuid, ok := context.Params.Get("uuid")
+	if !ok {
+		err = errors.Join(validation.WrongInputErr, errors.New("Не предоставлен uuid"))
+		return
+	}
+
+	req, err := bindReviewAchievement(context)
+	if err != nil {
+		return
+	}
+
+	err = c.achievementService.ResubmitAchievement(context, uuid, req.Comment)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse("Достижение повторно отправлено на проверку"))
+}
+
+func (c *achievementController) ReviewAchievements(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+	req := requests.BatchReviewAchievement{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	results, err := c.achievementService.ReviewAchievements(context, req.Items)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(results))
+}
+
+func (c *achievementController) ClaimAchievement(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+	uuid, ok := context.Params.Get("uuid")
+	if !ok {
+		err = errors.Join(validation.WrongInputErr, errors.New("Не предоставлен uuid"))
+		return
+	}
+
+	claim, err := c.achievementService.ClaimAchievement(context, uuid)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(claim))
+}
+
+func (c *achievementController) ReleaseAchievementClaim(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+	uuid, ok := context.Params.Get("uuid")
+	if !ok {
+		err = errors.Join(validation.WrongInputErr, errors.New("Не предоставлен uuid"))
+		return
+	}
+
+	err = c.achievementService.ReleaseAchievementClaim(context, uuid)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse("Достижение освобождено"))
+}
+
+// bindReviewAchievement reads an optional review body, an empty body means no comment.
+func bindReviewAchievement(context *gin.Context) (requests.ReviewAchievement, error) {
+	req := requests.ReviewAchievement{}
+	if err := context.ShouldBindBodyWithJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		return req, errors.Join(err, parsing.InputDataErr)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/middleware"
+	"github.com/igntnk/scholarship_point_system/service"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+const (
+	attachmentFormField = "file"
+	// multipartOverhead is allowed on top of the files for the part headers and
+	// the other form fields.
+	multipartOverhead = 1 << 20
+)
+
+type attachmentController struct {
+	attachmentService service.AttachmentService
+	m                 middleware.Middleware
+	maxUploadSize     int64
+}
+
+// NewAttachmentController rejects upload requests with more than maxUploadSize
+// bytes of files before any of them is stored.
+func NewAttachmentController(
+	attachmentService service.AttachmentService,
+	m middleware.Middleware,
+	maxUploadSize int64,
+) Controller {
+	return &attachmentController{
+		attachmentService: attachmentService,
+		m:                 m,
+		maxUploadSize:     maxUploadSize,
+	}
+}
+
+func (c *attachmentController) Register(r *gin.Engine) {
+	group := r.Group("/achievement", c.m.CheckAccess)
+	group.POST("/:uuid/attachments", c.UploadAttachments)
+	group.GET("/:uuid/attachments", c.ListAttachments)
+	group.GET("/:uuid/attachments/:attachment_uuid", c.DownloadAttachment)
+	group.DELETE("/:uuid/attachments/:attachment_uuid", c.DeleteAttachment)
+}
+
+// UploadAttachments accepts one or more multipart parts named "file".
+func (c *attachmentController) UploadAttachments(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	achievementUUID := context.Param("uuid")
+
+	form, err := parseMultipartForm(context, c.maxUploadSize)
+	if err != nil {
+		return
+	}
+
+	files := form.File[attachmentFormField]
+	if len(files) == 0 {
+		err = errors.Join(validation.WrongInputErr, errors.New("Не передан файл"))
+		return
+	}
+
+	attachments, err := uploadAttachments(context, c.attachmentService, achievementUUID, files)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(attachments))
+}
+
+func (c *attachmentController) ListAttachments(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	attachments, err := c.attachmentService.GetAttachments(context, context.Param("uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(attachments))
+}
+
+func (c *attachmentController) DownloadAttachment(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	attachment, content, err := c.attachmentService.OpenAttachment(context, context.Param("uuid"), context.Param("attachment_uuid"))
+	if err != nil {
+		return
+	}
+	defer content.Close()
+
+	context.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    "attachment; filename*=UTF-8''" + url.PathEscape(attachment.FileName),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (c *attachmentController) DeleteAttachment(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	err = c.attachmentService.DeleteAttachment(context, context.Param("uuid"), context.Param("attachment_uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse("Вложение удалено"))
+}
+
+// parseMultipartForm reads a multipart request carrying at most maxUploadSize
+// bytes of files.
+func parseMultipartForm(context *gin.Context, maxUploadSize int64) (*multipart.Form, error) {
+	maxSize := maxUploadSize + multipartOverhead
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, maxSize)
+
+	form, err := context.MultipartForm()
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, errors.Join(validation.WrongInputErr, fmt.Errorf("Размер запроса превышает %d байт", maxSize))
+		}
+		return nil, errors.Join(err, parsing.InputDataErr)
+	}
+
+	return form, nil
+}
+
+func uploadAttachments(
+	context *gin.Context,
+	attachmentService service.AttachmentService,
+	achievementUUID string,
+	files []*multipart.FileHeader,
+) ([]responses.Attachment, error) {
+	attachments := make([]responses.Attachment, 0, len(files))
+	for _, fileHeader := range files {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, errors.Join(err, parsing.InputDataErr)
+		}
+
+		attachment, err := attachmentService.UploadAttachment(context, achievementUUID, requests.UploadAttachment{
+			FileName: fileHeader.Filename,
+			Size:     fileHeader.Size,
+			Content:  file,
+		})
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/middleware"
+	"github.com/igntnk/scholarship_point_system/service"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultAuditLimit = 50
+)
+
+type auditController struct {
+	auditService service.AuditService
+	m            middleware.Middleware
+}
+
+func NewAuditController(
+	auditService service.AuditService,
+	m middleware.Middleware,
+) Controller {
+	return &auditController{
+		auditService: auditService,
+		m:            m,
+	}
+}
+
+func (c *auditController) Register(r *gin.Engine) {
+	group := r.Group("/audit", c.m.CheckAccess)
+	group.GET("", c.ListAuditEvents)
+}
+
+func (c *auditController) ListAuditEvents(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	queryParams := context.Request.URL.Query()
+
+	limit := defaultAuditLimit
+	if strLimit := queryParams.Get("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	offset := 0
+	if strOffset := queryParams.Get("offset"); strOffset != "" {
+		offset, err = strconv.Atoi(strOffset)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	filter := models.AuditFilter{
+		ActorUUID:   queryParams.Get("actor_uuid"),
+		Action:      queryParams.Get("action"),
+		EntityType:  queryParams.Get("entity_type"),
+		EntityID:    queryParams.Get("entity_id"),
+		CreatedFrom: queryParams.Get("from"),
+		CreatedTo:   queryParams.Get("to"),
+	}
+
+	events, totalRecords, err := c.auditService.ListAuditEvents(context, filter, limit, offset)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponseWithPagination(events, limit, offset, totalRecords))
+}
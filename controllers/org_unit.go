@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/middleware"
+	"github.com/igntnk/scholarship_point_system/service"
+	"net/http"
+)
+
+type orgUnitController struct {
+	orgUnitService service.OrgUnitService
+	m              middleware.Middleware
+}
+
+func NewOrgUnitController(
+	orgUnitService service.OrgUnitService,
+	m middleware.Middleware,
+) Controller {
+	return &orgUnitController{
+		orgUnitService: orgUnitService,
+		m:              m,
+	}
+}
+
+func (c *orgUnitController) Register(r *gin.Engine) {
+	group := r.Group("/org_unit", c.m.CheckAccess)
+	group.POST("", c.CreateOrgUnit)
+	group.GET("", c.ListOrgUnits)
+	group.GET("/:uuid", c.GetOrgUnit)
+	group.PUT("/:uuid", c.UpdateOrgUnit)
+	group.DELETE("/:uuid", c.DeleteOrgUnit)
+	group.PUT("/:uuid/users", c.AssignUsers)
+	group.DELETE("/:uuid/users", c.UnassignUsers)
+}
+
+func (c *orgUnitController) CreateOrgUnit(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.UpsertOrgUnit{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	uuid, err := c.orgUnitService.CreateOrgUnit(context, req)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(responses.CreateOrgUnit{UUID: uuid}))
+}
+
+func (c *orgUnitController) ListOrgUnits(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	units, err := c.orgUnitService.ListOrgUnits(context)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(units))
+}
+
+func (c *orgUnitController) GetOrgUnit(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	unit, err := c.orgUnitService.GetOrgUnit(context, context.Param("uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(unit))
+}
+
+func (c *orgUnitController) UpdateOrgUnit(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.UpsertOrgUnit{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+	req.UUID = context.Param("uuid")
+
+	if err = c.orgUnitService.UpdateOrgUnit(context, req); err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse("Подразделение успешно обновлено"))
+}
+
+func (c *orgUnitController) DeleteOrgUnit(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	if err = c.orgUnitService.DeleteOrgUnit(context, context.Param("uuid")); err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse("Подразделение удалено"))
+}
+
+func (c *orgUnitController) AssignUsers(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.OrgUnitUsers{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	resp, err := c.orgUnitService.AssignUsers(context, context.Param("uuid"), req)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(resp))
+}
+
+func (c *orgUnitController) UnassignUsers(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.OrgUnitUsers{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	resp, err := c.orgUnitService.UnassignUsers(context, context.Param("uuid"), req)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(resp))
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/middleware"
+	"github.com/igntnk/scholarship_point_system/service"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPeriodLimit = 50
+)
+
+type periodController struct {
+	periodService service.PeriodService
+	m             middleware.Middleware
+}
+
+func NewPeriodController(
+	periodService service.PeriodService,
+	m middleware.Middleware,
+) Controller {
+	return &periodController{
+		periodService: periodService,
+		m:             m,
+	}
+}
+
+func (c *periodController) Register(r *gin.Engine) {
+	group := r.Group("/period", c.m.CheckAccess)
+	group.POST("", c.CreatePeriod)
+	group.GET("", c.ListPeriods)
+	group.GET("/:uuid", c.GetPeriod)
+	group.PUT("/:uuid", c.UpdatePeriod)
+	group.DELETE("/:uuid", c.DeletePeriod)
+	group.GET("/:uuid/close-out", c.PreviewCloseOut)
+	group.POST("/:uuid/close-out", c.CloseOut)
+}
+
+func (c *periodController) CreatePeriod(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.UpsertPeriod{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	uuid, err := c.periodService.CreatePeriod(context, req)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(responses.CreatePeriod{UUID: uuid}))
+}
+
+func (c *periodController) ListPeriods(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	queryParams := context.Request.URL.Query()
+
+	limit := defaultPeriodLimit
+	if strLimit := queryParams.Get("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	offset := 0
+	if strOffset := queryParams.Get("offset"); strOffset != "" {
+		offset, err = strconv.Atoi(strOffset)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	periods, totalRecords, err := c.periodService.ListPeriods(context, queryParams.Get("status"), limit, offset)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponseWithPagination(periods, limit, offset, totalRecords))
+}
+
+func (c *periodController) GetPeriod(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	period, err := c.periodService.GetPeriod(context, context.Param("uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(period))
+}
+
+func (c *periodController) UpdatePeriod(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.UpsertPeriod{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+	req.UUID = context.Param("uuid")
+
+	if err = c.periodService.UpdatePeriod(context, req); err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse("Период успешно обновлен"))
+}
+
+func (c *periodController) DeletePeriod(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	if err = c.periodService.DeletePeriod(context, context.Param("uuid")); err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse("Период удален"))
+}
+
+func (c *periodController) PreviewCloseOut(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	closeOut, err := c.periodService.PreviewPeriodCloseOut(context, context.Param("uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(closeOut))
+}
+
+func (c *periodController) CloseOut(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	closeOut, err := c.periodService.ClosePeriod(context, context.Param("uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(closeOut))
+}
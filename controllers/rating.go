@@ -1,16 +1,24 @@
 package controllers
 
 import (
+	"bytes"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/igntnk/scholarship_point_system/controllers/requests"
 	"github.com/igntnk/scholarship_point_system/errors/authorization"
 	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/export"
 	"github.com/igntnk/scholarship_point_system/jwk"
 	"github.com/igntnk/scholarship_point_system/middleware"
 	"github.com/igntnk/scholarship_point_system/service"
+	"github.com/igntnk/scholarship_point_system/service/models"
 	"io"
 	"net/http"
+	"strconv"
+)
+
+const (
+	defaultSnapshotLimit = 50
 )
 
 type ratingController struct {
@@ -32,7 +40,17 @@ func (c *ratingController) Register(r *gin.Engine) {
 	g := r.Group("/rating", c.m.CheckAccess)
 
 	g.POST("", c.GetRating)
+	g.GET("/export", c.ExportRating)
+	g.GET("/report", c.WinnersReport)
 	g.GET("/short_info", c.GetShortInfo)
+	g.GET("/breakdown/me", c.GetMyBreakdown)
+	g.GET("/breakdown/:uuid", c.GetBreakdown)
+	g.POST("/simulate", c.Simulate)
+	g.POST("/selection", c.SelectWinners)
+	g.POST("/snapshot", c.CreateSnapshot)
+	g.GET("/snapshots", c.ListSnapshots)
+	g.GET("/snapshots/:uuid", c.GetSnapshot)
+	g.GET("/snapshots/:uuid/diff/:other_uuid", c.DiffSnapshots)
 }
 
 func (c *ratingController) GetRating(context *gin.Context) {
@@ -46,7 +64,7 @@ func (c *ratingController) GetRating(context *gin.Context) {
 
 	req := requests.GetRating{}
 	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
-		if !errors.As(err, &io.EOF) {
+		if !errors.Is(err, io.EOF) {
 			err = errors.Join(err, parsing.InputDataErr)
 			return
 		}
@@ -60,6 +78,108 @@ func (c *ratingController) GetRating(context *gin.Context) {
 	context.JSON(http.StatusOK, createResponseWithPagination(resp, req.Limit, req.Offset, totalRecs))
 }
 
+func (c *ratingController) ExportRating(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		// Once the file has started there is no way to report the error. The
+		// connection is dropped, so the client sees a failed download rather
+		// than a complete looking truncated file.
+		if context.Writer.Written() {
+			_ = context.Error(err)
+			context.Abort()
+			dropConnection(context)
+			return
+		}
+
+		context.Header("Content-Type", "")
+		context.Header("Content-Disposition", "")
+		processHttpError(context, err)
+	}()
+
+	queryParams := context.Request.URL.Query()
+
+	format := queryParams.Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	contentType, err := export.ContentType(format)
+	if err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	req := requests.GetRating{
+		SearchString: queryParams.Get("search"),
+		PeriodUUID:   queryParams.Get("period_uuid"),
+		OrgUnitUUID:  queryParams.Get("org_unit_uuid"),
+	}
+
+	if strValid := queryParams.Get("valid"); strValid != "" {
+		req.Valid, err = strconv.ParseBool(strValid)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	if strWinners := queryParams.Get("winners"); strWinners != "" {
+		req.Winners, err = strconv.ParseBool(strWinners)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	context.Header("Content-Type", contentType)
+	context.Header("Content-Disposition", "attachment; filename=rating."+format)
+	context.Header("X-Content-Type-Options", "nosniff")
+
+	err = c.ratingService.ExportRating(context, req, format, context.Writer)
+}
+
+func (c *ratingController) WinnersReport(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	queryParams := context.Request.URL.Query()
+
+	req := requests.WinnersReport{
+		PeriodUUID: queryParams.Get("period_uuid"),
+		Header:     queryParams.Get("header"),
+	}
+
+	if strAppendix := queryParams.Get("appendix"); strAppendix != "" {
+		req.Appendix, err = strconv.ParseBool(strAppendix)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	// The document is rendered in full before it is sent, so an error can
+	// still be reported as such.
+	buf := &bytes.Buffer{}
+	if err = c.ratingService.WinnersReport(context, req, buf); err != nil {
+		return
+	}
+
+	context.DataFromReader(http.StatusOK, int64(buf.Len()), "application/pdf", buf, map[string]string{
+		"Content-Disposition":    "attachment; filename=winners.pdf",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
 func (c *ratingController) GetShortInfo(context *gin.Context) {
 	var err error
 
@@ -82,3 +202,191 @@ func (c *ratingController) GetShortInfo(context *gin.Context) {
 
 	context.JSON(http.StatusOK, createResponse(result))
 }
+
+func (c *ratingController) GetMyBreakdown(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	accessClaims, ok := context.Get(jwk.ClaimsContextKey)
+	if !ok {
+		err = authorization.UnauthorizedErr
+		return
+	}
+
+	breakdown, err := c.ratingService.GetBreakdown(context, accessClaims.(jwk.SPSAccessClaims).User.UUID)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(breakdown))
+}
+
+func (c *ratingController) GetBreakdown(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	breakdown, err := c.ratingService.GetBreakdown(context, context.Param("uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(breakdown))
+}
+
+func (c *ratingController) Simulate(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.SimulateRating{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	simulation, err := c.ratingService.SimulateRating(context, req)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(simulation))
+}
+
+func (c *ratingController) SelectWinners(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.SelectWinners{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		if !errors.Is(err, io.EOF) {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	selection, err := c.ratingService.SelectWinners(context, req)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(selection))
+}
+
+func (c *ratingController) CreateSnapshot(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.CreateRatingSnapshot{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		if !errors.Is(err, io.EOF) {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	snapshot, err := c.ratingService.CreateSnapshot(context, req)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(snapshot))
+}
+
+func (c *ratingController) ListSnapshots(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	queryParams := context.Request.URL.Query()
+
+	limit := defaultSnapshotLimit
+	if strLimit := queryParams.Get("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	offset := 0
+	if strOffset := queryParams.Get("offset"); strOffset != "" {
+		offset, err = strconv.Atoi(strOffset)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	filter := models.RatingSnapshotFilter{
+		PeriodUUID: queryParams.Get("period_uuid"),
+	}
+
+	snapshots, totalRecords, err := c.ratingService.ListSnapshots(context, filter, limit, offset)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponseWithPagination(snapshots, limit, offset, totalRecords))
+}
+
+func (c *ratingController) GetSnapshot(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	snapshot, err := c.ratingService.GetSnapshot(context, context.Param("uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(snapshot))
+}
+
+func (c *ratingController) DiffSnapshots(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	diff, err := c.ratingService.DiffSnapshots(context, context.Param("uuid"), context.Param("other_uuid"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(diff))
+}
@@ -7,9 +7,26 @@ type UpsertAchievement struct {
 	CategoryUUID    string        `json:"category_uuid"`
 	AchievementDate string        `json:"achievement_date"`
 	Subcategories   []Subcategory `json:"subcategories"`
+	// HasAttachments is set when files are uploaded together with the
+	// achievement, they count as evidence instead of AttachmentLink.
+	HasAttachments bool `json:"-"`
 }
 
 type Subcategory struct {
 	UUID          string `json:"uuid"`
 	SelectedValue string `json:"selected_value"`
 }
+
+type ReviewAchievement struct {
+	Comment string `json:"comment"`
+}
+
+type BatchReviewAchievement struct {
+	Items []BatchReviewItem `json:"items"`
+}
+
+type BatchReviewItem struct {
+	UUID     string `json:"uuid"`
+	Decision string `json:"decision"`
+	Comment  string `json:"comment"`
+}
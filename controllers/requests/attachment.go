@@ -0,0 +1,9 @@
+package requests
+
+import "io"
+
+type UploadAttachment struct {
+	FileName string
+	Size     int64
+	Content  io.Reader
+}
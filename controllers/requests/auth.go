@@ -8,3 +8,14 @@ type SingIn struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
+
+type CreateAdmin struct {
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	Name       string `json:"name"`
+	SecondName string `json:"second_name"`
+	Patronymic string `json:"patronymic"`
+	// Promote allows making an existing user an admin. The password and
+	// names of the existing user are left as they are.
+	Promote bool `json:"promote"`
+}
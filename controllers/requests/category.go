@@ -5,6 +5,7 @@ type CreateCategory struct {
 	ParentUuid string           `json:"parent_uuid"`
 	Points     float32          `json:"points"`
 	Values     []CategoryValues `json:"values"`
+	Rules      ScoringRules     `json:"rules"`
 }
 
 type CategoryValues struct {
@@ -17,4 +18,14 @@ type UpdateCategory struct {
 	Name   string           `json:"name"`
 	Points float32          `json:"points"`
 	Values []CategoryValues `json:"values"`
+	Rules  ScoringRules     `json:"rules"`
+}
+
+// ScoringRules are the rating rules of a root category, zero values keep the
+// defaults.
+type ScoringRules struct {
+	MaxPoints         float64 `json:"max_points"`
+	MaxAchievements   int     `json:"max_achievements"`
+	Multiplier        float64 `json:"multiplier"`
+	DiminishingFactor float64 `json:"diminishing_factor"`
 }
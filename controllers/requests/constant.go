@@ -1,5 +1,9 @@
 package requests
 
+import "encoding/json"
+
+// Constant is the body of the old /constant/grades_amount update, the value is
+// a number or a string with a number.
 type Constant struct {
-	Constant string `json:"constant" mapstructure:"constant"`
+	Constant json.RawMessage `json:"constant"`
 }
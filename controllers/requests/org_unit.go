@@ -0,0 +1,13 @@
+package requests
+
+type UpsertOrgUnit struct {
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	ParentUUID  string `json:"parent_uuid"`
+	WinnerQuota int    `json:"winner_quota"`
+}
+
+type OrgUnitUsers struct {
+	UserUUIDs []string `json:"user_uuids"`
+}
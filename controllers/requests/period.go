@@ -0,0 +1,11 @@
+package requests
+
+type UpsertPeriod struct {
+	UUID               string `json:"uuid"`
+	Name               string `json:"name"`
+	StartDate          string `json:"start_date"`
+	EndDate            string `json:"end_date"`
+	SubmissionDeadline string `json:"submission_deadline"`
+	Status             string `json:"status"`
+	WinnerCount        int    `json:"winner_count"`
+}
@@ -6,4 +6,39 @@ type GetRating struct {
 	Winners      bool   `json:"winners"`
 	Limit        int    `json:"limit"`
 	Offset       int    `json:"offset"`
+	PeriodUUID   string `json:"period_uuid"`
+	OrgUnitUUID  string `json:"org_unit_uuid"`
+	SortBy       string `json:"sort_by"`
+	SortOrder    string `json:"sort_order"`
+}
+
+type CreateRatingSnapshot struct {
+	PeriodUUID string `json:"period_uuid"`
+}
+
+// SimulateRating proposes category changes in the shape of the category update
+// request. A change with values changes a subcategory, one without changes a
+// root category.
+type SimulateRating struct {
+	PeriodUUID string           `json:"period_uuid"`
+	Changes    []UpdateCategory `json:"changes"`
+}
+
+type WinnersReport struct {
+	PeriodUUID string
+	Header     string
+	Appendix   bool
+}
+
+// SelectWinners tries rules of a winner selection out. The rules that are not
+// set are the stored ones. UnitQuotas add to or replace the winner quotas of
+// the org units, CategoryQuotas the stored quotas of the leading root
+// categories. Both are keyed by uuid.
+type SelectWinners struct {
+	PeriodUUID      string         `json:"period_uuid"`
+	MinPoints       *float64       `json:"min_points"`
+	RequireApproved *bool          `json:"require_approved"`
+	RequireVerified *bool          `json:"require_verified"`
+	UnitQuotas      map[string]int `json:"unit_quotas"`
+	CategoryQuotas  map[string]int `json:"category_quotas"`
 }
@@ -0,0 +1,7 @@
+package requests
+
+import "encoding/json"
+
+type UpdateSetting struct {
+	Value json.RawMessage `json:"value"`
+}
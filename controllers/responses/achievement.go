@@ -20,12 +20,75 @@ type SimpleAchievement struct {
 }
 
 type FullAchievement struct {
-	UUID            string        `json:"uuid"`
-	Comment         string        `json:"comment"`
-	AttachmentLink  string        `json:"attachment_link"`
-	Status          string        `json:"status"`
-	Category        Category      `json:"category"`
-	AchievementDate string        `json:"achievement_date"`
-	PointAmount     float32       `json:"point_amount"`
-	Subcategories   []Subcategory `json:"subcategories"`
+	UUID                string              `json:"uuid"`
+	Comment             string              `json:"comment"`
+	AttachmentLink      string              `json:"attachment_link"`
+	Status              string              `json:"status"`
+	Category            Category            `json:"category"`
+	AchievementDate     string              `json:"achievement_date"`
+	PointAmount         float32             `json:"point_amount"`
+	Subcategories       []Subcategory       `json:"subcategories"`
+	PossibleDuplicateOf string              `json:"possible_duplicate_of,omitempty"`
+	PeriodUUID          string              `json:"period_uuid,omitempty"`
+	PeriodName          string              `json:"period_name,omitempty"`
+	History             []AchievementReview `json:"history"`
+	EvidenceWarnings    []EvidenceWarning   `json:"evidence_warnings"`
+}
+
+// EvidenceWarning tells the reviewer that the same file or link is already
+// used by another achievement.
+type EvidenceWarning struct {
+	Kind            string `json:"kind"`
+	Evidence        string `json:"evidence"`
+	DuplicateOf     string `json:"duplicate_of,omitempty"`
+	AchievementUUID string `json:"achievement_uuid"`
+	UserUUID        string `json:"user_uuid"`
+	SameUser        bool   `json:"same_user"`
+}
+
+type AchievementReview struct {
+	UUID         string `json:"uuid"`
+	ReviewerUUID string `json:"reviewer_uuid,omitempty"`
+	ReviewerName string `json:"reviewer_name,omitempty"`
+	FromStatus   string `json:"from_status,omitempty"`
+	ToStatus     string `json:"to_status"`
+	Comment      string `json:"comment,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type BatchReviewResult struct {
+	UUID     string `json:"uuid"`
+	Decision string `json:"decision"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+type QueueAchievement struct {
+	UUID                string            `json:"uuid"`
+	Comment             string            `json:"comment"`
+	AttachmentLink      string            `json:"attachment_link"`
+	UserUUID            string            `json:"user_uuid"`
+	UserFullName        string            `json:"user_full_name"`
+	CategoryUUID        string            `json:"category_uuid"`
+	CategoryName        string            `json:"category_name"`
+	Status              string            `json:"status"`
+	StatusCode          string            `json:"status_code"`
+	AchievementDate     string            `json:"achievement_date,omitempty"`
+	CreatedAt           string            `json:"created_at"`
+	PossibleDuplicateOf string            `json:"possible_duplicate_of,omitempty"`
+	Claim               *AchievementClaim `json:"claim,omitempty"`
+}
+
+type AchievementStatusCount struct {
+	Status     string `json:"status"`
+	StatusCode string `json:"status_code"`
+	Total      int    `json:"total"`
+}
+
+type AchievementClaim struct {
+	AchievementUUID string `json:"achievement_uuid"`
+	ReviewerUUID    string `json:"reviewer_uuid"`
+	ReviewerName    string `json:"reviewer_name"`
+	ClaimedAt       string `json:"claimed_at"`
+	ExpiresAt       string `json:"expires_at"`
 }
@@ -0,0 +1,12 @@
+package responses
+
+type Attachment struct {
+	UUID            string `json:"uuid"`
+	AchievementUUID string `json:"achievement_uuid"`
+	FileName        string `json:"file_name"`
+	ContentType     string `json:"content_type"`
+	Size            int64  `json:"size"`
+	UploadedBy      string `json:"uploaded_by,omitempty"`
+	SHA256          string `json:"sha256,omitempty"`
+	CreatedAt       string `json:"created_at"`
+}
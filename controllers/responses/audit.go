@@ -0,0 +1,16 @@
+package responses
+
+import "encoding/json"
+
+type AuditEvent struct {
+	UUID       string          `json:"uuid"`
+	ActorUUID  string          `json:"actor_uuid,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+}
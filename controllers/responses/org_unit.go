@@ -0,0 +1,19 @@
+package responses
+
+type OrgUnit struct {
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	ParentUUID  string `json:"parent_uuid,omitempty"`
+	WinnerQuota int    `json:"winner_quota,omitempty"`
+	UserAmount  int    `json:"user_amount"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type CreateOrgUnit struct {
+	UUID string `json:"uuid"`
+}
+
+type OrgUnitUsers struct {
+	UserAmount int `json:"user_amount"`
+}
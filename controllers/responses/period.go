@@ -0,0 +1,25 @@
+package responses
+
+type Period struct {
+	UUID               string `json:"uuid"`
+	Name               string `json:"name"`
+	StartDate          string `json:"start_date"`
+	EndDate            string `json:"end_date"`
+	SubmissionDeadline string `json:"submission_deadline"`
+	Status             string `json:"status"`
+	WinnerCount        int    `json:"winner_count"`
+	CreatedAt          string `json:"created_at"`
+}
+
+type CreatePeriod struct {
+	UUID string `json:"uuid"`
+}
+
+type PeriodCloseOut struct {
+	Period                Period        `json:"period"`
+	DryRun                bool          `json:"dry_run"`
+	SnapshotUUID          string        `json:"snapshot_uuid,omitempty"`
+	Ranking               []RatingEntry `json:"ranking"`
+	WinnerAmount          int           `json:"winner_amount"`
+	UsedAchievementAmount int           `json:"used_achievement_amount"`
+}
@@ -5,3 +5,139 @@ type RatingShortInfo struct {
 	CurrentPoints   float64 `json:"current_points"`
 	LeaderPoints    float64 `json:"leader_points"`
 }
+
+type RatingEntry struct {
+	Position          int     `json:"position"`
+	UserUUID          string  `json:"user_uuid"`
+	FullName          string  `json:"full_name"`
+	GradebookNumber   string  `json:"gradebook_number"`
+	PointAmount       float64 `json:"point_amount"`
+	AchievementAmount int     `json:"achievement_amount"`
+	IsWinner          bool    `json:"is_winner"`
+	// Achievements lists what the points consist of.
+	Achievements []RatingEntryAchievement `json:"achievements,omitempty"`
+}
+
+type RatingEntryAchievement struct {
+	AchievementUUID string  `json:"achievement_uuid"`
+	CategoryUUID    string  `json:"category_uuid,omitempty"`
+	CategoryName    string  `json:"category_name"`
+	PointAmount     float64 `json:"point_amount"`
+}
+
+type RatingSnapshot struct {
+	UUID        string        `json:"uuid"`
+	PeriodUUID  string        `json:"period_uuid,omitempty"`
+	PeriodName  string        `json:"period_name,omitempty"`
+	Reason      string        `json:"reason"`
+	CreatedBy   string        `json:"created_by,omitempty"`
+	RuleVersion string        `json:"rule_version"`
+	CreatedAt   string        `json:"created_at"`
+	EntryAmount int           `json:"entry_amount"`
+	Entries     []RatingEntry `json:"entries,omitempty"`
+}
+
+type RatingSnapshotDiff struct {
+	From               RatingSnapshot            `json:"from"`
+	To                 RatingSnapshot            `json:"to"`
+	RuleVersionChanged bool                      `json:"rule_version_changed"`
+	Changes            []RatingSnapshotDiffEntry `json:"changes"`
+}
+
+// RatingSnapshotDiffEntry describes how the place of one student differs
+// between two snapshots. Change is one of added, removed or changed.
+type RatingSnapshotDiffEntry struct {
+	UserUUID        string  `json:"user_uuid"`
+	FullName        string  `json:"full_name"`
+	GradebookNumber string  `json:"gradebook_number"`
+	Change          string  `json:"change"`
+	FromPosition    int     `json:"from_position,omitempty"`
+	ToPosition      int     `json:"to_position,omitempty"`
+	PositionDelta   int     `json:"position_delta"`
+	FromPoints      float64 `json:"from_points"`
+	ToPoints        float64 `json:"to_points"`
+	PointDelta      float64 `json:"point_delta"`
+	FromWinner      bool    `json:"from_winner"`
+	ToWinner        bool    `json:"to_winner"`
+}
+
+// RatingSimulation is the ranking the proposed category changes would give.
+// Changes compares it with the current ranking, Entered and DroppedOut list
+// the students joining and leaving the winners.
+type RatingSimulation struct {
+	PeriodUUID  string                    `json:"period_uuid,omitempty"`
+	WinnerCount int                       `json:"winner_count"`
+	Ranking     []RatingEntry             `json:"ranking"`
+	Changes     []RatingSnapshotDiffEntry `json:"changes"`
+	Entered     []RatingSnapshotDiffEntry `json:"entered"`
+	DroppedOut  []RatingSnapshotDiffEntry `json:"dropped_out"`
+}
+
+// RatingBreakdown shows what the live rating total of a student consists of.
+type RatingBreakdown struct {
+	UserUUID        string                    `json:"user_uuid"`
+	FullName        string                    `json:"full_name"`
+	GradebookNumber string                    `json:"gradebook_number"`
+	PointAmount     float64                   `json:"point_amount"`
+	Categories      []RatingBreakdownCategory `json:"categories"`
+}
+
+type RatingBreakdownCategory struct {
+	CategoryUUID string                       `json:"category_uuid"`
+	CategoryName string                       `json:"category_name"`
+	PointAmount  float64                      `json:"point_amount"`
+	Achievements []RatingBreakdownAchievement `json:"achievements"`
+}
+
+// RatingBreakdownAchievement is one achievement of the student. State is one of
+// counted, pending or excluded, an excluded achievement has a reason: declined,
+// removed, category_inactive or category_limit. BasePoints are the points
+// before the category rules, PointAmount is what it brings to the total.
+type RatingBreakdownAchievement struct {
+	AchievementUUID string                 `json:"achievement_uuid"`
+	Status          string                 `json:"status"`
+	AchievementDate string                 `json:"achievement_date"`
+	State           string                 `json:"state"`
+	ExclusionReason string                 `json:"exclusion_reason,omitempty"`
+	BasePoints      float64                `json:"base_points"`
+	PointAmount     float64                `json:"point_amount"`
+	Values          []RatingBreakdownValue `json:"values"`
+}
+
+type RatingBreakdownValue struct {
+	ValueUUID       string  `json:"value_uuid"`
+	ValueName       string  `json:"value_name"`
+	SubcategoryUUID string  `json:"subcategory_uuid"`
+	SubcategoryName string  `json:"subcategory_name"`
+	PointAmount     float64 `json:"point_amount"`
+	Counted         bool    `json:"counted"`
+}
+
+// WinnerSelection lists every student of the live rating with the decision of
+// the selection rules and the reasons behind it.
+type WinnerSelection struct {
+	PeriodUUID    string                 `json:"period_uuid,omitempty"`
+	WinnerCount   int                    `json:"winner_count"`
+	SelectedCount int                    `json:"selected_count"`
+	Students      []WinnerSelectionEntry `json:"students"`
+}
+
+type WinnerSelectionEntry struct {
+	Position            int                     `json:"position"`
+	UserUUID            string                  `json:"user_uuid"`
+	FullName            string                  `json:"full_name"`
+	GradebookNumber     string                  `json:"gradebook_number"`
+	OrgUnitUUID         string                  `json:"org_unit_uuid,omitempty"`
+	LeadingCategoryUUID string                  `json:"leading_category_uuid,omitempty"`
+	PointAmount         float64                 `json:"point_amount"`
+	Selected            bool                    `json:"selected"`
+	Reasons             []WinnerSelectionReason `json:"reasons"`
+	// Explanation sums the decision up in a sentence.
+	Explanation string `json:"explanation"`
+}
+
+type WinnerSelectionReason struct {
+	Code   string `json:"code"`
+	UUID   string `json:"uuid,omitempty"`
+	Detail string `json:"detail"`
+}
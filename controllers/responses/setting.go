@@ -0,0 +1,24 @@
+package responses
+
+// Setting holds the value of a setting in its JSON type. A duration is a
+// string like 36h0m0s. UpdatedAt and UpdatedBy are empty while the setting
+// has its default.
+type Setting struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Value       any    `json:"value"`
+	Default     any    `json:"default"`
+	IsDefault   bool   `json:"is_default"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+	UpdatedBy   string `json:"updated_by,omitempty"`
+}
+
+type SettingChange struct {
+	UUID      string `json:"uuid"`
+	Key       string `json:"key"`
+	OldValue  any    `json:"old_value"`
+	NewValue  any    `json:"new_value"`
+	ChangedBy string `json:"changed_by,omitempty"`
+	ChangedAt string `json:"changed_at"`
+}
@@ -9,8 +9,10 @@ type User struct {
 	PhoneNumber            string  `json:"phone_number"`
 	GradebookNumber        string  `json:"gradebook_number"`
 	Email                  string  `json:"email"`
+	OrgUnitUUID            string  `json:"org_unit_uuid,omitempty"`
 	PointsAmount           float64 `json:"points_amount"`
 	AchievementAmount      int     `json:"achievement_amount"`
 	Valid                  bool    `json:"valid"`
 	AllAchievementVerified bool    `json:"all_achievement_verified"`
+	Rank                   int     `json:"rank,omitempty"`
 }
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/middleware"
+	"github.com/igntnk/scholarship_point_system/service"
+	"github.com/igntnk/scholarship_point_system/settings"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultSettingHistoryLimit = 50
+)
+
+type settingController struct {
+	m              middleware.Middleware
+	settingService service.SettingService
+}
+
+func NewSettingController(
+	m middleware.Middleware,
+	settingService service.SettingService,
+) Controller {
+	return &settingController{
+		m:              m,
+		settingService: settingService,
+	}
+}
+
+func (c *settingController) Register(r *gin.Engine) {
+	g := r.Group("/settings", c.m.CheckAccess)
+
+	g.GET("", c.ListSettings)
+	g.GET("/:key", c.GetSetting)
+	g.PUT("/:key", c.UpdateSetting)
+	g.GET("/:key/history", c.ListSettingChanges)
+
+	// The winner count used to be the only constant, the old routes are kept
+	// for the clients that still use them.
+	constants := r.Group("/constant", c.m.CheckAccess)
+
+	constants.GET("/grades_amount", c.GetGradesAmount)
+	constants.PUT("/grades_amount", c.UpdateGradesAmount)
+}
+
+func (c *settingController) ListSettings(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	resp, err := c.settingService.ListSettings(context)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(resp))
+}
+
+func (c *settingController) GetSetting(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	resp, err := c.settingService.GetSetting(context, context.Param("key"))
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(resp))
+}
+
+func (c *settingController) UpdateSetting(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.UpdateSetting{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	resp, err := c.settingService.UpdateSetting(context, context.Param("key"), req)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(resp))
+}
+
+func (c *settingController) ListSettingChanges(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	queryParams := context.Request.URL.Query()
+
+	limit := defaultSettingHistoryLimit
+	if strLimit := queryParams.Get("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	offset := 0
+	if strOffset := queryParams.Get("offset"); strOffset != "" {
+		offset, err = strconv.Atoi(strOffset)
+		if err != nil {
+			err = errors.Join(err, parsing.InputDataErr)
+			return
+		}
+	}
+
+	changes, totalRecords, err := c.settingService.ListSettingChanges(context, context.Param("key"), limit, offset)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponseWithPagination(changes, limit, offset, totalRecords))
+}
+
+func (c *settingController) GetGradesAmount(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	amount, err := c.settingService.Int(context, settings.WinnerCount)
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse(responses.Constant{Constant: strconv.Itoa(amount)}))
+}
+
+func (c *settingController) UpdateGradesAmount(context *gin.Context) {
+	var err error
+
+	defer func() {
+		if err != nil {
+			processHttpError(context, err)
+		}
+	}()
+
+	req := requests.Constant{}
+	if err = context.ShouldBindBodyWithJSON(&req); err != nil {
+		err = errors.Join(err, parsing.InputDataErr)
+		return
+	}
+
+	_, err = c.settingService.UpdateSetting(context, settings.WinnerCount, requests.UpdateSetting{Value: req.Constant})
+	if err != nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, createResponse("Константа успешно обновлена"))
+}
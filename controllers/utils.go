@@ -3,19 +3,31 @@ package controllers
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/igntnk/scholarship_point_system/errors/authorization"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
 	"github.com/igntnk/scholarship_point_system/errors/parsing"
 	"github.com/igntnk/scholarship_point_system/errors/unexpected"
 	"github.com/igntnk/scholarship_point_system/errors/validation"
 	"net/http"
 )
 
+// processHttpError maps the error kinds to status codes.
 func processHttpError(c *gin.Context, err error) {
 
 	switch {
 	case errors.Is(err, parsing.OutputDataErr), errors.Is(err, unexpected.RequestErr):
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	case errors.Is(err, parsing.InputDataErr), errors.Is(err, validation.RecordAlreadyExistsErr):
+	case errors.Is(err, parsing.InputDataErr), errors.Is(err, validation.RecordAlreadyExistsErr),
+		errors.Is(err, validation.WrongInputErr):
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, conflict.InvalidTransitionErr), errors.Is(err, conflict.AlreadyClaimedErr),
+		errors.Is(err, conflict.NotClaimedErr), errors.Is(err, conflict.PeriodClosedErr),
+		errors.Is(err, conflict.OrgUnitInUseErr), errors.Is(err, conflict.PeriodInUseErr):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, authorization.HasNoPermissionErr):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, unexpected.NotConfiguredErr):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
 	case errors.Is(err, validation.NoDataFoundErr):
 		c.JSON(http.StatusNoContent, gin.H{"error": err.Error()})
 	default:
@@ -23,6 +35,24 @@ func processHttpError(c *gin.Context, err error) {
 	}
 }
 
+// dropConnection closes the connection of a response that can't be finished,
+// without ending the body properly. Gin refuses to hijack a written response,
+// so the writer of net/http is hijacked. Connections that can't be hijacked,
+// like HTTP/2 streams, are left as they are.
+func dropConnection(c *gin.Context) {
+	var w http.ResponseWriter = c.Writer
+	if unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter }); ok {
+		w = unwrapper.Unwrap()
+	}
+
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return
+	}
+
+	_ = conn.Close()
+}
+
 func createResponse(data any) gin.H {
 	return gin.H{
 		"data": data,
@@ -11,26 +11,155 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimAchievement = `-- name: ClaimAchievement :one
+insert into achievement_claim (achievement_uuid, reviewer_uuid, expires_at)
+values ($1, $2, now() + make_interval(secs => $3::float8))
+on conflict (achievement_uuid) do update
+    set reviewer_uuid = excluded.reviewer_uuid,
+        claimed_at    = now(),
+        expires_at    = excluded.expires_at
+where achievement_claim.expires_at <= now()
+   or achievement_claim.reviewer_uuid = excluded.reviewer_uuid
+returning achievement_uuid, reviewer_uuid, claimed_at, expires_at
+`
+
+type ClaimAchievementParams struct {
+	AchievementUuid pgtype.UUID
+	ReviewerUuid    pgtype.UUID
+	LeaseSeconds    float64
+}
+
+func (q *Queries) ClaimAchievement(ctx context.Context, arg ClaimAchievementParams) (AchievementClaim, error) {
+	row := q.db.QueryRow(ctx, claimAchievement, arg.AchievementUuid, arg.ReviewerUuid, arg.LeaseSeconds)
+	var i AchievementClaim
+	err := row.Scan(
+		&i.AchievementUuid,
+		&i.ReviewerUuid,
+		&i.ClaimedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const countAchievementQueueByStatus = `-- name: CountAchievementQueueByStatus :many
+select s.internal_value as status_code,
+       s.display_value  as status,
+       count(a.uuid)    as total
+from status s
+         left join achievement a on a.status_uuid = s.uuid
+    and ($1::uuid is null or exists(select 1
+                                                          from achievement_category ac
+                                                          where ac.achievement_uuid = a.uuid
+                                                            and ac.category_uuid = $1))
+    and ($2::timestamptz is null or a.created_at >= $2)
+    and ($3::timestamptz is null or a.created_at < $3)
+    and ($4::uuid is null or exists(select 1
+                                                       from sys_user gu
+                                                                join org_unit g on g.uuid = gu.org_unit_uuid
+                                                       where gu.uuid = a.user_uuid
+                                                         and g.uuid = $4
+                                                         and g.kind = 'group'))
+where s.type = 'achievement_status'
+group by s.internal_value, s.display_value
+order by s.internal_value
+`
+
+type CountAchievementQueueByStatusParams struct {
+	CategoryUuid pgtype.UUID
+	CreatedFrom  pgtype.Timestamptz
+	CreatedTo    pgtype.Timestamptz
+	GroupUuid    pgtype.UUID
+}
+
+type CountAchievementQueueByStatusRow struct {
+	StatusCode pgtype.Text
+	Status     pgtype.Text
+	Total      int64
+}
+
+func (q *Queries) CountAchievementQueueByStatus(ctx context.Context, arg CountAchievementQueueByStatusParams) ([]CountAchievementQueueByStatusRow, error) {
+	rows, err := q.db.Query(ctx, countAchievementQueueByStatus,
+		arg.CategoryUuid,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.GroupUuid,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountAchievementQueueByStatusRow
+	for rows.Next() {
+		var i CountAchievementQueueByStatusRow
+		if err := rows.Scan(&i.StatusCode, &i.Status, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createAchievement = `-- name: CreateAchievement :one
-insert into achievement (comment, attachment_link, user_uuid, status_uuid)
+insert into achievement (comment, attachment_link, user_uuid, status_uuid, attachment_link_normalized,
+                         achievement_date, possible_duplicate_of)
 values ($1, $2, $3,
-        (select s.uuid from status s where s.internal_value = 'unapproved' and s.type = 'achievement_status'))
+        (select s.uuid from status s where s.internal_value = 'unapproved' and s.type = 'achievement_status'),
+        $4, $5, $6)
 returning uuid
 `
 
 type CreateAchievementParams struct {
-	Comment        pgtype.Text
-	AttachmentLink string
-	UserUuid       pgtype.UUID
+	Comment                  pgtype.Text
+	AttachmentLink           string
+	UserUuid                 pgtype.UUID
+	AttachmentLinkNormalized pgtype.Text
+	AchievementDate          pgtype.Date
+	PossibleDuplicateOf      pgtype.UUID
 }
 
 func (q *Queries) CreateAchievement(ctx context.Context, arg CreateAchievementParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, createAchievement, arg.Comment, arg.AttachmentLink, arg.UserUuid)
+	row := q.db.QueryRow(ctx, createAchievement,
+		arg.Comment,
+		arg.AttachmentLink,
+		arg.UserUuid,
+		arg.AttachmentLinkNormalized,
+		arg.AchievementDate,
+		arg.PossibleDuplicateOf,
+	)
 	var uuid pgtype.UUID
 	err := row.Scan(&uuid)
 	return uuid, err
 }
 
+const createAchievementReview = `-- name: CreateAchievementReview :exec
+insert into achievement_review (achievement_uuid, reviewer_uuid, from_status_uuid, to_status_uuid, comment)
+select a.uuid, $1, a.status_uuid, s.uuid, $2
+from achievement a
+         join status s on s.internal_value = $3 and s.type = 'achievement_status'
+where a.uuid = $4
+  and a.status_uuid != s.uuid
+`
+
+type CreateAchievementReviewParams struct {
+	ReviewerUuid    pgtype.UUID
+	Comment         pgtype.Text
+	ToStatus        pgtype.Text
+	AchievementUuid pgtype.UUID
+}
+
+func (q *Queries) CreateAchievementReview(ctx context.Context, arg CreateAchievementReviewParams) error {
+	_, err := q.db.Exec(ctx, createAchievementReview,
+		arg.ReviewerUuid,
+		arg.Comment,
+		arg.ToStatus,
+		arg.AchievementUuid,
+	)
+	return err
+}
+
 const deleteAchievementCategoryValueByAchievementUUID = `-- name: DeleteAchievementCategoryValueByAchievementUUID :exec
 delete
 from achievement_category_value
@@ -42,6 +171,236 @@ func (q *Queries) DeleteAchievementCategoryValueByAchievementUUID(ctx context.Co
 	return err
 }
 
+const deleteAchievementClaim = `-- name: DeleteAchievementClaim :exec
+delete
+from achievement_claim
+where achievement_uuid = $1
+`
+
+func (q *Queries) DeleteAchievementClaim(ctx context.Context, achievementUuid pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteAchievementClaim, achievementUuid)
+	return err
+}
+
+const getAchievementLinksWithoutFingerprint = `-- name: GetAchievementLinksWithoutFingerprint :many
+select uuid, attachment_link
+from achievement
+where attachment_link_normalized is null
+  and attachment_link != ''
+`
+
+type GetAchievementLinksWithoutFingerprintRow struct {
+	Uuid           pgtype.UUID
+	AttachmentLink string
+}
+
+func (q *Queries) GetAchievementLinksWithoutFingerprint(ctx context.Context) ([]GetAchievementLinksWithoutFingerprintRow, error) {
+	rows, err := q.db.Query(ctx, getAchievementLinksWithoutFingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAchievementLinksWithoutFingerprintRow
+	for rows.Next() {
+		var i GetAchievementLinksWithoutFingerprintRow
+		if err := rows.Scan(&i.Uuid, &i.AttachmentLink); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAchievementQueue = `-- name: GetAchievementQueue :many
+select a.uuid,
+       a.comment,
+       a.attachment_link,
+       a.user_uuid,
+       a.achievement_date,
+       a.created_at,
+       a.possible_duplicate_of,
+       u.name             as user_name,
+       u.second_name      as user_second_name,
+       u.patronymic       as user_patronymic,
+       c.uuid             as category_uuid,
+       c.name             as category_name,
+       s.display_value    as status,
+       s.internal_value   as status_code,
+       cl.reviewer_uuid   as claimed_by_uuid,
+       ru.name            as claimed_by_name,
+       ru.second_name     as claimed_by_second_name,
+       ru.patronymic      as claimed_by_patronymic,
+       cl.expires_at      as claim_expires_at,
+       count(*) over ()   as total_records
+from achievement a
+         join sys_user u on u.uuid = a.user_uuid
+         join achievement_category ac on ac.achievement_uuid = a.uuid
+         join category c on c.uuid = ac.category_uuid and c.parent_category is null
+         join status s on s.uuid = a.status_uuid
+         left join achievement_claim cl on cl.achievement_uuid = a.uuid and cl.expires_at > now()
+         left join sys_user ru on ru.uuid = cl.reviewer_uuid
+where ($1::varchar is null or s.internal_value = $1)
+  and ($2::uuid is null or c.uuid = $2)
+  and ($3::timestamptz is null or a.created_at >= $3)
+  and ($4::timestamptz is null or a.created_at < $4)
+  and ($5::uuid is null or exists(select 1
+                                                     from sys_user gu
+                                                              join org_unit g on g.uuid = gu.org_unit_uuid
+                                                     where gu.uuid = a.user_uuid
+                                                       and g.uuid = $5
+                                                       and g.kind = 'group'))
+order by a.created_at, a.uuid
+limit $7 offset $6
+`
+
+type GetAchievementQueueParams struct {
+	Status       pgtype.Text
+	CategoryUuid pgtype.UUID
+	CreatedFrom  pgtype.Timestamptz
+	CreatedTo    pgtype.Timestamptz
+	GroupUuid    pgtype.UUID
+	RowOffset    int32
+	RowLimit     int32
+}
+
+type GetAchievementQueueRow struct {
+	Uuid                pgtype.UUID
+	Comment             pgtype.Text
+	AttachmentLink      string
+	UserUuid            pgtype.UUID
+	AchievementDate     pgtype.Date
+	CreatedAt           pgtype.Timestamptz
+	PossibleDuplicateOf pgtype.UUID
+	UserName            string
+	UserSecondName      string
+	UserPatronymic      pgtype.Text
+	CategoryUuid        pgtype.UUID
+	CategoryName        string
+	Status              pgtype.Text
+	StatusCode          pgtype.Text
+	ClaimedByUuid       pgtype.UUID
+	ClaimedByName       pgtype.Text
+	ClaimedBySecondName pgtype.Text
+	ClaimedByPatronymic pgtype.Text
+	ClaimExpiresAt      pgtype.Timestamptz
+	TotalRecords        int64
+}
+
+func (q *Queries) GetAchievementQueue(ctx context.Context, arg GetAchievementQueueParams) ([]GetAchievementQueueRow, error) {
+	rows, err := q.db.Query(ctx, getAchievementQueue,
+		arg.Status,
+		arg.CategoryUuid,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.GroupUuid,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAchievementQueueRow
+	for rows.Next() {
+		var i GetAchievementQueueRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Comment,
+			&i.AttachmentLink,
+			&i.UserUuid,
+			&i.AchievementDate,
+			&i.CreatedAt,
+			&i.PossibleDuplicateOf,
+			&i.UserName,
+			&i.UserSecondName,
+			&i.UserPatronymic,
+			&i.CategoryUuid,
+			&i.CategoryName,
+			&i.Status,
+			&i.StatusCode,
+			&i.ClaimedByUuid,
+			&i.ClaimedByName,
+			&i.ClaimedBySecondName,
+			&i.ClaimedByPatronymic,
+			&i.ClaimExpiresAt,
+			&i.TotalRecords,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAchievementReviews = `-- name: GetAchievementReviews :many
+select r.uuid,
+       r.reviewer_uuid,
+       u.name             as reviewer_name,
+       u.second_name      as reviewer_second_name,
+       u.patronymic       as reviewer_patronymic,
+       f_s.display_value  as from_status,
+       t_s.display_value  as to_status,
+       t_s.internal_value as to_status_code,
+       r.comment,
+       r.created_at
+from achievement_review r
+         left join sys_user u on u.uuid = r.reviewer_uuid
+         left join status f_s on f_s.uuid = r.from_status_uuid
+         join status t_s on t_s.uuid = r.to_status_uuid
+where r.achievement_uuid = $1
+order by r.created_at
+`
+
+type GetAchievementReviewsRow struct {
+	Uuid               pgtype.UUID
+	ReviewerUuid       pgtype.UUID
+	ReviewerName       pgtype.Text
+	ReviewerSecondName pgtype.Text
+	ReviewerPatronymic pgtype.Text
+	FromStatus         pgtype.Text
+	ToStatus           pgtype.Text
+	ToStatusCode       pgtype.Text
+	Comment            pgtype.Text
+	CreatedAt          pgtype.Timestamptz
+}
+
+func (q *Queries) GetAchievementReviews(ctx context.Context, achievementUuid pgtype.UUID) ([]GetAchievementReviewsRow, error) {
+	rows, err := q.db.Query(ctx, getAchievementReviews, achievementUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAchievementReviewsRow
+	for rows.Next() {
+		var i GetAchievementReviewsRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.ReviewerUuid,
+			&i.ReviewerName,
+			&i.ReviewerSecondName,
+			&i.ReviewerPatronymic,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.ToStatusCode,
+			&i.Comment,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAchievementSubCategories = `-- name: GetAchievementSubCategories :many
 select distinct c.uuid, c.name, cv.name as selected_value, cv.point, av_cv.name as available_value
 from category c
@@ -87,40 +446,167 @@ func (q *Queries) GetAchievementSubCategories(ctx context.Context, achievementUu
 	return items, nil
 }
 
+const getActiveAchievementClaim = `-- name: GetActiveAchievementClaim :one
+select c.achievement_uuid, c.reviewer_uuid, c.claimed_at, c.expires_at,
+       u.name        as reviewer_name,
+       u.second_name as reviewer_second_name,
+       u.patronymic  as reviewer_patronymic
+from achievement_claim c
+         join sys_user u on u.uuid = c.reviewer_uuid
+where c.achievement_uuid = $1
+  and c.expires_at > now()
+`
+
+type GetActiveAchievementClaimRow struct {
+	AchievementUuid    pgtype.UUID
+	ReviewerUuid       pgtype.UUID
+	ClaimedAt          pgtype.Timestamptz
+	ExpiresAt          pgtype.Timestamptz
+	ReviewerName       string
+	ReviewerSecondName string
+	ReviewerPatronymic pgtype.Text
+}
+
+func (q *Queries) GetActiveAchievementClaim(ctx context.Context, achievementUuid pgtype.UUID) (GetActiveAchievementClaimRow, error) {
+	row := q.db.QueryRow(ctx, getActiveAchievementClaim, achievementUuid)
+	var i GetActiveAchievementClaimRow
+	err := row.Scan(
+		&i.AchievementUuid,
+		&i.ReviewerUuid,
+		&i.ClaimedAt,
+		&i.ExpiresAt,
+		&i.ReviewerName,
+		&i.ReviewerSecondName,
+		&i.ReviewerPatronymic,
+	)
+	return i, err
+}
+
+const getDuplicateCandidates = `-- name: GetDuplicateCandidates :many
+select a.uuid,
+       a.comment,
+       a.achievement_date,
+       array(select cv.category_uuid::text || ':' || cv.name
+             from achievement_category_value acv
+                      join category_value cv on cv.uuid = acv.category_value_uuid
+             where acv.achievement_uuid = a.uuid)::text[] as subcategory_values
+from achievement a
+         join achievement_category ac on ac.achievement_uuid = a.uuid and ac.category_uuid = $1
+         join status s on s.uuid = a.status_uuid
+where a.user_uuid = $2
+  and s.internal_value != 'removed'
+order by a.created_at
+`
+
+type GetDuplicateCandidatesParams struct {
+	CategoryUuid pgtype.UUID
+	UserUuid     pgtype.UUID
+}
+
+type GetDuplicateCandidatesRow struct {
+	Uuid              pgtype.UUID
+	Comment           pgtype.Text
+	AchievementDate   pgtype.Date
+	SubcategoryValues []string
+}
+
+func (q *Queries) GetDuplicateCandidates(ctx context.Context, arg GetDuplicateCandidatesParams) ([]GetDuplicateCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, getDuplicateCandidates, arg.CategoryUuid, arg.UserUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDuplicateCandidatesRow
+	for rows.Next() {
+		var i GetDuplicateCandidatesRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Comment,
+			&i.AchievementDate,
+			&i.SubcategoryValues,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDuplicateLinkEvidence = `-- name: GetDuplicateLinkEvidence :many
+select a2.uuid as achievement_uuid,
+       a2.user_uuid,
+       a2.attachment_link
+from achievement a
+         join achievement a2 on a2.attachment_link_normalized = a.attachment_link_normalized and a2.uuid != a.uuid
+         join status s on s.uuid = a2.status_uuid
+where a.uuid = $1
+  and a.attachment_link_normalized != ''
+  and s.internal_value != 'removed'
+`
+
+type GetDuplicateLinkEvidenceRow struct {
+	AchievementUuid pgtype.UUID
+	UserUuid        pgtype.UUID
+	AttachmentLink  string
+}
+
+func (q *Queries) GetDuplicateLinkEvidence(ctx context.Context, uuid pgtype.UUID) ([]GetDuplicateLinkEvidenceRow, error) {
+	rows, err := q.db.Query(ctx, getDuplicateLinkEvidence, uuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDuplicateLinkEvidenceRow
+	for rows.Next() {
+		var i GetDuplicateLinkEvidenceRow
+		if err := rows.Scan(&i.AchievementUuid, &i.UserUuid, &i.AttachmentLink); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSimpleUserAchievementByUUID = `-- name: GetSimpleUserAchievementByUUID :one
-select a.uuid, a.comment, a.attachment_link, a.user_uuid, a.status_uuid, a.achievement_date,
+select a.uuid, a.comment, a.attachment_link, a.user_uuid, a.status_uuid, a.achievement_date, a.created_at, a.attachment_link_normalized, a.possible_duplicate_of,
        c.name                         as category_name,
        s.display_value                as status,
+       s.internal_value               as status_code,
        c.uuid                         as category_uuid,
        c.point_amount                 as base_point_amount,
-       coalesce(sum(cv.point) + c.point_amount, 0)::numeric as point_amount,
        count(*) over ()               as total_records
 from achievement a
          join achievement_category ac on ac.achievement_uuid = a.uuid
          left join category c on c.uuid = ac.category_uuid and parent_category is null
-         left join category c_p on c_p.uuid = ac.category_uuid and c_p.parent_category is not null
-         left join achievement_category_value acv on acv.achievement_uuid = a.uuid
-         left join category_value cv on cv.uuid = acv.category_value_uuid and cv.category_uuid = c_p.uuid
          join status s on s.uuid = a.status_uuid
 where a.uuid = $1
   and c.uuid is not null
 group by c.name, a.uuid, a.comment, c.point_amount, c.uuid, attachment_link, a.user_uuid, a.status_uuid,
-         s.display_value
+         s.display_value, s.internal_value
 `
 
 type GetSimpleUserAchievementByUUIDRow struct {
-	Uuid            pgtype.UUID
-	Comment         pgtype.Text
-	AttachmentLink  string
-	UserUuid        pgtype.UUID
-	StatusUuid      pgtype.UUID
-	AchievementDate pgtype.Date
-	CategoryName    pgtype.Text
-	Status          pgtype.Text
-	CategoryUuid    pgtype.UUID
-	BasePointAmount pgtype.Numeric
-	PointAmount     pgtype.Numeric
-	TotalRecords    int64
+	Uuid                     pgtype.UUID
+	Comment                  pgtype.Text
+	AttachmentLink           string
+	UserUuid                 pgtype.UUID
+	StatusUuid               pgtype.UUID
+	AchievementDate          pgtype.Date
+	CreatedAt                pgtype.Timestamptz
+	AttachmentLinkNormalized pgtype.Text
+	PossibleDuplicateOf      pgtype.UUID
+	CategoryName             pgtype.Text
+	Status                   pgtype.Text
+	StatusCode               pgtype.Text
+	CategoryUuid             pgtype.UUID
+	BasePointAmount          pgtype.Numeric
+	TotalRecords             int64
 }
 
 func (q *Queries) GetSimpleUserAchievementByUUID(ctx context.Context, uuid pgtype.UUID) (GetSimpleUserAchievementByUUIDRow, error) {
@@ -133,29 +619,28 @@ func (q *Queries) GetSimpleUserAchievementByUUID(ctx context.Context, uuid pgtyp
 		&i.UserUuid,
 		&i.StatusUuid,
 		&i.AchievementDate,
+		&i.CreatedAt,
+		&i.AttachmentLinkNormalized,
+		&i.PossibleDuplicateOf,
 		&i.CategoryName,
 		&i.Status,
+		&i.StatusCode,
 		&i.CategoryUuid,
 		&i.BasePointAmount,
-		&i.PointAmount,
 		&i.TotalRecords,
 	)
 	return i, err
 }
 
 const getUserAchievements = `-- name: GetUserAchievements :many
-select a.uuid, a.comment, a.attachment_link, a.user_uuid, a.status_uuid, a.achievement_date,
+select a.uuid, a.comment, a.attachment_link, a.user_uuid, a.status_uuid, a.achievement_date, a.created_at, a.attachment_link_normalized, a.possible_duplicate_of,
        c.name                         as category_name,
        s.display_value                as status,
        c.uuid                         as category_uuid,
-       coalesce(sum(cv.point) + c.point_amount, 0)::numeric as point_amount,
        count(*) over ()               as total_records
 from achievement a
          join achievement_category ac on ac.achievement_uuid = a.uuid
          left join category c on c.uuid = ac.category_uuid and parent_category is null
-         left join category c_p on c_p.uuid = ac.category_uuid and c_p.parent_category is not null
-         left join achievement_category_value acv on acv.achievement_uuid = a.uuid
-         left join category_value cv on cv.uuid = acv.category_value_uuid and cv.category_uuid = c_p.uuid
          join status s on s.uuid = a.status_uuid
 where a.user_uuid = $1
   and s.internal_value != 'removed'
@@ -165,17 +650,19 @@ group by c.name, a.uuid, a.comment, c.point_amount, c.uuid, attachment_link, a.u
 `
 
 type GetUserAchievementsRow struct {
-	Uuid            pgtype.UUID
-	Comment         pgtype.Text
-	AttachmentLink  string
-	UserUuid        pgtype.UUID
-	StatusUuid      pgtype.UUID
-	AchievementDate pgtype.Date
-	CategoryName    pgtype.Text
-	Status          pgtype.Text
-	CategoryUuid    pgtype.UUID
-	PointAmount     pgtype.Numeric
-	TotalRecords    int64
+	Uuid                     pgtype.UUID
+	Comment                  pgtype.Text
+	AttachmentLink           string
+	UserUuid                 pgtype.UUID
+	StatusUuid               pgtype.UUID
+	AchievementDate          pgtype.Date
+	CreatedAt                pgtype.Timestamptz
+	AttachmentLinkNormalized pgtype.Text
+	PossibleDuplicateOf      pgtype.UUID
+	CategoryName             pgtype.Text
+	Status                   pgtype.Text
+	CategoryUuid             pgtype.UUID
+	TotalRecords             int64
 }
 
 func (q *Queries) GetUserAchievements(ctx context.Context, userUuid pgtype.UUID) ([]GetUserAchievementsRow, error) {
@@ -194,10 +681,12 @@ func (q *Queries) GetUserAchievements(ctx context.Context, userUuid pgtype.UUID)
 			&i.UserUuid,
 			&i.StatusUuid,
 			&i.AchievementDate,
+			&i.CreatedAt,
+			&i.AttachmentLinkNormalized,
+			&i.PossibleDuplicateOf,
 			&i.CategoryName,
 			&i.Status,
 			&i.CategoryUuid,
-			&i.PointAmount,
 			&i.TotalRecords,
 		); err != nil {
 			return nil, err
@@ -211,18 +700,14 @@ func (q *Queries) GetUserAchievements(ctx context.Context, userUuid pgtype.UUID)
 }
 
 const getUserAchievementsWithPagination = `-- name: GetUserAchievementsWithPagination :many
-select a.uuid, a.comment, a.attachment_link, a.user_uuid, a.status_uuid, a.achievement_date,
+select a.uuid, a.comment, a.attachment_link, a.user_uuid, a.status_uuid, a.achievement_date, a.created_at, a.attachment_link_normalized, a.possible_duplicate_of,
        c.name                         as category_name,
        s.display_value                as status,
        c.uuid                         as category_uuid,
-       coalesce(sum(cv.point) + c.point_amount, 0)::numeric as point_amount,
        count(*) over ()               as total_records
 from achievement a
          join achievement_category ac on ac.achievement_uuid = a.uuid
          left join category c on c.uuid = ac.category_uuid and parent_category is null
-         left join category c_p on c_p.uuid = ac.category_uuid and c_p.parent_category is not null
-         left join achievement_category_value acv on acv.achievement_uuid = a.uuid
-         left join category_value cv on cv.uuid = acv.category_value_uuid and cv.category_uuid = c_p.uuid
          join status s on s.uuid = a.status_uuid
 where a.user_uuid = $1
   and s.internal_value != 'removed'
@@ -239,17 +724,19 @@ type GetUserAchievementsWithPaginationParams struct {
 }
 
 type GetUserAchievementsWithPaginationRow struct {
-	Uuid            pgtype.UUID
-	Comment         pgtype.Text
-	AttachmentLink  string
-	UserUuid        pgtype.UUID
-	StatusUuid      pgtype.UUID
-	AchievementDate pgtype.Date
-	CategoryName    pgtype.Text
-	Status          pgtype.Text
-	CategoryUuid    pgtype.UUID
-	PointAmount     pgtype.Numeric
-	TotalRecords    int64
+	Uuid                     pgtype.UUID
+	Comment                  pgtype.Text
+	AttachmentLink           string
+	UserUuid                 pgtype.UUID
+	StatusUuid               pgtype.UUID
+	AchievementDate          pgtype.Date
+	CreatedAt                pgtype.Timestamptz
+	AttachmentLinkNormalized pgtype.Text
+	PossibleDuplicateOf      pgtype.UUID
+	CategoryName             pgtype.Text
+	Status                   pgtype.Text
+	CategoryUuid             pgtype.UUID
+	TotalRecords             int64
 }
 
 func (q *Queries) GetUserAchievementsWithPagination(ctx context.Context, arg GetUserAchievementsWithPaginationParams) ([]GetUserAchievementsWithPaginationRow, error) {
@@ -268,10 +755,12 @@ func (q *Queries) GetUserAchievementsWithPagination(ctx context.Context, arg Get
 			&i.UserUuid,
 			&i.StatusUuid,
 			&i.AchievementDate,
+			&i.CreatedAt,
+			&i.AttachmentLinkNormalized,
+			&i.PossibleDuplicateOf,
 			&i.CategoryName,
 			&i.Status,
 			&i.CategoryUuid,
-			&i.PointAmount,
 			&i.TotalRecords,
 		); err != nil {
 			return nil, err
@@ -284,59 +773,139 @@ func (q *Queries) GetUserAchievementsWithPagination(ctx context.Context, arg Get
 	return items, nil
 }
 
-const makeAchievementApproved = `-- name: MakeAchievementApproved :exec
+const makeAchievementApproved = `-- name: MakeAchievementApproved :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'approved' and type = 'achievement_status')
 where achievement.uuid = $1
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = $2::varchar
+                                   and type = 'achievement_status')
 `
 
-func (q *Queries) MakeAchievementApproved(ctx context.Context, uuid pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, makeAchievementApproved, uuid)
-	return err
+type MakeAchievementApprovedParams struct {
+	Uuid       pgtype.UUID
+	FromStatus string
+}
+
+func (q *Queries) MakeAchievementApproved(ctx context.Context, arg MakeAchievementApprovedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, makeAchievementApproved, arg.Uuid, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const makeAchievementDeclined = `-- name: MakeAchievementDeclined :exec
+const makeAchievementDeclined = `-- name: MakeAchievementDeclined :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'declined' and type = 'achievement_status')
 where achievement.uuid = $1
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = $2::varchar
+                                   and type = 'achievement_status')
 `
 
-func (q *Queries) MakeAchievementDeclined(ctx context.Context, uuid pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, makeAchievementDeclined, uuid)
-	return err
+type MakeAchievementDeclinedParams struct {
+	Uuid       pgtype.UUID
+	FromStatus string
+}
+
+func (q *Queries) MakeAchievementDeclined(ctx context.Context, arg MakeAchievementDeclinedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, makeAchievementDeclined, arg.Uuid, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const makeAchievementRemoved = `-- name: MakeAchievementRemoved :exec
+const makeAchievementRemoved = `-- name: MakeAchievementRemoved :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'removed' and type = 'achievement_status')
 where achievement.uuid = $1
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = $2::varchar
+                                   and type = 'achievement_status')
 `
 
-func (q *Queries) MakeAchievementRemoved(ctx context.Context, uuid pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, makeAchievementRemoved, uuid)
-	return err
+type MakeAchievementRemovedParams struct {
+	Uuid       pgtype.UUID
+	FromStatus string
 }
 
-const makeAchievementUnapproved = `-- name: MakeAchievementUnapproved :exec
+func (q *Queries) MakeAchievementRemoved(ctx context.Context, arg MakeAchievementRemovedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, makeAchievementRemoved, arg.Uuid, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const makeAchievementUnapproved = `-- name: MakeAchievementUnapproved :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'unapproved' and type = 'achievement_status')
 where achievement.uuid = $1
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = $2::varchar
+                                   and type = 'achievement_status')
 `
 
-func (q *Queries) MakeAchievementUnapproved(ctx context.Context, uuid pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, makeAchievementUnapproved, uuid)
-	return err
+type MakeAchievementUnapprovedParams struct {
+	Uuid       pgtype.UUID
+	FromStatus string
+}
+
+func (q *Queries) MakeAchievementUnapproved(ctx context.Context, arg MakeAchievementUnapprovedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, makeAchievementUnapproved, arg.Uuid, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const makeAchievementUsed = `-- name: MakeAchievementUsed :exec
+const makeAchievementUsed = `-- name: MakeAchievementUsed :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'used' and type = 'achievement_status')
 where achievement.uuid = $1
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = $2::varchar
+                                   and type = 'achievement_status')
 `
 
-func (q *Queries) MakeAchievementUsed(ctx context.Context, uuid pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, makeAchievementUsed, uuid)
-	return err
+type MakeAchievementUsedParams struct {
+	Uuid       pgtype.UUID
+	FromStatus string
+}
+
+func (q *Queries) MakeAchievementUsed(ctx context.Context, arg MakeAchievementUsedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, makeAchievementUsed, arg.Uuid, arg.FromStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const releaseAchievementClaim = `-- name: ReleaseAchievementClaim :execrows
+delete
+from achievement_claim
+where achievement_uuid = $1
+  and reviewer_uuid = $2
+`
+
+type ReleaseAchievementClaimParams struct {
+	AchievementUuid pgtype.UUID
+	ReviewerUuid    pgtype.UUID
+}
+
+func (q *Queries) ReleaseAchievementClaim(ctx context.Context, arg ReleaseAchievementClaimParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseAchievementClaim, arg.AchievementUuid, arg.ReviewerUuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const removeAllAchievementCategory = `-- name: RemoveAllAchievementCategory :exec
@@ -350,39 +919,102 @@ func (q *Queries) RemoveAllAchievementCategory(ctx context.Context, achievementU
 	return err
 }
 
+const setAchievementLinkFingerprint = `-- name: SetAchievementLinkFingerprint :exec
+update achievement
+set attachment_link_normalized = $2
+where uuid = $1
+`
+
+type SetAchievementLinkFingerprintParams struct {
+	Uuid                     pgtype.UUID
+	AttachmentLinkNormalized pgtype.Text
+}
+
+func (q *Queries) SetAchievementLinkFingerprint(ctx context.Context, arg SetAchievementLinkFingerprintParams) error {
+	_, err := q.db.Exec(ctx, setAchievementLinkFingerprint, arg.Uuid, arg.AttachmentLinkNormalized)
+	return err
+}
+
+const takeAchievementClaim = `-- name: TakeAchievementClaim :execrows
+delete
+from achievement_claim
+where achievement_uuid = $1
+  and reviewer_uuid = $2
+  and expires_at > now()
+`
+
+type TakeAchievementClaimParams struct {
+	AchievementUuid pgtype.UUID
+	ReviewerUuid    pgtype.UUID
+}
+
+func (q *Queries) TakeAchievementClaim(ctx context.Context, arg TakeAchievementClaimParams) (int64, error) {
+	result, err := q.db.Exec(ctx, takeAchievementClaim, arg.AchievementUuid, arg.ReviewerUuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateAchievement = `-- name: UpdateAchievement :exec
 update achievement
-set comment         = $1,
-    attachment_link = $2
+set comment                    = $1,
+    attachment_link            = $2,
+    attachment_link_normalized = $4,
+    achievement_date           = $5
 where uuid = $3
 `
 
 type UpdateAchievementParams struct {
-	Comment        pgtype.Text
-	AttachmentLink string
-	Uuid           pgtype.UUID
+	Comment                  pgtype.Text
+	AttachmentLink           string
+	Uuid                     pgtype.UUID
+	AttachmentLinkNormalized pgtype.Text
+	AchievementDate          pgtype.Date
 }
 
 func (q *Queries) UpdateAchievement(ctx context.Context, arg UpdateAchievementParams) error {
-	_, err := q.db.Exec(ctx, updateAchievement, arg.Comment, arg.AttachmentLink, arg.Uuid)
+	_, err := q.db.Exec(ctx, updateAchievement,
+		arg.Comment,
+		arg.AttachmentLink,
+		arg.Uuid,
+		arg.AttachmentLinkNormalized,
+		arg.AchievementDate,
+	)
 	return err
 }
 
-const updateAchievementWithStatus = `-- name: UpdateAchievementWithStatus :exec
+const updateAchievementWithStatus = `-- name: UpdateAchievementWithStatus :execrows
 update achievement a
-set comment         = $1,
-    attachment_link = $2,
+set comment                    = $1,
+    attachment_link            = $2,
+    attachment_link_normalized = $4,
+    achievement_date           = $5,
     status_uuid = (select s.uuid from status s where s.internal_value = 'unapproved' and s.type = 'achievement_status')
 where a.uuid = $3
+  and a.status_uuid = (select s.uuid from status s where s.internal_value = $6::varchar and s.type = 'achievement_status')
 `
 
 type UpdateAchievementWithStatusParams struct {
-	Comment        pgtype.Text
-	AttachmentLink string
-	Uuid           pgtype.UUID
+	Comment                  pgtype.Text
+	AttachmentLink           string
+	Uuid                     pgtype.UUID
+	AttachmentLinkNormalized pgtype.Text
+	AchievementDate          pgtype.Date
+	FromStatus               string
 }
 
-func (q *Queries) UpdateAchievementWithStatus(ctx context.Context, arg UpdateAchievementWithStatusParams) error {
-	_, err := q.db.Exec(ctx, updateAchievementWithStatus, arg.Comment, arg.AttachmentLink, arg.Uuid)
-	return err
+func (q *Queries) UpdateAchievementWithStatus(ctx context.Context, arg UpdateAchievementWithStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateAchievementWithStatus,
+		arg.Comment,
+		arg.AttachmentLink,
+		arg.Uuid,
+		arg.AttachmentLinkNormalized,
+		arg.AchievementDate,
+		arg.FromStatus,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
@@ -0,0 +1,227 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: attachments.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countAchievementAttachments = `-- name: CountAchievementAttachments :one
+select count(*)
+from achievement_attachment
+where achievement_uuid = $1
+`
+
+func (q *Queries) CountAchievementAttachments(ctx context.Context, achievementUuid pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countAchievementAttachments, achievementUuid)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAchievementAttachment = `-- name: CreateAchievementAttachment :exec
+insert into achievement_attachment (uuid, achievement_uuid, file_name, content_type, size, storage_key, uploaded_by,
+                                    sha256)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateAchievementAttachmentParams struct {
+	Uuid            pgtype.UUID
+	AchievementUuid pgtype.UUID
+	FileName        string
+	ContentType     string
+	Size            int64
+	StorageKey      string
+	UploadedBy      pgtype.UUID
+	Sha256          pgtype.Text
+}
+
+func (q *Queries) CreateAchievementAttachment(ctx context.Context, arg CreateAchievementAttachmentParams) error {
+	_, err := q.db.Exec(ctx, createAchievementAttachment,
+		arg.Uuid,
+		arg.AchievementUuid,
+		arg.FileName,
+		arg.ContentType,
+		arg.Size,
+		arg.StorageKey,
+		arg.UploadedBy,
+		arg.Sha256,
+	)
+	return err
+}
+
+const deleteAchievementAttachment = `-- name: DeleteAchievementAttachment :exec
+delete
+from achievement_attachment
+where uuid = $1
+`
+
+func (q *Queries) DeleteAchievementAttachment(ctx context.Context, uuid pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteAchievementAttachment, uuid)
+	return err
+}
+
+const getAchievementAttachment = `-- name: GetAchievementAttachment :one
+select uuid, achievement_uuid, file_name, content_type, size, storage_key, uploaded_by, created_at, sha256
+from achievement_attachment
+where uuid = $1
+`
+
+func (q *Queries) GetAchievementAttachment(ctx context.Context, uuid pgtype.UUID) (AchievementAttachment, error) {
+	row := q.db.QueryRow(ctx, getAchievementAttachment, uuid)
+	var i AchievementAttachment
+	err := row.Scan(
+		&i.Uuid,
+		&i.AchievementUuid,
+		&i.FileName,
+		&i.ContentType,
+		&i.Size,
+		&i.StorageKey,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.Sha256,
+	)
+	return i, err
+}
+
+const getAchievementAttachments = `-- name: GetAchievementAttachments :many
+select uuid, achievement_uuid, file_name, content_type, size, storage_key, uploaded_by, created_at, sha256
+from achievement_attachment
+where achievement_uuid = $1
+order by created_at
+`
+
+func (q *Queries) GetAchievementAttachments(ctx context.Context, achievementUuid pgtype.UUID) ([]AchievementAttachment, error) {
+	rows, err := q.db.Query(ctx, getAchievementAttachments, achievementUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AchievementAttachment
+	for rows.Next() {
+		var i AchievementAttachment
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.AchievementUuid,
+			&i.FileName,
+			&i.ContentType,
+			&i.Size,
+			&i.StorageKey,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.Sha256,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttachmentsWithoutHash = `-- name: GetAttachmentsWithoutHash :many
+select uuid, achievement_uuid, file_name, content_type, size, storage_key, uploaded_by, created_at, sha256
+from achievement_attachment
+where sha256 is null
+`
+
+func (q *Queries) GetAttachmentsWithoutHash(ctx context.Context) ([]AchievementAttachment, error) {
+	rows, err := q.db.Query(ctx, getAttachmentsWithoutHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AchievementAttachment
+	for rows.Next() {
+		var i AchievementAttachment
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.AchievementUuid,
+			&i.FileName,
+			&i.ContentType,
+			&i.Size,
+			&i.StorageKey,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.Sha256,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDuplicateAttachmentEvidence = `-- name: GetDuplicateAttachmentEvidence :many
+select aa.uuid       as attachment_uuid,
+       aa.file_name,
+       a2.uuid       as duplicate_achievement_uuid,
+       a2.user_uuid  as duplicate_user_uuid,
+       aa2.file_name as duplicate_file_name
+from achievement_attachment aa
+         join achievement_attachment aa2 on aa2.sha256 = aa.sha256 and aa2.achievement_uuid != aa.achievement_uuid
+         join achievement a2 on a2.uuid = aa2.achievement_uuid
+         join status s on s.uuid = a2.status_uuid
+where aa.achievement_uuid = $1
+  and s.internal_value != 'removed'
+`
+
+type GetDuplicateAttachmentEvidenceRow struct {
+	AttachmentUuid           pgtype.UUID
+	FileName                 string
+	DuplicateAchievementUuid pgtype.UUID
+	DuplicateUserUuid        pgtype.UUID
+	DuplicateFileName        string
+}
+
+func (q *Queries) GetDuplicateAttachmentEvidence(ctx context.Context, achievementUuid pgtype.UUID) ([]GetDuplicateAttachmentEvidenceRow, error) {
+	rows, err := q.db.Query(ctx, getDuplicateAttachmentEvidence, achievementUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDuplicateAttachmentEvidenceRow
+	for rows.Next() {
+		var i GetDuplicateAttachmentEvidenceRow
+		if err := rows.Scan(
+			&i.AttachmentUuid,
+			&i.FileName,
+			&i.DuplicateAchievementUuid,
+			&i.DuplicateUserUuid,
+			&i.DuplicateFileName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAttachmentHash = `-- name: SetAttachmentHash :exec
+update achievement_attachment
+set sha256 = $2
+where uuid = $1
+`
+
+type SetAttachmentHashParams struct {
+	Uuid   pgtype.UUID
+	Sha256 pgtype.Text
+}
+
+func (q *Queries) SetAttachmentHash(ctx context.Context, arg SetAttachmentHashParams) error {
+	_, err := q.db.Exec(ctx, setAttachmentHash, arg.Uuid, arg.Sha256)
+	return err
+}
@@ -0,0 +1,122 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: audit.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createAuditEvent = `-- name: CreateAuditEvent :exec
+insert into audit_event (actor_uuid, action, entity_type, entity_id, before_data, after_data, ip, request_id)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateAuditEventParams struct {
+	ActorUuid  pgtype.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	BeforeData []byte
+	AfterData  []byte
+	Ip         pgtype.Text
+	RequestID  pgtype.Text
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error {
+	_, err := q.db.Exec(ctx, createAuditEvent,
+		arg.ActorUuid,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.BeforeData,
+		arg.AfterData,
+		arg.Ip,
+		arg.RequestID,
+	)
+	return err
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+select e.uuid, e.actor_uuid, e.action, e.entity_type, e.entity_id, e.before_data, e.after_data, e.ip, e.request_id, e.created_at,
+       count(*) over () as total_records
+from audit_event e
+where ($1::uuid is null or e.actor_uuid = $1)
+  and ($2::varchar is null or e.action = $2)
+  and ($3::varchar is null or e.entity_type = $3)
+  and ($4::varchar is null or e.entity_id = $4)
+  and ($5::timestamptz is null or e.created_at >= $5)
+  and ($6::timestamptz is null or e.created_at < $6)
+order by e.created_at desc
+limit $8 offset $7
+`
+
+type ListAuditEventsParams struct {
+	ActorUuid   pgtype.UUID
+	Action      pgtype.Text
+	EntityType  pgtype.Text
+	EntityID    pgtype.Text
+	CreatedFrom pgtype.Timestamptz
+	CreatedTo   pgtype.Timestamptz
+	RowOffset   int32
+	RowLimit    int32
+}
+
+type ListAuditEventsRow struct {
+	Uuid         pgtype.UUID
+	ActorUuid    pgtype.UUID
+	Action       string
+	EntityType   string
+	EntityID     string
+	BeforeData   []byte
+	AfterData    []byte
+	Ip           pgtype.Text
+	RequestID    pgtype.Text
+	CreatedAt    pgtype.Timestamptz
+	TotalRecords int64
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]ListAuditEventsRow, error) {
+	rows, err := q.db.Query(ctx, listAuditEvents,
+		arg.ActorUuid,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAuditEventsRow
+	for rows.Next() {
+		var i ListAuditEventsRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.ActorUuid,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.BeforeData,
+			&i.AfterData,
+			&i.Ip,
+			&i.RequestID,
+			&i.CreatedAt,
+			&i.TotalRecords,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -513,3 +513,64 @@ func (b *RemoveBatchAchievementCategoryBatchResults) Close() error {
 	b.closed = true
 	return b.br.Close()
 }
+
+const upsertStudentScores = `-- name: UpsertStudentScores :batchexec
+insert into student_score (user_uuid, point_amount, achievement_amount, achievement_proofed, last_submitted_at, updated_at)
+values ($1, $2, $3, $4, $5, now())
+on conflict (user_uuid) do update set point_amount        = excluded.point_amount,
+                                      achievement_amount  = excluded.achievement_amount,
+                                      achievement_proofed = excluded.achievement_proofed,
+                                      last_submitted_at   = excluded.last_submitted_at,
+                                      updated_at          = excluded.updated_at
+`
+
+type UpsertStudentScoresBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type UpsertStudentScoresParams struct {
+	UserUuid           pgtype.UUID
+	PointAmount        pgtype.Numeric
+	AchievementAmount  int32
+	AchievementProofed bool
+	LastSubmittedAt    pgtype.Timestamptz
+}
+
+func (q *Queries) UpsertStudentScores(ctx context.Context, arg []UpsertStudentScoresParams) *UpsertStudentScoresBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.UserUuid,
+			a.PointAmount,
+			a.AchievementAmount,
+			a.AchievementProofed,
+			a.LastSubmittedAt,
+		}
+		batch.Queue(upsertStudentScores, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &UpsertStudentScoresBatchResults{br, len(arg), false}
+}
+
+func (b *UpsertStudentScoresBatchResults) Exec(f func(int, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		if b.closed {
+			if f != nil {
+				f(t, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		_, err := b.br.Exec()
+		if f != nil {
+			f(t, err)
+		}
+	}
+}
+
+func (b *UpsertStudentScoresBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
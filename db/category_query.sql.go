@@ -12,19 +12,35 @@ import (
 )
 
 const createCategory = `-- name: CreateCategory :one
-insert into category (name, point_amount, parent_category, status_uuid)
-values ($1, $2, $3, (select s.uuid from status s where type = 'category_status' and internal_value = 'active'))
+insert into category (name, point_amount, parent_category, status_uuid, max_points, max_achievements, multiplier,
+                      diminishing_factor)
+values ($1, $2, $3,
+        (select s.uuid from status s where type = 'category_status' and internal_value = 'active'),
+        $4, $5, coalesce($6::numeric, 1),
+        coalesce($7::numeric, 1))
 returning uuid
 `
 
 type CreateCategoryParams struct {
-	Name           string
-	PointAmount    pgtype.Numeric
-	ParentCategory pgtype.UUID
+	Name              string
+	PointAmount       pgtype.Numeric
+	ParentCategory    pgtype.UUID
+	MaxPoints         pgtype.Numeric
+	MaxAchievements   pgtype.Int4
+	Multiplier        pgtype.Numeric
+	DiminishingFactor pgtype.Numeric
 }
 
 func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, createCategory, arg.Name, arg.PointAmount, arg.ParentCategory)
+	row := q.db.QueryRow(ctx, createCategory,
+		arg.Name,
+		arg.PointAmount,
+		arg.ParentCategory,
+		arg.MaxPoints,
+		arg.MaxAchievements,
+		arg.Multiplier,
+		arg.DiminishingFactor,
+	)
 	var uuid pgtype.UUID
 	err := row.Scan(&uuid)
 	return uuid, err
@@ -51,7 +67,7 @@ func (q *Queries) DeleteCategoryValues(ctx context.Context, categoryUuid pgtype.
 }
 
 const getCategoryByAchievement = `-- name: GetCategoryByAchievement :many
-select c.uuid, c.name, c.point_amount, c.parent_category, c.comment, c.status_uuid, s.display_value as status_value
+select c.uuid, c.name, c.point_amount, c.parent_category, c.comment, c.status_uuid, c.max_points, c.max_achievements, c.multiplier, c.diminishing_factor, s.display_value as status_value
 from category c
          join achievement_category ac on c.uuid = ac.category_uuid
          join status s on s.uuid = c.status_uuid
@@ -60,13 +76,17 @@ where achievement_uuid = $1
 `
 
 type GetCategoryByAchievementRow struct {
-	Uuid           pgtype.UUID
-	Name           string
-	PointAmount    pgtype.Numeric
-	ParentCategory pgtype.UUID
-	Comment        pgtype.Text
-	StatusUuid     pgtype.UUID
-	StatusValue    pgtype.Text
+	Uuid              pgtype.UUID
+	Name              string
+	PointAmount       pgtype.Numeric
+	ParentCategory    pgtype.UUID
+	Comment           pgtype.Text
+	StatusUuid        pgtype.UUID
+	MaxPoints         pgtype.Numeric
+	MaxAchievements   pgtype.Int4
+	Multiplier        pgtype.Numeric
+	DiminishingFactor pgtype.Numeric
+	StatusValue       pgtype.Text
 }
 
 func (q *Queries) GetCategoryByAchievement(ctx context.Context, achievementUuid pgtype.UUID) ([]GetCategoryByAchievementRow, error) {
@@ -85,6 +105,10 @@ func (q *Queries) GetCategoryByAchievement(ctx context.Context, achievementUuid
 			&i.ParentCategory,
 			&i.Comment,
 			&i.StatusUuid,
+			&i.MaxPoints,
+			&i.MaxAchievements,
+			&i.Multiplier,
+			&i.DiminishingFactor,
 			&i.StatusValue,
 		); err != nil {
 			return nil, err
@@ -98,7 +122,7 @@ func (q *Queries) GetCategoryByAchievement(ctx context.Context, achievementUuid
 }
 
 const getCategoryByNameAndParentNull = `-- name: GetCategoryByNameAndParentNull :one
-select uuid, name, point_amount, parent_category, comment, status_uuid
+select uuid, name, point_amount, parent_category, comment, status_uuid, max_points, max_achievements, multiplier, diminishing_factor
 from category
 where name = $1
   and parent_category is null
@@ -114,12 +138,16 @@ func (q *Queries) GetCategoryByNameAndParentNull(ctx context.Context, name strin
 		&i.ParentCategory,
 		&i.Comment,
 		&i.StatusUuid,
+		&i.MaxPoints,
+		&i.MaxAchievements,
+		&i.Multiplier,
+		&i.DiminishingFactor,
 	)
 	return i, err
 }
 
 const getCategoryByNameAndParentUUID = `-- name: GetCategoryByNameAndParentUUID :one
-select uuid, name, point_amount, parent_category, comment, status_uuid
+select uuid, name, point_amount, parent_category, comment, status_uuid, max_points, max_achievements, multiplier, diminishing_factor
 from category
 where name = $1
   and parent_category = $2
@@ -140,24 +168,33 @@ func (q *Queries) GetCategoryByNameAndParentUUID(ctx context.Context, arg GetCat
 		&i.ParentCategory,
 		&i.Comment,
 		&i.StatusUuid,
+		&i.MaxPoints,
+		&i.MaxAchievements,
+		&i.Multiplier,
+		&i.DiminishingFactor,
 	)
 	return i, err
 }
 
 const getCategoryByUUID = `-- name: GetCategoryByUUID :one
-select c.uuid, c.name, c.point_amount, c.parent_category, c.comment, s.display_value
+select c.uuid, c.name, c.point_amount, c.parent_category, c.comment, s.display_value,
+       c.max_points, c.max_achievements, c.multiplier, c.diminishing_factor
 from category c
          join status s on c.status_uuid = s.uuid and type = 'category_status'
 where c.uuid = $1
 `
 
 type GetCategoryByUUIDRow struct {
-	Uuid           pgtype.UUID
-	Name           string
-	PointAmount    pgtype.Numeric
-	ParentCategory pgtype.UUID
-	Comment        pgtype.Text
-	DisplayValue   pgtype.Text
+	Uuid              pgtype.UUID
+	Name              string
+	PointAmount       pgtype.Numeric
+	ParentCategory    pgtype.UUID
+	Comment           pgtype.Text
+	DisplayValue      pgtype.Text
+	MaxPoints         pgtype.Numeric
+	MaxAchievements   pgtype.Int4
+	Multiplier        pgtype.Numeric
+	DiminishingFactor pgtype.Numeric
 }
 
 func (q *Queries) GetCategoryByUUID(ctx context.Context, uuid pgtype.UUID) (GetCategoryByUUIDRow, error) {
@@ -170,6 +207,10 @@ func (q *Queries) GetCategoryByUUID(ctx context.Context, uuid pgtype.UUID) (GetC
 		&i.ParentCategory,
 		&i.Comment,
 		&i.DisplayValue,
+		&i.MaxPoints,
+		&i.MaxAchievements,
+		&i.Multiplier,
+		&i.DiminishingFactor,
 	)
 	return i, err
 }
@@ -254,7 +295,7 @@ func (q *Queries) GetChildCategories(ctx context.Context, parentCategory pgtype.
 }
 
 const getParentCategoryByAchievement = `-- name: GetParentCategoryByAchievement :one
-select c.uuid, c.name, c.point_amount, c.parent_category, c.comment, c.status_uuid, s.display_value as status_value
+select c.uuid, c.name, c.point_amount, c.parent_category, c.comment, c.status_uuid, c.max_points, c.max_achievements, c.multiplier, c.diminishing_factor, s.display_value as status_value
 from category c
          join achievement_category ac on c.uuid = ac.category_uuid
          join status s on s.uuid = c.status_uuid
@@ -264,13 +305,17 @@ where achievement_uuid = $1
 `
 
 type GetParentCategoryByAchievementRow struct {
-	Uuid           pgtype.UUID
-	Name           string
-	PointAmount    pgtype.Numeric
-	ParentCategory pgtype.UUID
-	Comment        pgtype.Text
-	StatusUuid     pgtype.UUID
-	StatusValue    pgtype.Text
+	Uuid              pgtype.UUID
+	Name              string
+	PointAmount       pgtype.Numeric
+	ParentCategory    pgtype.UUID
+	Comment           pgtype.Text
+	StatusUuid        pgtype.UUID
+	MaxPoints         pgtype.Numeric
+	MaxAchievements   pgtype.Int4
+	Multiplier        pgtype.Numeric
+	DiminishingFactor pgtype.Numeric
+	StatusValue       pgtype.Text
 }
 
 func (q *Queries) GetParentCategoryByAchievement(ctx context.Context, achievementUuid pgtype.UUID) (GetParentCategoryByAchievementRow, error) {
@@ -283,13 +328,18 @@ func (q *Queries) GetParentCategoryByAchievement(ctx context.Context, achievemen
 		&i.ParentCategory,
 		&i.Comment,
 		&i.StatusUuid,
+		&i.MaxPoints,
+		&i.MaxAchievements,
+		&i.Multiplier,
+		&i.DiminishingFactor,
 		&i.StatusValue,
 	)
 	return i, err
 }
 
 const listParentCategories = `-- name: ListParentCategories :many
-select c.uuid, c.name, c.point_amount, c.comment, s.display_value, count(c_c.uuid) as sub_amount
+select c.uuid, c.name, c.point_amount, c.comment, s.display_value, count(c_c.uuid) as sub_amount,
+       c.max_points, c.max_achievements, c.multiplier, c.diminishing_factor
 from category c
          join status s on c.status_uuid = s.uuid and type = 'category_status'
          left join category c_c on c_c.parent_category = c.uuid and c_c.status_uuid != (select i_s.uuid from status i_s where i_s.type = 'category_status' and i_s.internal_value = 'unactive')
@@ -299,12 +349,16 @@ group by c.name, c.uuid, c.point_amount, c.comment, s.display_value
 `
 
 type ListParentCategoriesRow struct {
-	Uuid         pgtype.UUID
-	Name         string
-	PointAmount  pgtype.Numeric
-	Comment      pgtype.Text
-	DisplayValue pgtype.Text
-	SubAmount    int64
+	Uuid              pgtype.UUID
+	Name              string
+	PointAmount       pgtype.Numeric
+	Comment           pgtype.Text
+	DisplayValue      pgtype.Text
+	SubAmount         int64
+	MaxPoints         pgtype.Numeric
+	MaxAchievements   pgtype.Int4
+	Multiplier        pgtype.Numeric
+	DiminishingFactor pgtype.Numeric
 }
 
 func (q *Queries) ListParentCategories(ctx context.Context) ([]ListParentCategoriesRow, error) {
@@ -323,6 +377,10 @@ func (q *Queries) ListParentCategories(ctx context.Context) ([]ListParentCategor
 			&i.Comment,
 			&i.DisplayValue,
 			&i.SubAmount,
+			&i.MaxPoints,
+			&i.MaxAchievements,
+			&i.Multiplier,
+			&i.DiminishingFactor,
 		); err != nil {
 			return nil, err
 		}
@@ -335,7 +393,8 @@ func (q *Queries) ListParentCategories(ctx context.Context) ([]ListParentCategor
 }
 
 const listParentCategoriesWithPagination = `-- name: ListParentCategoriesWithPagination :many
-select c.uuid, c.name, c.point_amount, s.display_value, count(c.uuid) over () as total_amount, count(c_c.uuid) as sub_amount
+select c.uuid, c.name, c.point_amount, s.display_value, count(c.uuid) over () as total_amount, count(c_c.uuid) as sub_amount,
+       c.max_points, c.max_achievements, c.multiplier, c.diminishing_factor
 from category c
          join status s on c.status_uuid = s.uuid and type = 'category_status'
          left join category c_c on c_c.parent_category = c.uuid and c_c.status_uuid != (select i_s.uuid from status i_s where i_s.type = 'category_status' and i_s.internal_value = 'unactive')
@@ -351,12 +410,16 @@ type ListParentCategoriesWithPaginationParams struct {
 }
 
 type ListParentCategoriesWithPaginationRow struct {
-	Uuid         pgtype.UUID
-	Name         string
-	PointAmount  pgtype.Numeric
-	DisplayValue pgtype.Text
-	TotalAmount  int64
-	SubAmount    int64
+	Uuid              pgtype.UUID
+	Name              string
+	PointAmount       pgtype.Numeric
+	DisplayValue      pgtype.Text
+	TotalAmount       int64
+	SubAmount         int64
+	MaxPoints         pgtype.Numeric
+	MaxAchievements   pgtype.Int4
+	Multiplier        pgtype.Numeric
+	DiminishingFactor pgtype.Numeric
 }
 
 func (q *Queries) ListParentCategoriesWithPagination(ctx context.Context, arg ListParentCategoriesWithPaginationParams) ([]ListParentCategoriesWithPaginationRow, error) {
@@ -375,6 +438,10 @@ func (q *Queries) ListParentCategoriesWithPagination(ctx context.Context, arg Li
 			&i.DisplayValue,
 			&i.TotalAmount,
 			&i.SubAmount,
+			&i.MaxPoints,
+			&i.MaxAchievements,
+			&i.Multiplier,
+			&i.DiminishingFactor,
 		); err != nil {
 			return nil, err
 		}
@@ -388,17 +455,25 @@ func (q *Queries) ListParentCategoriesWithPagination(ctx context.Context, arg Li
 
 const updateCategory = `-- name: UpdateCategory :exec
 update category
-set name         = $1,
-    point_amount = $2,
-    status_uuid  = (select s.uuid from status s where s.display_value = $3 and s.type = 'category_status')
-where category.uuid = $4
+set name               = $1,
+    point_amount       = $2,
+    status_uuid        = (select s.uuid from status s where s.display_value = $3 and s.type = 'category_status'),
+    max_points         = $4,
+    max_achievements   = $5,
+    multiplier         = coalesce($6::numeric, 1),
+    diminishing_factor = coalesce($7::numeric, 1)
+where category.uuid = $8
 `
 
 type UpdateCategoryParams struct {
-	Name         string
-	PointAmount  pgtype.Numeric
-	DisplayValue pgtype.Text
-	Uuid         pgtype.UUID
+	Name              string
+	PointAmount       pgtype.Numeric
+	DisplayValue      pgtype.Text
+	MaxPoints         pgtype.Numeric
+	MaxAchievements   pgtype.Int4
+	Multiplier        pgtype.Numeric
+	DiminishingFactor pgtype.Numeric
+	Uuid              pgtype.UUID
 }
 
 func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error {
@@ -406,6 +481,10 @@ func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams)
 		arg.Name,
 		arg.PointAmount,
 		arg.DisplayValue,
+		arg.MaxPoints,
+		arg.MaxAchievements,
+		arg.Multiplier,
+		arg.DiminishingFactor,
 		arg.Uuid,
 	)
 	return err
@@ -9,12 +9,27 @@ import (
 )
 
 type Achievement struct {
+	Uuid                     pgtype.UUID
+	Comment                  pgtype.Text
+	AttachmentLink           string
+	UserUuid                 pgtype.UUID
+	StatusUuid               pgtype.UUID
+	AchievementDate          pgtype.Date
+	CreatedAt                pgtype.Timestamptz
+	AttachmentLinkNormalized pgtype.Text
+	PossibleDuplicateOf      pgtype.UUID
+}
+
+type AchievementAttachment struct {
 	Uuid            pgtype.UUID
-	Comment         pgtype.Text
-	AttachmentLink  string
-	UserUuid        pgtype.UUID
-	StatusUuid      pgtype.UUID
-	AchievementDate pgtype.Date
+	AchievementUuid pgtype.UUID
+	FileName        string
+	ContentType     string
+	Size            int64
+	StorageKey      string
+	UploadedBy      pgtype.UUID
+	CreatedAt       pgtype.Timestamptz
+	Sha256          pgtype.Text
 }
 
 type AchievementCategory struct {
@@ -27,6 +42,36 @@ type AchievementCategoryValue struct {
 	CategoryValueUuid pgtype.UUID
 }
 
+type AchievementClaim struct {
+	AchievementUuid pgtype.UUID
+	ReviewerUuid    pgtype.UUID
+	ClaimedAt       pgtype.Timestamptz
+	ExpiresAt       pgtype.Timestamptz
+}
+
+type AchievementReview struct {
+	Uuid            pgtype.UUID
+	AchievementUuid pgtype.UUID
+	ReviewerUuid    pgtype.UUID
+	FromStatusUuid  pgtype.UUID
+	ToStatusUuid    pgtype.UUID
+	Comment         pgtype.Text
+	CreatedAt       pgtype.Timestamptz
+}
+
+type AuditEvent struct {
+	Uuid       pgtype.UUID
+	ActorUuid  pgtype.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	BeforeData []byte
+	AfterData  []byte
+	Ip         pgtype.Text
+	RequestID  pgtype.Text
+	CreatedAt  pgtype.Timestamptz
+}
+
 type AuthGroup struct {
 	Uuid pgtype.UUID
 	Name string
@@ -38,12 +83,16 @@ type AuthRole struct {
 }
 
 type Category struct {
-	Uuid           pgtype.UUID
-	Name           string
-	PointAmount    pgtype.Numeric
-	ParentCategory pgtype.UUID
-	Comment        pgtype.Text
-	StatusUuid     pgtype.UUID
+	Uuid              pgtype.UUID
+	Name              string
+	PointAmount       pgtype.Numeric
+	ParentCategory    pgtype.UUID
+	Comment           pgtype.Text
+	StatusUuid        pgtype.UUID
+	MaxPoints         pgtype.Numeric
+	MaxAchievements   pgtype.Int4
+	Multiplier        pgtype.Numeric
+	DiminishingFactor pgtype.Numeric
 }
 
 type CategoryValue struct {
@@ -54,16 +103,60 @@ type CategoryValue struct {
 	StatusUuid   pgtype.UUID
 }
 
-type Constant struct {
-	Name  string
-	Value string
-}
-
 type GroupResource struct {
 	GroupUuid    pgtype.UUID
 	ResourceUuid pgtype.UUID
 }
 
+type OrgUnit struct {
+	Uuid        pgtype.UUID
+	Name        string
+	Kind        string
+	ParentUuid  pgtype.UUID
+	WinnerQuota pgtype.Int4
+	CreatedAt   pgtype.Timestamptz
+}
+
+type Period struct {
+	Uuid               pgtype.UUID
+	Name               string
+	StartDate          pgtype.Date
+	EndDate            pgtype.Date
+	SubmissionDeadline pgtype.Date
+	Status             string
+	WinnerCount        int32
+	CreatedAt          pgtype.Timestamptz
+}
+
+type RatingSnapshot struct {
+	Uuid        pgtype.UUID
+	PeriodUuid  pgtype.UUID
+	Reason      string
+	CreatedBy   pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+	RuleVersion string
+}
+
+type RatingSnapshotAchievement struct {
+	SnapshotUuid    pgtype.UUID
+	AchievementUuid pgtype.UUID
+	UserUuid        pgtype.UUID
+	CategoryUuid    pgtype.UUID
+	CategoryName    string
+	PointAmount     pgtype.Numeric
+}
+
+type RatingSnapshotEntry struct {
+	SnapshotUuid      pgtype.UUID
+	UserUuid          pgtype.UUID
+	Position          int32
+	PointAmount       pgtype.Numeric
+	AchievementAmount int32
+	IsWinner          bool
+	FullName          string
+	GradebookNumber   string
+}
+
 type Resource struct {
 	Uuid  pgtype.UUID
 	Value pgtype.Text
@@ -74,6 +167,22 @@ type RoleGroup struct {
 	GroupUuid pgtype.UUID
 }
 
+type Setting struct {
+	Name      string
+	Value     string
+	UpdatedAt pgtype.Timestamptz
+	UpdatedBy pgtype.UUID
+}
+
+type SettingChange struct {
+	Uuid      pgtype.UUID
+	Name      string
+	OldValue  string
+	NewValue  string
+	ChangedBy pgtype.UUID
+	ChangedAt pgtype.Timestamptz
+}
+
 type Status struct {
 	Uuid          pgtype.UUID
 	InternalValue pgtype.Text
@@ -81,6 +190,15 @@ type Status struct {
 	Type          pgtype.Text
 }
 
+type StudentScore struct {
+	UserUuid           pgtype.UUID
+	PointAmount        pgtype.Numeric
+	AchievementAmount  int32
+	AchievementProofed bool
+	LastSubmittedAt    pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+}
+
 type SysUser struct {
 	Uuid            pgtype.UUID
 	Name            string
@@ -93,6 +211,7 @@ type SysUser struct {
 	StatusUuid      pgtype.UUID
 	Password        pgtype.Text
 	Salt            pgtype.Text
+	OrgUnitUuid     pgtype.UUID
 }
 
 type UserAchievement struct {
@@ -0,0 +1,239 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: org_units.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const assignUsersToOrgUnit = `-- name: AssignUsersToOrgUnit :execrows
+update sys_user
+set org_unit_uuid = $1
+where uuid = any ($2::uuid[])
+`
+
+type AssignUsersToOrgUnitParams struct {
+	OrgUnitUuid pgtype.UUID
+	UserUuids   []pgtype.UUID
+}
+
+func (q *Queries) AssignUsersToOrgUnit(ctx context.Context, arg AssignUsersToOrgUnitParams) (int64, error) {
+	result, err := q.db.Exec(ctx, assignUsersToOrgUnit, arg.OrgUnitUuid, arg.UserUuids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createOrgUnit = `-- name: CreateOrgUnit :one
+insert into org_unit (name, kind, parent_uuid, winner_quota)
+values ($1, $2, $3, $4)
+returning uuid
+`
+
+type CreateOrgUnitParams struct {
+	Name        string
+	Kind        string
+	ParentUuid  pgtype.UUID
+	WinnerQuota pgtype.Int4
+}
+
+func (q *Queries) CreateOrgUnit(ctx context.Context, arg CreateOrgUnitParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createOrgUnit,
+		arg.Name,
+		arg.Kind,
+		arg.ParentUuid,
+		arg.WinnerQuota,
+	)
+	var uuid pgtype.UUID
+	err := row.Scan(&uuid)
+	return uuid, err
+}
+
+const deleteOrgUnit = `-- name: DeleteOrgUnit :execrows
+delete
+from org_unit
+where uuid = $1
+`
+
+func (q *Queries) DeleteOrgUnit(ctx context.Context, uuid pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrgUnit, uuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getOrgUnitByUUID = `-- name: GetOrgUnitByUUID :one
+select u.uuid, u.name, u.kind, u.parent_uuid, u.winner_quota, u.created_at,
+       (select count(*) from sys_user su where su.org_unit_uuid = u.uuid) as user_amount
+from org_unit u
+where u.uuid = $1
+`
+
+type GetOrgUnitByUUIDRow struct {
+	Uuid        pgtype.UUID
+	Name        string
+	Kind        string
+	ParentUuid  pgtype.UUID
+	WinnerQuota pgtype.Int4
+	CreatedAt   pgtype.Timestamptz
+	UserAmount  int64
+}
+
+func (q *Queries) GetOrgUnitByUUID(ctx context.Context, uuid pgtype.UUID) (GetOrgUnitByUUIDRow, error) {
+	row := q.db.QueryRow(ctx, getOrgUnitByUUID, uuid)
+	var i GetOrgUnitByUUIDRow
+	err := row.Scan(
+		&i.Uuid,
+		&i.Name,
+		&i.Kind,
+		&i.ParentUuid,
+		&i.WinnerQuota,
+		&i.CreatedAt,
+		&i.UserAmount,
+	)
+	return i, err
+}
+
+const getOrgUnitSubtree = `-- name: GetOrgUnitSubtree :many
+with recursive subtree as (select u.uuid
+                           from org_unit u
+                           where u.uuid = $1
+                           union
+                           select child.uuid
+                           from org_unit child
+                                    join subtree on child.parent_uuid = subtree.uuid)
+select uuid
+from subtree
+`
+
+func (q *Queries) GetOrgUnitSubtree(ctx context.Context, uuid pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getOrgUnitSubtree, uuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var uuid pgtype.UUID
+		if err := rows.Scan(&uuid); err != nil {
+			return nil, err
+		}
+		items = append(items, uuid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasOrgUnitDependents = `-- name: HasOrgUnitDependents :one
+select (exists(select 1 from org_unit where parent_uuid = $1::uuid)
+    or exists(select 1 from sys_user where org_unit_uuid = $1::uuid))::bool as in_use
+`
+
+func (q *Queries) HasOrgUnitDependents(ctx context.Context, uuid pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, hasOrgUnitDependents, uuid)
+	var in_use bool
+	err := row.Scan(&in_use)
+	return in_use, err
+}
+
+const listOrgUnits = `-- name: ListOrgUnits :many
+select u.uuid, u.name, u.kind, u.parent_uuid, u.winner_quota, u.created_at,
+       (select count(*) from sys_user su where su.org_unit_uuid = u.uuid) as user_amount
+from org_unit u
+order by u.name, u.uuid
+`
+
+type ListOrgUnitsRow struct {
+	Uuid        pgtype.UUID
+	Name        string
+	Kind        string
+	ParentUuid  pgtype.UUID
+	WinnerQuota pgtype.Int4
+	CreatedAt   pgtype.Timestamptz
+	UserAmount  int64
+}
+
+func (q *Queries) ListOrgUnits(ctx context.Context) ([]ListOrgUnitsRow, error) {
+	rows, err := q.db.Query(ctx, listOrgUnits)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrgUnitsRow
+	for rows.Next() {
+		var i ListOrgUnitsRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Name,
+			&i.Kind,
+			&i.ParentUuid,
+			&i.WinnerQuota,
+			&i.CreatedAt,
+			&i.UserAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unassignUsersFromOrgUnit = `-- name: UnassignUsersFromOrgUnit :execrows
+update sys_user
+set org_unit_uuid = null
+where org_unit_uuid = $1
+  and uuid = any ($2::uuid[])
+`
+
+type UnassignUsersFromOrgUnitParams struct {
+	OrgUnitUuid pgtype.UUID
+	UserUuids   []pgtype.UUID
+}
+
+func (q *Queries) UnassignUsersFromOrgUnit(ctx context.Context, arg UnassignUsersFromOrgUnitParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unassignUsersFromOrgUnit, arg.OrgUnitUuid, arg.UserUuids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateOrgUnit = `-- name: UpdateOrgUnit :exec
+update org_unit
+set name         = $1,
+    kind         = $2,
+    parent_uuid  = $3,
+    winner_quota = $4
+where uuid = $5
+`
+
+type UpdateOrgUnitParams struct {
+	Name        string
+	Kind        string
+	ParentUuid  pgtype.UUID
+	WinnerQuota pgtype.Int4
+	Uuid        pgtype.UUID
+}
+
+func (q *Queries) UpdateOrgUnit(ctx context.Context, arg UpdateOrgUnitParams) error {
+	_, err := q.db.Exec(ctx, updateOrgUnit,
+		arg.Name,
+		arg.Kind,
+		arg.ParentUuid,
+		arg.WinnerQuota,
+		arg.Uuid,
+	)
+	return err
+}
@@ -0,0 +1,387 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: periods.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createPeriod = `-- name: CreatePeriod :one
+insert into period (name, start_date, end_date, submission_deadline, status, winner_count)
+values ($1, $2, $3, $4, $5, $6)
+returning uuid
+`
+
+type CreatePeriodParams struct {
+	Name               string
+	StartDate          pgtype.Date
+	EndDate            pgtype.Date
+	SubmissionDeadline pgtype.Date
+	Status             string
+	WinnerCount        int32
+}
+
+func (q *Queries) CreatePeriod(ctx context.Context, arg CreatePeriodParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createPeriod,
+		arg.Name,
+		arg.StartDate,
+		arg.EndDate,
+		arg.SubmissionDeadline,
+		arg.Status,
+		arg.WinnerCount,
+	)
+	var uuid pgtype.UUID
+	err := row.Scan(&uuid)
+	return uuid, err
+}
+
+const deletePeriod = `-- name: DeletePeriod :execrows
+delete
+from period
+where uuid = $1
+`
+
+func (q *Queries) DeletePeriod(ctx context.Context, uuid pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePeriod, uuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getAchievementPeriod = `-- name: GetAchievementPeriod :one
+select p.uuid, p.name, p.start_date, p.end_date, p.submission_deadline, p.status, p.winner_count, p.created_at
+from period p
+         join achievement a on coalesce(a.achievement_date, a.created_at::date) between p.start_date and p.end_date
+where a.uuid = $1
+order by p.start_date desc
+limit 1
+`
+
+func (q *Queries) GetAchievementPeriod(ctx context.Context, uuid pgtype.UUID) (Period, error) {
+	row := q.db.QueryRow(ctx, getAchievementPeriod, uuid)
+	var i Period
+	err := row.Scan(
+		&i.Uuid,
+		&i.Name,
+		&i.StartDate,
+		&i.EndDate,
+		&i.SubmissionDeadline,
+		&i.Status,
+		&i.WinnerCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOverlappingPeriods = `-- name: GetOverlappingPeriods :many
+select uuid, name, start_date, end_date, submission_deadline, status, winner_count, created_at
+from period
+where start_date <= $1
+  and end_date >= $2
+  and ($3::uuid is null or uuid != $3)
+`
+
+type GetOverlappingPeriodsParams struct {
+	EndDate     pgtype.Date
+	StartDate   pgtype.Date
+	ExcludeUuid pgtype.UUID
+}
+
+func (q *Queries) GetOverlappingPeriods(ctx context.Context, arg GetOverlappingPeriodsParams) ([]Period, error) {
+	rows, err := q.db.Query(ctx, getOverlappingPeriods, arg.EndDate, arg.StartDate, arg.ExcludeUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Period
+	for rows.Next() {
+		var i Period
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Name,
+			&i.StartDate,
+			&i.EndDate,
+			&i.SubmissionDeadline,
+			&i.Status,
+			&i.WinnerCount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPeriodAchievementPoints = `-- name: GetPeriodAchievementPoints :many
+select a.uuid                          as achievement_uuid,
+       u.uuid                          as user_uuid,
+       u.name                          as user_name,
+       u.second_name                   as user_second_name,
+       u.patronymic                    as user_patronymic,
+       u.gradebook_number,
+       u_s.internal_value              as user_status,
+       u.org_unit_uuid                 as user_org_unit_uuid,
+       c.uuid                          as category_uuid,
+       c.name                          as category_name,
+       a.created_at                    as submitted_at
+from achievement a
+         join status a_s on a_s.uuid = a.status_uuid and a_s.type = 'achievement_status'
+         join sys_user u on u.uuid = a.user_uuid
+         join status u_s on u_s.uuid = u.status_uuid
+         join achievement_category ac on ac.achievement_uuid = a.uuid
+         join category c on c.uuid = ac.category_uuid and c.parent_category is null
+         join status c_s on c_s.uuid = c.status_uuid and c_s.internal_value = 'active'
+where a_s.internal_value = any ($1::varchar[])
+  and ($2::date is null or coalesce(a.achievement_date, a.created_at::date) >= $2)
+  and ($3::date is null or coalesce(a.achievement_date, a.created_at::date) <= $3)
+order by u.uuid, a.uuid
+`
+
+type GetPeriodAchievementPointsParams struct {
+	Statuses  []string
+	StartDate pgtype.Date
+	EndDate   pgtype.Date
+}
+
+type GetPeriodAchievementPointsRow struct {
+	AchievementUuid pgtype.UUID
+	UserUuid        pgtype.UUID
+	UserName        string
+	UserSecondName  string
+	UserPatronymic  pgtype.Text
+	GradebookNumber string
+	UserStatus      pgtype.Text
+	UserOrgUnitUuid pgtype.UUID
+	CategoryUuid    pgtype.UUID
+	CategoryName    string
+	SubmittedAt     pgtype.Timestamptz
+}
+
+func (q *Queries) GetPeriodAchievementPoints(ctx context.Context, arg GetPeriodAchievementPointsParams) ([]GetPeriodAchievementPointsRow, error) {
+	rows, err := q.db.Query(ctx, getPeriodAchievementPoints, arg.Statuses, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPeriodAchievementPointsRow
+	for rows.Next() {
+		var i GetPeriodAchievementPointsRow
+		if err := rows.Scan(
+			&i.AchievementUuid,
+			&i.UserUuid,
+			&i.UserName,
+			&i.UserSecondName,
+			&i.UserPatronymic,
+			&i.GradebookNumber,
+			&i.UserStatus,
+			&i.UserOrgUnitUuid,
+			&i.CategoryUuid,
+			&i.CategoryName,
+			&i.SubmittedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPeriodByDate = `-- name: GetPeriodByDate :one
+select uuid, name, start_date, end_date, submission_deadline, status, winner_count, created_at
+from period
+where $1::date between start_date and end_date
+order by start_date desc
+limit 1
+`
+
+func (q *Queries) GetPeriodByDate(ctx context.Context, date pgtype.Date) (Period, error) {
+	row := q.db.QueryRow(ctx, getPeriodByDate, date)
+	var i Period
+	err := row.Scan(
+		&i.Uuid,
+		&i.Name,
+		&i.StartDate,
+		&i.EndDate,
+		&i.SubmissionDeadline,
+		&i.Status,
+		&i.WinnerCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPeriodByUUID = `-- name: GetPeriodByUUID :one
+select uuid, name, start_date, end_date, submission_deadline, status, winner_count, created_at
+from period
+where uuid = $1
+`
+
+func (q *Queries) GetPeriodByUUID(ctx context.Context, uuid pgtype.UUID) (Period, error) {
+	row := q.db.QueryRow(ctx, getPeriodByUUID, uuid)
+	var i Period
+	err := row.Scan(
+		&i.Uuid,
+		&i.Name,
+		&i.StartDate,
+		&i.EndDate,
+		&i.SubmissionDeadline,
+		&i.Status,
+		&i.WinnerCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPeriodByUUIDForUpdate = `-- name: GetPeriodByUUIDForUpdate :one
+select uuid, name, start_date, end_date, submission_deadline, status, winner_count, created_at
+from period
+where uuid = $1
+    for update
+`
+
+func (q *Queries) GetPeriodByUUIDForUpdate(ctx context.Context, uuid pgtype.UUID) (Period, error) {
+	row := q.db.QueryRow(ctx, getPeriodByUUIDForUpdate, uuid)
+	var i Period
+	err := row.Scan(
+		&i.Uuid,
+		&i.Name,
+		&i.StartDate,
+		&i.EndDate,
+		&i.SubmissionDeadline,
+		&i.Status,
+		&i.WinnerCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const hasPeriodSnapshots = `-- name: HasPeriodSnapshots :one
+select exists(select 1 from rating_snapshot where period_uuid = $1)::bool as has_snapshots
+`
+
+func (q *Queries) HasPeriodSnapshots(ctx context.Context, periodUuid pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, hasPeriodSnapshots, periodUuid)
+	var has_snapshots bool
+	err := row.Scan(&has_snapshots)
+	return has_snapshots, err
+}
+
+const listPeriods = `-- name: ListPeriods :many
+select p.uuid, p.name, p.start_date, p.end_date, p.submission_deadline, p.status, p.winner_count, p.created_at,
+       count(*) over () as total_records
+from period p
+where ($1::varchar is null or p.status = $1)
+order by p.start_date desc
+limit $3 offset $2
+`
+
+type ListPeriodsParams struct {
+	Status    pgtype.Text
+	RowOffset int32
+	RowLimit  int32
+}
+
+type ListPeriodsRow struct {
+	Uuid               pgtype.UUID
+	Name               string
+	StartDate          pgtype.Date
+	EndDate            pgtype.Date
+	SubmissionDeadline pgtype.Date
+	Status             string
+	WinnerCount        int32
+	CreatedAt          pgtype.Timestamptz
+	TotalRecords       int64
+}
+
+func (q *Queries) ListPeriods(ctx context.Context, arg ListPeriodsParams) ([]ListPeriodsRow, error) {
+	rows, err := q.db.Query(ctx, listPeriods, arg.Status, arg.RowOffset, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPeriodsRow
+	for rows.Next() {
+		var i ListPeriodsRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Name,
+			&i.StartDate,
+			&i.EndDate,
+			&i.SubmissionDeadline,
+			&i.Status,
+			&i.WinnerCount,
+			&i.CreatedAt,
+			&i.TotalRecords,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePeriod = `-- name: UpdatePeriod :exec
+update period
+set name                = $2,
+    start_date          = $3,
+    end_date            = $4,
+    submission_deadline = $5,
+    status              = $6,
+    winner_count        = $7
+where uuid = $1
+`
+
+type UpdatePeriodParams struct {
+	Uuid               pgtype.UUID
+	Name               string
+	StartDate          pgtype.Date
+	EndDate            pgtype.Date
+	SubmissionDeadline pgtype.Date
+	Status             string
+	WinnerCount        int32
+}
+
+func (q *Queries) UpdatePeriod(ctx context.Context, arg UpdatePeriodParams) error {
+	_, err := q.db.Exec(ctx, updatePeriod,
+		arg.Uuid,
+		arg.Name,
+		arg.StartDate,
+		arg.EndDate,
+		arg.SubmissionDeadline,
+		arg.Status,
+		arg.WinnerCount,
+	)
+	return err
+}
+
+const updatePeriodStatus = `-- name: UpdatePeriodStatus :exec
+update period
+set status = $2
+where uuid = $1
+`
+
+type UpdatePeriodStatusParams struct {
+	Uuid   pgtype.UUID
+	Status string
+}
+
+func (q *Queries) UpdatePeriodStatus(ctx context.Context, arg UpdatePeriodStatusParams) error {
+	_, err := q.db.Exec(ctx, updatePeriodStatus, arg.Uuid, arg.Status)
+	return err
+}
@@ -319,7 +319,7 @@ func (q *Queries) GetRoleListWithPagination(ctx context.Context, arg GetRoleList
 }
 
 const getRoleMembers = `-- name: GetRoleMembers :many
-select u.uuid, u.name, u.second_name, u.patronymic, u.gradebook_number, u.birth_date, u.email, u.phone_number, u.status_uuid, u.password, u.salt
+select u.uuid, u.name, u.second_name, u.patronymic, u.gradebook_number, u.birth_date, u.email, u.phone_number, u.status_uuid, u.password, u.salt, u.org_unit_uuid
 from sys_user u
          join user_role r on r.user_uuid = u.uuid
 where role_uuid = $1
@@ -346,6 +346,7 @@ func (q *Queries) GetRoleMembers(ctx context.Context, roleUuid pgtype.UUID) ([]S
 			&i.StatusUuid,
 			&i.Password,
 			&i.Salt,
+			&i.OrgUnitUuid,
 		); err != nil {
 			return nil, err
 		}
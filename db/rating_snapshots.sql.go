@@ -0,0 +1,315 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: rating_snapshots.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createRatingSnapshot = `-- name: CreateRatingSnapshot :one
+insert into rating_snapshot (period_uuid, reason, created_by, rule_version)
+values ($1, $2, $3, $4)
+returning uuid, created_at
+`
+
+type CreateRatingSnapshotParams struct {
+	PeriodUuid  pgtype.UUID
+	Reason      string
+	CreatedBy   pgtype.UUID
+	RuleVersion string
+}
+
+type CreateRatingSnapshotRow struct {
+	Uuid      pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateRatingSnapshot(ctx context.Context, arg CreateRatingSnapshotParams) (CreateRatingSnapshotRow, error) {
+	row := q.db.QueryRow(ctx, createRatingSnapshot,
+		arg.PeriodUuid,
+		arg.Reason,
+		arg.CreatedBy,
+		arg.RuleVersion,
+	)
+	var i CreateRatingSnapshotRow
+	err := row.Scan(&i.Uuid, &i.CreatedAt)
+	return i, err
+}
+
+const createRatingSnapshotAchievement = `-- name: CreateRatingSnapshotAchievement :exec
+insert into rating_snapshot_achievement (snapshot_uuid, achievement_uuid, user_uuid, category_uuid, category_name,
+                                         point_amount)
+values ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateRatingSnapshotAchievementParams struct {
+	SnapshotUuid    pgtype.UUID
+	AchievementUuid pgtype.UUID
+	UserUuid        pgtype.UUID
+	CategoryUuid    pgtype.UUID
+	CategoryName    string
+	PointAmount     pgtype.Numeric
+}
+
+func (q *Queries) CreateRatingSnapshotAchievement(ctx context.Context, arg CreateRatingSnapshotAchievementParams) error {
+	_, err := q.db.Exec(ctx, createRatingSnapshotAchievement,
+		arg.SnapshotUuid,
+		arg.AchievementUuid,
+		arg.UserUuid,
+		arg.CategoryUuid,
+		arg.CategoryName,
+		arg.PointAmount,
+	)
+	return err
+}
+
+const createRatingSnapshotEntry = `-- name: CreateRatingSnapshotEntry :exec
+insert into rating_snapshot_entry (snapshot_uuid, user_uuid, position, point_amount, achievement_amount, is_winner,
+                                   full_name, gradebook_number)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateRatingSnapshotEntryParams struct {
+	SnapshotUuid      pgtype.UUID
+	UserUuid          pgtype.UUID
+	Position          int32
+	PointAmount       pgtype.Numeric
+	AchievementAmount int32
+	IsWinner          bool
+	FullName          string
+	GradebookNumber   string
+}
+
+func (q *Queries) CreateRatingSnapshotEntry(ctx context.Context, arg CreateRatingSnapshotEntryParams) error {
+	_, err := q.db.Exec(ctx, createRatingSnapshotEntry,
+		arg.SnapshotUuid,
+		arg.UserUuid,
+		arg.Position,
+		arg.PointAmount,
+		arg.AchievementAmount,
+		arg.IsWinner,
+		arg.FullName,
+		arg.GradebookNumber,
+	)
+	return err
+}
+
+const getCloseOutRatingSnapshotUUID = `-- name: GetCloseOutRatingSnapshotUUID :one
+select uuid
+from rating_snapshot
+where period_uuid = $1
+  and reason = 'close_out'
+order by created_at desc
+limit 1
+`
+
+func (q *Queries) GetCloseOutRatingSnapshotUUID(ctx context.Context, periodUuid pgtype.UUID) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getCloseOutRatingSnapshotUUID, periodUuid)
+	var uuid pgtype.UUID
+	err := row.Scan(&uuid)
+	return uuid, err
+}
+
+const getRatingRuleVersion = `-- name: GetRatingRuleVersion :one
+select md5(coalesce(string_agg(r.rule, ';' order by r.rule), '') || '|' || $1::text)::varchar as rule_version
+from (select c.uuid::text || '=' || c.point_amount::text || ':' || coalesce(c.parent_category::text, '') ||
+             ':' || coalesce(c_s.internal_value, '') || ':' || coalesce(c.max_points::text, '') || ':' ||
+             coalesce(c.max_achievements::text, '') || ':' || c.multiplier::text || ':' ||
+             c.diminishing_factor::text as rule
+      from category c
+               left join status c_s on c_s.uuid = c.status_uuid
+      union all
+      select cv.uuid::text || '=' || cv.point::text || ':' || cv.category_uuid::text || ':' ||
+             coalesce(cv_s.internal_value, '') as rule
+      from category_value cv
+               left join status cv_s on cv_s.uuid = cv.status_uuid) as r
+`
+
+// The rule version changes whenever a scoring relevant category or value changes or the winners are picked differently.
+func (q *Queries) GetRatingRuleVersion(ctx context.Context, winnerRules string) (string, error) {
+	row := q.db.QueryRow(ctx, getRatingRuleVersion, winnerRules)
+	var rule_version string
+	err := row.Scan(&rule_version)
+	return rule_version, err
+}
+
+const getRatingSnapshot = `-- name: GetRatingSnapshot :one
+select s.uuid,
+       s.period_uuid,
+       p.name                                                                         as period_name,
+       s.reason,
+       s.created_by,
+       s.rule_version,
+       s.created_at,
+       (select count(*) from rating_snapshot_entry e where e.snapshot_uuid = s.uuid) as entry_amount
+from rating_snapshot s
+         left join period p on p.uuid = s.period_uuid
+where s.uuid = $1
+`
+
+type GetRatingSnapshotRow struct {
+	Uuid        pgtype.UUID
+	PeriodUuid  pgtype.UUID
+	PeriodName  pgtype.Text
+	Reason      string
+	CreatedBy   pgtype.UUID
+	RuleVersion string
+	CreatedAt   pgtype.Timestamptz
+	EntryAmount int64
+}
+
+func (q *Queries) GetRatingSnapshot(ctx context.Context, uuid pgtype.UUID) (GetRatingSnapshotRow, error) {
+	row := q.db.QueryRow(ctx, getRatingSnapshot, uuid)
+	var i GetRatingSnapshotRow
+	err := row.Scan(
+		&i.Uuid,
+		&i.PeriodUuid,
+		&i.PeriodName,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.RuleVersion,
+		&i.CreatedAt,
+		&i.EntryAmount,
+	)
+	return i, err
+}
+
+const getRatingSnapshotAchievements = `-- name: GetRatingSnapshotAchievements :many
+select snapshot_uuid, achievement_uuid, user_uuid, category_uuid, category_name, point_amount
+from rating_snapshot_achievement
+where snapshot_uuid = $1
+order by user_uuid, achievement_uuid
+`
+
+func (q *Queries) GetRatingSnapshotAchievements(ctx context.Context, snapshotUuid pgtype.UUID) ([]RatingSnapshotAchievement, error) {
+	rows, err := q.db.Query(ctx, getRatingSnapshotAchievements, snapshotUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RatingSnapshotAchievement
+	for rows.Next() {
+		var i RatingSnapshotAchievement
+		if err := rows.Scan(
+			&i.SnapshotUuid,
+			&i.AchievementUuid,
+			&i.UserUuid,
+			&i.CategoryUuid,
+			&i.CategoryName,
+			&i.PointAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRatingSnapshotEntries = `-- name: GetRatingSnapshotEntries :many
+select snapshot_uuid, user_uuid, position, point_amount, achievement_amount, is_winner, full_name, gradebook_number
+from rating_snapshot_entry
+where snapshot_uuid = $1
+order by position
+`
+
+func (q *Queries) GetRatingSnapshotEntries(ctx context.Context, snapshotUuid pgtype.UUID) ([]RatingSnapshotEntry, error) {
+	rows, err := q.db.Query(ctx, getRatingSnapshotEntries, snapshotUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RatingSnapshotEntry
+	for rows.Next() {
+		var i RatingSnapshotEntry
+		if err := rows.Scan(
+			&i.SnapshotUuid,
+			&i.UserUuid,
+			&i.Position,
+			&i.PointAmount,
+			&i.AchievementAmount,
+			&i.IsWinner,
+			&i.FullName,
+			&i.GradebookNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRatingSnapshots = `-- name: ListRatingSnapshots :many
+select s.uuid,
+       s.period_uuid,
+       p.name                                                                         as period_name,
+       s.reason,
+       s.created_by,
+       s.rule_version,
+       s.created_at,
+       (select count(*) from rating_snapshot_entry e where e.snapshot_uuid = s.uuid) as entry_amount,
+       count(*) over ()                                                               as total_records
+from rating_snapshot s
+         left join period p on p.uuid = s.period_uuid
+where ($1::uuid is null or s.period_uuid = $1)
+order by s.created_at desc
+limit $3 offset $2
+`
+
+type ListRatingSnapshotsParams struct {
+	PeriodUuid pgtype.UUID
+	RowOffset  int32
+	RowLimit   int32
+}
+
+type ListRatingSnapshotsRow struct {
+	Uuid         pgtype.UUID
+	PeriodUuid   pgtype.UUID
+	PeriodName   pgtype.Text
+	Reason       string
+	CreatedBy    pgtype.UUID
+	RuleVersion  string
+	CreatedAt    pgtype.Timestamptz
+	EntryAmount  int64
+	TotalRecords int64
+}
+
+func (q *Queries) ListRatingSnapshots(ctx context.Context, arg ListRatingSnapshotsParams) ([]ListRatingSnapshotsRow, error) {
+	rows, err := q.db.Query(ctx, listRatingSnapshots, arg.PeriodUuid, arg.RowOffset, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRatingSnapshotsRow
+	for rows.Next() {
+		var i ListRatingSnapshotsRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.PeriodUuid,
+			&i.PeriodName,
+			&i.Reason,
+			&i.CreatedBy,
+			&i.RuleVersion,
+			&i.CreatedAt,
+			&i.EntryAmount,
+			&i.TotalRecords,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,128 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: scoring.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getAchievementScoreInputs = `-- name: GetAchievementScoreInputs :many
+select a.uuid                                         as achievement_uuid,
+       a.user_uuid,
+       a_s.internal_value                             as achievement_status,
+       coalesce(a.achievement_date, a.created_at::date)::date as achievement_date,
+       a.created_at                                   as submitted_at,
+       c.uuid                                         as category_uuid,
+       c.name                                         as category_name,
+       c.point_amount                                 as category_points,
+       coalesce(c_s.internal_value = 'active', false)::bool as category_active,
+       c.max_points                                   as category_max_points,
+       c.max_achievements                             as category_max_achievements,
+       c.multiplier                                   as category_multiplier,
+       c.diminishing_factor                           as category_diminishing_factor,
+       cv.uuid                                        as value_uuid,
+       cv.name                                        as value_name,
+       cv.category_uuid                               as subcategory_uuid,
+       sc.name                                        as subcategory_name,
+       cv.point                                       as value_points,
+       coalesce(cv_s.internal_value is distinct from 'unactive' and
+                sc_s.internal_value is distinct from 'unactive' and
+                sc.parent_category = c.uuid, false)::bool as value_counted
+from achievement a
+         join status a_s on a_s.uuid = a.status_uuid and a_s.type = 'achievement_status'
+         join achievement_category ac on ac.achievement_uuid = a.uuid
+         join category c on c.uuid = ac.category_uuid and c.parent_category is null
+         left join status c_s on c_s.uuid = c.status_uuid
+         left join achievement_category_value acv on acv.achievement_uuid = a.uuid
+         left join category_value cv on cv.uuid = acv.category_value_uuid
+         left join status cv_s on cv_s.uuid = cv.status_uuid
+         left join category sc on sc.uuid = cv.category_uuid
+         left join status sc_s on sc_s.uuid = sc.status_uuid
+where ($1::uuid[] is null or a.uuid = any ($1::uuid[]))
+  and ($2::uuid[] is null or a.user_uuid = any ($2::uuid[]))
+  and ($3::varchar[] is null or a_s.internal_value = any ($3::varchar[]))
+  and ($4::date is null or coalesce(a.achievement_date, a.created_at::date) >= $4)
+  and ($5::date is null or coalesce(a.achievement_date, a.created_at::date) <= $5)
+order by a.uuid, cv.uuid
+`
+
+type GetAchievementScoreInputsParams struct {
+	AchievementUuids []pgtype.UUID
+	UserUuids        []pgtype.UUID
+	Statuses         []string
+	StartDate        pgtype.Date
+	EndDate          pgtype.Date
+}
+
+type GetAchievementScoreInputsRow struct {
+	AchievementUuid           pgtype.UUID
+	UserUuid                  pgtype.UUID
+	AchievementStatus         pgtype.Text
+	AchievementDate           pgtype.Date
+	SubmittedAt               pgtype.Timestamptz
+	CategoryUuid              pgtype.UUID
+	CategoryName              string
+	CategoryPoints            pgtype.Numeric
+	CategoryActive            bool
+	CategoryMaxPoints         pgtype.Numeric
+	CategoryMaxAchievements   pgtype.Int4
+	CategoryMultiplier        pgtype.Numeric
+	CategoryDiminishingFactor pgtype.Numeric
+	ValueUuid                 pgtype.UUID
+	ValueName                 pgtype.Text
+	SubcategoryUuid           pgtype.UUID
+	SubcategoryName           pgtype.Text
+	ValuePoints               pgtype.Numeric
+	ValueCounted              bool
+}
+
+func (q *Queries) GetAchievementScoreInputs(ctx context.Context, arg GetAchievementScoreInputsParams) ([]GetAchievementScoreInputsRow, error) {
+	rows, err := q.db.Query(ctx, getAchievementScoreInputs,
+		arg.AchievementUuids,
+		arg.UserUuids,
+		arg.Statuses,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAchievementScoreInputsRow
+	for rows.Next() {
+		var i GetAchievementScoreInputsRow
+		if err := rows.Scan(
+			&i.AchievementUuid,
+			&i.UserUuid,
+			&i.AchievementStatus,
+			&i.AchievementDate,
+			&i.SubmittedAt,
+			&i.CategoryUuid,
+			&i.CategoryName,
+			&i.CategoryPoints,
+			&i.CategoryActive,
+			&i.CategoryMaxPoints,
+			&i.CategoryMaxAchievements,
+			&i.CategoryMultiplier,
+			&i.CategoryDiminishingFactor,
+			&i.ValueUuid,
+			&i.ValueName,
+			&i.SubcategoryUuid,
+			&i.SubcategoryName,
+			&i.ValuePoints,
+			&i.ValueCounted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,169 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: setting.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createMissingSetting = `-- name: CreateMissingSetting :exec
+insert into setting (name, value)
+values ($1, $2)
+on conflict (name) do nothing
+`
+
+type CreateMissingSettingParams struct {
+	Name  string
+	Value string
+}
+
+// Stores the default of a setting that was never stored, so its row can be locked.
+func (q *Queries) CreateMissingSetting(ctx context.Context, arg CreateMissingSettingParams) error {
+	_, err := q.db.Exec(ctx, createMissingSetting, arg.Name, arg.Value)
+	return err
+}
+
+const createSettingChange = `-- name: CreateSettingChange :exec
+insert into setting_change (name, old_value, new_value, changed_by)
+values ($1, $2, $3, $4)
+`
+
+type CreateSettingChangeParams struct {
+	Name      string
+	OldValue  string
+	NewValue  string
+	ChangedBy pgtype.UUID
+}
+
+func (q *Queries) CreateSettingChange(ctx context.Context, arg CreateSettingChangeParams) error {
+	_, err := q.db.Exec(ctx, createSettingChange,
+		arg.Name,
+		arg.OldValue,
+		arg.NewValue,
+		arg.ChangedBy,
+	)
+	return err
+}
+
+const getSettingValueForUpdate = `-- name: GetSettingValueForUpdate :one
+select value
+from setting
+where name = $1
+    for update
+`
+
+func (q *Queries) GetSettingValueForUpdate(ctx context.Context, name string) (string, error) {
+	row := q.db.QueryRow(ctx, getSettingValueForUpdate, name)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const listSettingChanges = `-- name: ListSettingChanges :many
+select c.uuid, c.name, c.old_value, c.new_value, c.changed_by, c.changed_at,
+       count(*) over () as total_records
+from setting_change c
+where c.name = $1
+order by c.changed_at desc
+limit $3 offset $2
+`
+
+type ListSettingChangesParams struct {
+	Name      string
+	RowOffset int32
+	RowLimit  int32
+}
+
+type ListSettingChangesRow struct {
+	Uuid         pgtype.UUID
+	Name         string
+	OldValue     string
+	NewValue     string
+	ChangedBy    pgtype.UUID
+	ChangedAt    pgtype.Timestamptz
+	TotalRecords int64
+}
+
+func (q *Queries) ListSettingChanges(ctx context.Context, arg ListSettingChangesParams) ([]ListSettingChangesRow, error) {
+	rows, err := q.db.Query(ctx, listSettingChanges, arg.Name, arg.RowOffset, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSettingChangesRow
+	for rows.Next() {
+		var i ListSettingChangesRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Name,
+			&i.OldValue,
+			&i.NewValue,
+			&i.ChangedBy,
+			&i.ChangedAt,
+			&i.TotalRecords,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSettings = `-- name: ListSettings :many
+select name, value, updated_at, updated_by
+from setting
+order by name
+`
+
+func (q *Queries) ListSettings(ctx context.Context) ([]Setting, error) {
+	rows, err := q.db.Query(ctx, listSettings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Setting
+	for rows.Next() {
+		var i Setting
+		if err := rows.Scan(
+			&i.Name,
+			&i.Value,
+			&i.UpdatedAt,
+			&i.UpdatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSetting = `-- name: UpsertSetting :exec
+insert into setting (name, value, updated_at, updated_by)
+values ($1, $2, now(), $3)
+on conflict (name) do update
+    set value      = excluded.value,
+        updated_at = excluded.updated_at,
+        updated_by = excluded.updated_by
+`
+
+type UpsertSettingParams struct {
+	Name      string
+	Value     string
+	UpdatedBy pgtype.UUID
+}
+
+func (q *Queries) UpsertSetting(ctx context.Context, arg UpsertSettingParams) error {
+	_, err := q.db.Exec(ctx, upsertSetting, arg.Name, arg.Value, arg.UpdatedBy)
+	return err
+}
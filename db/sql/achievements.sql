@@ -2,21 +2,18 @@
 select a.*,
        c.name                         as category_name,
        s.display_value                as status,
+       s.internal_value               as status_code,
        c.uuid                         as category_uuid,
        c.point_amount                 as base_point_amount,
-       coalesce(sum(cv.point) + c.point_amount, 0)::numeric as point_amount,
        count(*) over ()               as total_records
 from achievement a
          join achievement_category ac on ac.achievement_uuid = a.uuid
          left join category c on c.uuid = ac.category_uuid and parent_category is null
-         left join category c_p on c_p.uuid = ac.category_uuid and c_p.parent_category is not null
-         left join achievement_category_value acv on acv.achievement_uuid = a.uuid
-         left join category_value cv on cv.uuid = acv.category_value_uuid and cv.category_uuid = c_p.uuid
          join status s on s.uuid = a.status_uuid
 where a.uuid = $1
   and c.uuid is not null
 group by c.name, a.uuid, a.comment, c.point_amount, c.uuid, attachment_link, a.user_uuid, a.status_uuid,
-         s.display_value;
+         s.display_value, s.internal_value;
 
 -- name: GetAchievementSubCategories :many
 select distinct c.uuid, c.name, cv.name as selected_value, cv.point, av_cv.name as available_value
@@ -33,14 +30,10 @@ select a.*,
        c.name                         as category_name,
        s.display_value                as status,
        c.uuid                         as category_uuid,
-       coalesce(sum(cv.point) + c.point_amount, 0)::numeric as point_amount,
        count(*) over ()               as total_records
 from achievement a
          join achievement_category ac on ac.achievement_uuid = a.uuid
          left join category c on c.uuid = ac.category_uuid and parent_category is null
-         left join category c_p on c_p.uuid = ac.category_uuid and c_p.parent_category is not null
-         left join achievement_category_value acv on acv.achievement_uuid = a.uuid
-         left join category_value cv on cv.uuid = acv.category_value_uuid and cv.category_uuid = c_p.uuid
          join status s on s.uuid = a.status_uuid
 where a.user_uuid = $1
   and s.internal_value != 'removed'
@@ -53,14 +46,10 @@ select a.*,
        c.name                         as category_name,
        s.display_value                as status,
        c.uuid                         as category_uuid,
-       coalesce(sum(cv.point) + c.point_amount, 0)::numeric as point_amount,
        count(*) over ()               as total_records
 from achievement a
          join achievement_category ac on ac.achievement_uuid = a.uuid
          left join category c on c.uuid = ac.category_uuid and parent_category is null
-         left join category c_p on c_p.uuid = ac.category_uuid and c_p.parent_category is not null
-         left join achievement_category_value acv on acv.achievement_uuid = a.uuid
-         left join category_value cv on cv.uuid = acv.category_value_uuid and cv.category_uuid = c_p.uuid
          join status s on s.uuid = a.status_uuid
 where a.user_uuid = $1
   and s.internal_value != 'removed'
@@ -70,9 +59,11 @@ group by c.name, a.uuid, a.comment, c.point_amount, c.uuid, attachment_link, a.u
 limit $2 offset $3;
 
 -- name: CreateAchievement :one
-insert into achievement (comment, attachment_link, user_uuid, status_uuid)
+insert into achievement (comment, attachment_link, user_uuid, status_uuid, attachment_link_normalized,
+                         achievement_date, possible_duplicate_of)
 values ($1, $2, $3,
-        (select s.uuid from status s where s.internal_value = 'unapproved' and s.type = 'achievement_status'))
+        (select s.uuid from status s where s.internal_value = 'unapproved' and s.type = 'achievement_status'),
+        $4, $5, $6)
 returning uuid;
 
 -- name: CreateBatchAchievementCategory :batchexec
@@ -81,16 +72,21 @@ values ($1, $2);
 
 -- name: UpdateAchievement :exec
 update achievement
-set comment         = $1,
-    attachment_link = $2
+set comment                    = $1,
+    attachment_link            = $2,
+    attachment_link_normalized = $4,
+    achievement_date           = $5
 where uuid = $3;
 
--- name: UpdateAchievementWithStatus :exec
+-- name: UpdateAchievementWithStatus :execrows
 update achievement a
-set comment         = $1,
-    attachment_link = $2,
+set comment                    = $1,
+    attachment_link            = $2,
+    attachment_link_normalized = $4,
+    achievement_date           = $5,
     status_uuid = (select s.uuid from status s where s.internal_value = 'unapproved' and s.type = 'achievement_status')
-where a.uuid = $3;
+where a.uuid = $3
+  and a.status_uuid = (select s.uuid from status s where s.internal_value = sqlc.arg(from_status)::varchar and s.type = 'achievement_status');
 
 -- name: RemoveBatchAchievementCategory :batchexec
 delete
@@ -103,30 +99,50 @@ delete
 from achievement_category
 where achievement_uuid = $1;
 
--- name: MakeAchievementUnapproved :exec
+-- name: MakeAchievementUnapproved :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'unapproved' and type = 'achievement_status')
-where achievement.uuid = $1;
+where achievement.uuid = sqlc.arg(uuid)
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = sqlc.arg(from_status)::varchar
+                                   and type = 'achievement_status');
 
--- name: MakeAchievementApproved :exec
+-- name: MakeAchievementApproved :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'approved' and type = 'achievement_status')
-where achievement.uuid = $1;
+where achievement.uuid = sqlc.arg(uuid)
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = sqlc.arg(from_status)::varchar
+                                   and type = 'achievement_status');
 
--- name: MakeAchievementUsed :exec
+-- name: MakeAchievementUsed :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'used' and type = 'achievement_status')
-where achievement.uuid = $1;
+where achievement.uuid = sqlc.arg(uuid)
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = sqlc.arg(from_status)::varchar
+                                   and type = 'achievement_status');
 
--- name: MakeAchievementDeclined :exec
+-- name: MakeAchievementDeclined :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'declined' and type = 'achievement_status')
-where achievement.uuid = $1;
+where achievement.uuid = sqlc.arg(uuid)
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = sqlc.arg(from_status)::varchar
+                                   and type = 'achievement_status');
 
--- name: MakeAchievementRemoved :exec
+-- name: MakeAchievementRemoved :execrows
 update achievement
 set status_uuid = (select status.uuid from status where internal_value = 'removed' and type = 'achievement_status')
-where achievement.uuid = $1;
+where achievement.uuid = sqlc.arg(uuid)
+  and achievement.status_uuid = (select status.uuid
+                                 from status
+                                 where internal_value = sqlc.arg(from_status)::varchar
+                                   and type = 'achievement_status');
 
 -- name: CreateAchievementCategoryValue :batchexec
 insert into achievement_category_value (achievement_uuid, category_value_uuid)
@@ -137,3 +153,168 @@ delete
 from achievement_category_value
 where achievement_uuid = $1;
 
+
+-- name: CreateAchievementReview :exec
+insert into achievement_review (achievement_uuid, reviewer_uuid, from_status_uuid, to_status_uuid, comment)
+select a.uuid, sqlc.narg(reviewer_uuid), a.status_uuid, s.uuid, sqlc.narg(comment)
+from achievement a
+         join status s on s.internal_value = sqlc.arg(to_status) and s.type = 'achievement_status'
+where a.uuid = sqlc.arg(achievement_uuid)
+  and a.status_uuid != s.uuid;
+
+-- name: GetAchievementReviews :many
+select r.uuid,
+       r.reviewer_uuid,
+       u.name             as reviewer_name,
+       u.second_name      as reviewer_second_name,
+       u.patronymic       as reviewer_patronymic,
+       f_s.display_value  as from_status,
+       t_s.display_value  as to_status,
+       t_s.internal_value as to_status_code,
+       r.comment,
+       r.created_at
+from achievement_review r
+         left join sys_user u on u.uuid = r.reviewer_uuid
+         left join status f_s on f_s.uuid = r.from_status_uuid
+         join status t_s on t_s.uuid = r.to_status_uuid
+where r.achievement_uuid = $1
+order by r.created_at;
+
+-- name: GetAchievementQueue :many
+select a.uuid,
+       a.comment,
+       a.attachment_link,
+       a.user_uuid,
+       a.achievement_date,
+       a.created_at,
+       a.possible_duplicate_of,
+       u.name             as user_name,
+       u.second_name      as user_second_name,
+       u.patronymic       as user_patronymic,
+       c.uuid             as category_uuid,
+       c.name             as category_name,
+       s.display_value    as status,
+       s.internal_value   as status_code,
+       cl.reviewer_uuid   as claimed_by_uuid,
+       ru.name            as claimed_by_name,
+       ru.second_name     as claimed_by_second_name,
+       ru.patronymic      as claimed_by_patronymic,
+       cl.expires_at      as claim_expires_at,
+       count(*) over ()   as total_records
+from achievement a
+         join sys_user u on u.uuid = a.user_uuid
+         join achievement_category ac on ac.achievement_uuid = a.uuid
+         join category c on c.uuid = ac.category_uuid and c.parent_category is null
+         join status s on s.uuid = a.status_uuid
+         left join achievement_claim cl on cl.achievement_uuid = a.uuid and cl.expires_at > now()
+         left join sys_user ru on ru.uuid = cl.reviewer_uuid
+where (sqlc.narg(status)::varchar is null or s.internal_value = sqlc.narg(status))
+  and (sqlc.narg(category_uuid)::uuid is null or c.uuid = sqlc.narg(category_uuid))
+  and (sqlc.narg(created_from)::timestamptz is null or a.created_at >= sqlc.narg(created_from))
+  and (sqlc.narg(created_to)::timestamptz is null or a.created_at < sqlc.narg(created_to))
+  and (sqlc.narg(group_uuid)::uuid is null or exists(select 1
+                                                     from sys_user gu
+                                                              join org_unit g on g.uuid = gu.org_unit_uuid
+                                                     where gu.uuid = a.user_uuid
+                                                       and g.uuid = sqlc.narg(group_uuid)
+                                                       and g.kind = 'group'))
+order by a.created_at, a.uuid
+limit sqlc.arg(row_limit) offset sqlc.arg(row_offset);
+
+-- name: CountAchievementQueueByStatus :many
+select s.internal_value as status_code,
+       s.display_value  as status,
+       count(a.uuid)    as total
+from status s
+         left join achievement a on a.status_uuid = s.uuid
+    and (sqlc.narg(category_uuid)::uuid is null or exists(select 1
+                                                          from achievement_category ac
+                                                          where ac.achievement_uuid = a.uuid
+                                                            and ac.category_uuid = sqlc.narg(category_uuid)))
+    and (sqlc.narg(created_from)::timestamptz is null or a.created_at >= sqlc.narg(created_from))
+    and (sqlc.narg(created_to)::timestamptz is null or a.created_at < sqlc.narg(created_to))
+    and (sqlc.narg(group_uuid)::uuid is null or exists(select 1
+                                                       from sys_user gu
+                                                                join org_unit g on g.uuid = gu.org_unit_uuid
+                                                       where gu.uuid = a.user_uuid
+                                                         and g.uuid = sqlc.narg(group_uuid)
+                                                         and g.kind = 'group'))
+where s.type = 'achievement_status'
+group by s.internal_value, s.display_value
+order by s.internal_value;
+
+-- name: ClaimAchievement :one
+insert into achievement_claim (achievement_uuid, reviewer_uuid, expires_at)
+values (sqlc.arg(achievement_uuid), sqlc.arg(reviewer_uuid), now() + make_interval(secs => sqlc.arg(lease_seconds)::float8))
+on conflict (achievement_uuid) do update
+    set reviewer_uuid = excluded.reviewer_uuid,
+        claimed_at    = now(),
+        expires_at    = excluded.expires_at
+where achievement_claim.expires_at <= now()
+   or achievement_claim.reviewer_uuid = excluded.reviewer_uuid
+returning *;
+
+-- name: GetActiveAchievementClaim :one
+select c.*,
+       u.name        as reviewer_name,
+       u.second_name as reviewer_second_name,
+       u.patronymic  as reviewer_patronymic
+from achievement_claim c
+         join sys_user u on u.uuid = c.reviewer_uuid
+where c.achievement_uuid = $1
+  and c.expires_at > now();
+
+-- name: ReleaseAchievementClaim :execrows
+delete
+from achievement_claim
+where achievement_uuid = $1
+  and reviewer_uuid = $2;
+
+-- name: DeleteAchievementClaim :exec
+delete
+from achievement_claim
+where achievement_uuid = $1;
+
+-- name: TakeAchievementClaim :execrows
+delete
+from achievement_claim
+where achievement_uuid = $1
+  and reviewer_uuid = $2
+  and expires_at > now();
+
+-- name: GetDuplicateLinkEvidence :many
+select a2.uuid as achievement_uuid,
+       a2.user_uuid,
+       a2.attachment_link
+from achievement a
+         join achievement a2 on a2.attachment_link_normalized = a.attachment_link_normalized and a2.uuid != a.uuid
+         join status s on s.uuid = a2.status_uuid
+where a.uuid = $1
+  and a.attachment_link_normalized != ''
+  and s.internal_value != 'removed';
+
+-- name: GetAchievementLinksWithoutFingerprint :many
+select uuid, attachment_link
+from achievement
+where attachment_link_normalized is null
+  and attachment_link != '';
+
+-- name: SetAchievementLinkFingerprint :exec
+update achievement
+set attachment_link_normalized = $2
+where uuid = $1;
+
+-- name: GetDuplicateCandidates :many
+select a.uuid,
+       a.comment,
+       a.achievement_date,
+       array(select cv.category_uuid::text || ':' || cv.name
+             from achievement_category_value acv
+                      join category_value cv on cv.uuid = acv.category_value_uuid
+             where acv.achievement_uuid = a.uuid)::text[] as subcategory_values
+from achievement a
+         join achievement_category ac on ac.achievement_uuid = a.uuid and ac.category_uuid = sqlc.arg(category_uuid)
+         join status s on s.uuid = a.status_uuid
+where a.user_uuid = sqlc.arg(user_uuid)
+  and s.internal_value != 'removed'
+order by a.created_at;
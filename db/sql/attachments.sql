@@ -0,0 +1,48 @@
+-- name: CreateAchievementAttachment :exec
+insert into achievement_attachment (uuid, achievement_uuid, file_name, content_type, size, storage_key, uploaded_by,
+                                    sha256)
+values ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetAchievementAttachments :many
+select *
+from achievement_attachment
+where achievement_uuid = $1
+order by created_at;
+
+-- name: GetAchievementAttachment :one
+select *
+from achievement_attachment
+where uuid = $1;
+
+-- name: CountAchievementAttachments :one
+select count(*)
+from achievement_attachment
+where achievement_uuid = $1;
+
+-- name: DeleteAchievementAttachment :exec
+delete
+from achievement_attachment
+where uuid = $1;
+
+-- name: GetDuplicateAttachmentEvidence :many
+select aa.uuid       as attachment_uuid,
+       aa.file_name,
+       a2.uuid       as duplicate_achievement_uuid,
+       a2.user_uuid  as duplicate_user_uuid,
+       aa2.file_name as duplicate_file_name
+from achievement_attachment aa
+         join achievement_attachment aa2 on aa2.sha256 = aa.sha256 and aa2.achievement_uuid != aa.achievement_uuid
+         join achievement a2 on a2.uuid = aa2.achievement_uuid
+         join status s on s.uuid = a2.status_uuid
+where aa.achievement_uuid = $1
+  and s.internal_value != 'removed';
+
+-- name: GetAttachmentsWithoutHash :many
+select *
+from achievement_attachment
+where sha256 is null;
+
+-- name: SetAttachmentHash :exec
+update achievement_attachment
+set sha256 = $2
+where uuid = $1;
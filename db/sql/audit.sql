@@ -0,0 +1,16 @@
+-- name: CreateAuditEvent :exec
+insert into audit_event (actor_uuid, action, entity_type, entity_id, before_data, after_data, ip, request_id)
+values ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListAuditEvents :many
+select e.*,
+       count(*) over () as total_records
+from audit_event e
+where (sqlc.narg(actor_uuid)::uuid is null or e.actor_uuid = sqlc.narg(actor_uuid))
+  and (sqlc.narg(action)::varchar is null or e.action = sqlc.narg(action))
+  and (sqlc.narg(entity_type)::varchar is null or e.entity_type = sqlc.narg(entity_type))
+  and (sqlc.narg(entity_id)::varchar is null or e.entity_id = sqlc.narg(entity_id))
+  and (sqlc.narg(created_from)::timestamptz is null or e.created_at >= sqlc.narg(created_from))
+  and (sqlc.narg(created_to)::timestamptz is null or e.created_at < sqlc.narg(created_to))
+order by e.created_at desc
+limit sqlc.arg(row_limit) offset sqlc.arg(row_offset);
@@ -1,6 +1,10 @@
 -- name: CreateCategory :one
-insert into category (name, point_amount, parent_category, status_uuid)
-values ($1, $2, $3, (select s.uuid from status s where type = 'category_status' and internal_value = 'active'))
+insert into category (name, point_amount, parent_category, status_uuid, max_points, max_achievements, multiplier,
+                      diminishing_factor)
+values (sqlc.arg(name), sqlc.arg(point_amount), sqlc.arg(parent_category),
+        (select s.uuid from status s where type = 'category_status' and internal_value = 'active'),
+        sqlc.narg(max_points), sqlc.narg(max_achievements), coalesce(sqlc.narg(multiplier)::numeric, 1),
+        coalesce(sqlc.narg(diminishing_factor)::numeric, 1))
 returning uuid;
 
 -- name: CreateCategoryValues :batchexec
@@ -12,13 +16,15 @@ on conflict (name, category_uuid) do update set point = $3, status_uuid = (selec
 delete from category_value where category_uuid = $1;
 
 -- name: GetCategoryByUUID :one
-select c.uuid, c.name, c.point_amount, c.parent_category, c.comment, s.display_value
+select c.uuid, c.name, c.point_amount, c.parent_category, c.comment, s.display_value,
+       c.max_points, c.max_achievements, c.multiplier, c.diminishing_factor
 from category c
          join status s on c.status_uuid = s.uuid and type = 'category_status'
 where c.uuid = $1;
 
 -- name: ListParentCategoriesWithPagination :many
-select c.uuid, c.name, c.point_amount, s.display_value, count(c.uuid) over () as total_amount, count(c_c.uuid) as sub_amount
+select c.uuid, c.name, c.point_amount, s.display_value, count(c.uuid) over () as total_amount, count(c_c.uuid) as sub_amount,
+       c.max_points, c.max_achievements, c.multiplier, c.diminishing_factor
 from category c
          join status s on c.status_uuid = s.uuid and type = 'category_status'
          left join category c_c on c_c.parent_category = c.uuid and c_c.status_uuid != (select i_s.uuid from status i_s where i_s.type = 'category_status' and i_s.internal_value = 'unactive')
@@ -28,7 +34,8 @@ group by c.name, c.uuid, c.point_amount, c.comment, s.display_value
 limit $1 offset $2;
 
 -- name: ListParentCategories :many
-select c.uuid, c.name, c.point_amount, c.comment, s.display_value, count(c_c.uuid) as sub_amount
+select c.uuid, c.name, c.point_amount, c.comment, s.display_value, count(c_c.uuid) as sub_amount,
+       c.max_points, c.max_achievements, c.multiplier, c.diminishing_factor
 from category c
          join status s on c.status_uuid = s.uuid and type = 'category_status'
          left join category c_c on c_c.parent_category = c.uuid and c_c.status_uuid != (select i_s.uuid from status i_s where i_s.type = 'category_status' and i_s.internal_value = 'unactive')
@@ -63,10 +70,14 @@ where c.uuid = $1;
 
 -- name: UpdateCategory :exec
 update category
-set name         = $1,
-    point_amount = $2,
-    status_uuid  = (select s.uuid from status s where s.display_value = $3 and s.type = 'category_status')
-where category.uuid = $4;
+set name               = sqlc.arg(name),
+    point_amount       = sqlc.arg(point_amount),
+    status_uuid        = (select s.uuid from status s where s.display_value = sqlc.arg(display_value) and s.type = 'category_status'),
+    max_points         = sqlc.narg(max_points),
+    max_achievements   = sqlc.narg(max_achievements),
+    multiplier         = coalesce(sqlc.narg(multiplier)::numeric, 1),
+    diminishing_factor = coalesce(sqlc.narg(diminishing_factor)::numeric, 1)
+where category.uuid = sqlc.arg(uuid);
 
 -- name: GetCategoryByAchievement :many
 select c.*, s.display_value as status_value
@@ -0,0 +1,55 @@
+-- name: CreateOrgUnit :one
+insert into org_unit (name, kind, parent_uuid, winner_quota)
+values (sqlc.arg(name), sqlc.arg(kind), sqlc.narg(parent_uuid), sqlc.narg(winner_quota))
+returning uuid;
+
+-- name: GetOrgUnitByUUID :one
+select u.*,
+       (select count(*) from sys_user su where su.org_unit_uuid = u.uuid) as user_amount
+from org_unit u
+where u.uuid = $1;
+
+-- name: ListOrgUnits :many
+select u.*,
+       (select count(*) from sys_user su where su.org_unit_uuid = u.uuid) as user_amount
+from org_unit u
+order by u.name, u.uuid;
+
+-- name: UpdateOrgUnit :exec
+update org_unit
+set name         = sqlc.arg(name),
+    kind         = sqlc.arg(kind),
+    parent_uuid  = sqlc.narg(parent_uuid),
+    winner_quota = sqlc.narg(winner_quota)
+where uuid = sqlc.arg(uuid);
+
+-- name: DeleteOrgUnit :execrows
+delete
+from org_unit
+where uuid = $1;
+
+-- name: GetOrgUnitSubtree :many
+with recursive subtree as (select u.uuid
+                           from org_unit u
+                           where u.uuid = $1
+                           union
+                           select child.uuid
+                           from org_unit child
+                                    join subtree on child.parent_uuid = subtree.uuid)
+select uuid
+from subtree;
+
+-- name: HasOrgUnitDependents :one
+select (exists(select 1 from org_unit where parent_uuid = sqlc.arg(uuid)::uuid)
+    or exists(select 1 from sys_user where org_unit_uuid = sqlc.arg(uuid)::uuid))::bool as in_use;
+
+-- name: AssignUsersToOrgUnit :execrows
+update sys_user
+set org_unit_uuid = sqlc.narg(org_unit_uuid)
+where uuid = any (sqlc.arg(user_uuids)::uuid[]);
+
+-- name: UnassignUsersFromOrgUnit :execrows
+update sys_user
+set org_unit_uuid = null
+where org_unit_uuid = sqlc.arg(org_unit_uuid)
+  and uuid = any (sqlc.arg(user_uuids)::uuid[]);
@@ -0,0 +1,92 @@
+-- name: CreatePeriod :one
+insert into period (name, start_date, end_date, submission_deadline, status, winner_count)
+values ($1, $2, $3, $4, $5, $6)
+returning uuid;
+
+-- name: GetPeriodByUUID :one
+select *
+from period
+where uuid = $1;
+
+-- name: ListPeriods :many
+select p.*,
+       count(*) over () as total_records
+from period p
+where (sqlc.narg(status)::varchar is null or p.status = sqlc.narg(status))
+order by p.start_date desc
+limit sqlc.arg(row_limit) offset sqlc.arg(row_offset);
+
+-- name: UpdatePeriod :exec
+update period
+set name                = $2,
+    start_date          = $3,
+    end_date            = $4,
+    submission_deadline = $5,
+    status              = $6,
+    winner_count        = $7
+where uuid = $1;
+
+-- name: HasPeriodSnapshots :one
+select exists(select 1 from rating_snapshot where period_uuid = $1)::bool as has_snapshots;
+
+-- name: DeletePeriod :execrows
+delete
+from period
+where uuid = $1;
+
+-- name: GetOverlappingPeriods :many
+select *
+from period
+where start_date <= sqlc.arg(end_date)
+  and end_date >= sqlc.arg(start_date)
+  and (sqlc.narg(exclude_uuid)::uuid is null or uuid != sqlc.narg(exclude_uuid));
+
+-- name: GetPeriodByDate :one
+select *
+from period
+where sqlc.arg(date)::date between start_date and end_date
+order by start_date desc
+limit 1;
+
+-- name: GetAchievementPeriod :one
+select p.*
+from period p
+         join achievement a on coalesce(a.achievement_date, a.created_at::date) between p.start_date and p.end_date
+where a.uuid = $1
+order by p.start_date desc
+limit 1;
+
+-- name: GetPeriodByUUIDForUpdate :one
+select *
+from period
+where uuid = $1
+    for update;
+
+-- name: UpdatePeriodStatus :exec
+update period
+set status = $2
+where uuid = $1;
+
+-- name: GetPeriodAchievementPoints :many
+select a.uuid                          as achievement_uuid,
+       u.uuid                          as user_uuid,
+       u.name                          as user_name,
+       u.second_name                   as user_second_name,
+       u.patronymic                    as user_patronymic,
+       u.gradebook_number,
+       u_s.internal_value              as user_status,
+       u.org_unit_uuid                 as user_org_unit_uuid,
+       c.uuid                          as category_uuid,
+       c.name                          as category_name,
+       a.created_at                    as submitted_at
+from achievement a
+         join status a_s on a_s.uuid = a.status_uuid and a_s.type = 'achievement_status'
+         join sys_user u on u.uuid = a.user_uuid
+         join status u_s on u_s.uuid = u.status_uuid
+         join achievement_category ac on ac.achievement_uuid = a.uuid
+         join category c on c.uuid = ac.category_uuid and c.parent_category is null
+         join status c_s on c_s.uuid = c.status_uuid and c_s.internal_value = 'active'
+where a_s.internal_value = any (sqlc.arg(statuses)::varchar[])
+  and (sqlc.narg(start_date)::date is null or coalesce(a.achievement_date, a.created_at::date) >= sqlc.narg(start_date))
+  and (sqlc.narg(end_date)::date is null or coalesce(a.achievement_date, a.created_at::date) <= sqlc.narg(end_date))
+order by u.uuid, a.uuid;
@@ -0,0 +1,78 @@
+-- name: CreateRatingSnapshot :one
+insert into rating_snapshot (period_uuid, reason, created_by, rule_version)
+values ($1, $2, $3, $4)
+returning uuid, created_at;
+
+-- name: CreateRatingSnapshotEntry :exec
+insert into rating_snapshot_entry (snapshot_uuid, user_uuid, position, point_amount, achievement_amount, is_winner,
+                                   full_name, gradebook_number)
+values ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: CreateRatingSnapshotAchievement :exec
+insert into rating_snapshot_achievement (snapshot_uuid, achievement_uuid, user_uuid, category_uuid, category_name,
+                                         point_amount)
+values ($1, $2, $3, $4, $5, $6);
+
+-- name: GetRatingRuleVersion :one
+-- The rule version changes whenever a scoring relevant category or value changes or the winners are picked differently.
+select md5(coalesce(string_agg(r.rule, ';' order by r.rule), '') || '|' || sqlc.arg(winner_rules)::text)::varchar as rule_version
+from (select c.uuid::text || '=' || c.point_amount::text || ':' || coalesce(c.parent_category::text, '') ||
+             ':' || coalesce(c_s.internal_value, '') || ':' || coalesce(c.max_points::text, '') || ':' ||
+             coalesce(c.max_achievements::text, '') || ':' || c.multiplier::text || ':' ||
+             c.diminishing_factor::text as rule
+      from category c
+               left join status c_s on c_s.uuid = c.status_uuid
+      union all
+      select cv.uuid::text || '=' || cv.point::text || ':' || cv.category_uuid::text || ':' ||
+             coalesce(cv_s.internal_value, '') as rule
+      from category_value cv
+               left join status cv_s on cv_s.uuid = cv.status_uuid) as r;
+
+-- name: ListRatingSnapshots :many
+select s.uuid,
+       s.period_uuid,
+       p.name                                                                         as period_name,
+       s.reason,
+       s.created_by,
+       s.rule_version,
+       s.created_at,
+       (select count(*) from rating_snapshot_entry e where e.snapshot_uuid = s.uuid) as entry_amount,
+       count(*) over ()                                                               as total_records
+from rating_snapshot s
+         left join period p on p.uuid = s.period_uuid
+where (sqlc.narg(period_uuid)::uuid is null or s.period_uuid = sqlc.narg(period_uuid))
+order by s.created_at desc
+limit sqlc.arg(row_limit) offset sqlc.arg(row_offset);
+
+-- name: GetRatingSnapshot :one
+select s.uuid,
+       s.period_uuid,
+       p.name                                                                         as period_name,
+       s.reason,
+       s.created_by,
+       s.rule_version,
+       s.created_at,
+       (select count(*) from rating_snapshot_entry e where e.snapshot_uuid = s.uuid) as entry_amount
+from rating_snapshot s
+         left join period p on p.uuid = s.period_uuid
+where s.uuid = $1;
+
+-- name: GetRatingSnapshotEntries :many
+select *
+from rating_snapshot_entry
+where snapshot_uuid = $1
+order by position;
+
+-- name: GetRatingSnapshotAchievements :many
+select *
+from rating_snapshot_achievement
+where snapshot_uuid = $1
+order by user_uuid, achievement_uuid;
+
+-- name: GetCloseOutRatingSnapshotUUID :one
+select uuid
+from rating_snapshot
+where period_uuid = $1
+  and reason = 'close_out'
+order by created_at desc
+limit 1;
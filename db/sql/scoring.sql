@@ -0,0 +1,38 @@
+-- name: GetAchievementScoreInputs :many
+select a.uuid                                         as achievement_uuid,
+       a.user_uuid,
+       a_s.internal_value                             as achievement_status,
+       coalesce(a.achievement_date, a.created_at::date)::date as achievement_date,
+       a.created_at                                   as submitted_at,
+       c.uuid                                         as category_uuid,
+       c.name                                         as category_name,
+       c.point_amount                                 as category_points,
+       coalesce(c_s.internal_value = 'active', false)::bool as category_active,
+       c.max_points                                   as category_max_points,
+       c.max_achievements                             as category_max_achievements,
+       c.multiplier                                   as category_multiplier,
+       c.diminishing_factor                           as category_diminishing_factor,
+       cv.uuid                                        as value_uuid,
+       cv.name                                        as value_name,
+       cv.category_uuid                               as subcategory_uuid,
+       sc.name                                        as subcategory_name,
+       cv.point                                       as value_points,
+       coalesce(cv_s.internal_value is distinct from 'unactive' and
+                sc_s.internal_value is distinct from 'unactive' and
+                sc.parent_category = c.uuid, false)::bool as value_counted
+from achievement a
+         join status a_s on a_s.uuid = a.status_uuid and a_s.type = 'achievement_status'
+         join achievement_category ac on ac.achievement_uuid = a.uuid
+         join category c on c.uuid = ac.category_uuid and c.parent_category is null
+         left join status c_s on c_s.uuid = c.status_uuid
+         left join achievement_category_value acv on acv.achievement_uuid = a.uuid
+         left join category_value cv on cv.uuid = acv.category_value_uuid
+         left join status cv_s on cv_s.uuid = cv.status_uuid
+         left join category sc on sc.uuid = cv.category_uuid
+         left join status sc_s on sc_s.uuid = sc.status_uuid
+where (sqlc.narg(achievement_uuids)::uuid[] is null or a.uuid = any (sqlc.narg(achievement_uuids)::uuid[]))
+  and (sqlc.narg(user_uuids)::uuid[] is null or a.user_uuid = any (sqlc.narg(user_uuids)::uuid[]))
+  and (sqlc.narg(statuses)::varchar[] is null or a_s.internal_value = any (sqlc.narg(statuses)::varchar[]))
+  and (sqlc.narg(start_date)::date is null or coalesce(a.achievement_date, a.created_at::date) >= sqlc.narg(start_date))
+  and (sqlc.narg(end_date)::date is null or coalesce(a.achievement_date, a.created_at::date) <= sqlc.narg(end_date))
+order by a.uuid, cv.uuid;
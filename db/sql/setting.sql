@@ -0,0 +1,36 @@
+-- name: ListSettings :many
+select name, value, updated_at, updated_by
+from setting
+order by name;
+
+-- name: CreateMissingSetting :exec
+-- Stores the default of a setting that was never stored, so its row can be locked.
+insert into setting (name, value)
+values ($1, $2)
+on conflict (name) do nothing;
+
+-- name: GetSettingValueForUpdate :one
+select value
+from setting
+where name = $1
+    for update;
+
+-- name: UpsertSetting :exec
+insert into setting (name, value, updated_at, updated_by)
+values ($1, $2, now(), $3)
+on conflict (name) do update
+    set value      = excluded.value,
+        updated_at = excluded.updated_at,
+        updated_by = excluded.updated_by;
+
+-- name: CreateSettingChange :exec
+insert into setting_change (name, old_value, new_value, changed_by)
+values ($1, $2, $3, $4);
+
+-- name: ListSettingChanges :many
+select c.*,
+       count(*) over () as total_records
+from setting_change c
+where c.name = sqlc.arg(name)
+order by c.changed_at desc
+limit sqlc.arg(row_limit) offset sqlc.arg(row_offset);
@@ -0,0 +1,49 @@
+-- name: UpsertStudentScores :batchexec
+insert into student_score (user_uuid, point_amount, achievement_amount, achievement_proofed, last_submitted_at, updated_at)
+values ($1, $2, $3, $4, $5, now())
+on conflict (user_uuid) do update set point_amount        = excluded.point_amount,
+                                      achievement_amount  = excluded.achievement_amount,
+                                      achievement_proofed = excluded.achievement_proofed,
+                                      last_submitted_at   = excluded.last_submitted_at,
+                                      updated_at          = excluded.updated_at;
+
+-- name: DeleteStudentScores :exec
+delete
+from student_score;
+
+-- name: GetAchievementUserUUIDs :many
+select distinct a.user_uuid
+from achievement a
+where a.uuid = any (sqlc.arg(achievement_uuids)::uuid[]);
+
+-- name: GetCategoryUserUUIDs :many
+-- Students whose scores depend on the category: achievements filed under it
+-- and achievements with a value of it selected.
+select distinct a.user_uuid
+from achievement a
+where exists(select 1
+             from achievement_category ac
+             where ac.achievement_uuid = a.uuid
+               and ac.category_uuid = sqlc.arg(category_uuid)::uuid)
+   or exists(select 1
+             from achievement_category_value acv
+                      join category_value cv on cv.uuid = acv.category_value_uuid
+             where acv.achievement_uuid = a.uuid
+               and cv.category_uuid = sqlc.arg(category_uuid)::uuid);
+
+-- name: ShareStudentScoresLock :exec
+-- Taken by every refresh, so refreshes run concurrently but not during a rebuild.
+select pg_advisory_xact_lock_shared(hashtext('student_score'));
+
+-- name: LockAllStudentScores :exec
+-- Taken by a rebuild of all scores, it waits for the running refreshes.
+select pg_advisory_xact_lock(hashtext('student_score'));
+
+-- name: LockStudentScores :exec
+-- Serializes score refreshes of the same students until the end of the transaction.
+select pg_advisory_xact_lock(hashtext(u.user_uuid::text))
+from unnest(sqlc.arg(user_uuids)::uuid[]) as u(user_uuid)
+order by u.user_uuid;
+
+-- name: HasStudentScores :one
+select exists(select 1 from student_score)::bool;
@@ -0,0 +1,127 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: student_scores.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteStudentScores = `-- name: DeleteStudentScores :exec
+delete
+from student_score
+`
+
+func (q *Queries) DeleteStudentScores(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteStudentScores)
+	return err
+}
+
+const getAchievementUserUUIDs = `-- name: GetAchievementUserUUIDs :many
+select distinct a.user_uuid
+from achievement a
+where a.uuid = any ($1::uuid[])
+`
+
+func (q *Queries) GetAchievementUserUUIDs(ctx context.Context, achievementUuids []pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getAchievementUserUUIDs, achievementUuids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var user_uuid pgtype.UUID
+		if err := rows.Scan(&user_uuid); err != nil {
+			return nil, err
+		}
+		items = append(items, user_uuid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCategoryUserUUIDs = `-- name: GetCategoryUserUUIDs :many
+select distinct a.user_uuid
+from achievement a
+where exists(select 1
+             from achievement_category ac
+             where ac.achievement_uuid = a.uuid
+               and ac.category_uuid = $1::uuid)
+   or exists(select 1
+             from achievement_category_value acv
+                      join category_value cv on cv.uuid = acv.category_value_uuid
+             where acv.achievement_uuid = a.uuid
+               and cv.category_uuid = $1::uuid)
+`
+
+// Students whose scores depend on the category: achievements filed under it
+// and achievements with a value of it selected.
+func (q *Queries) GetCategoryUserUUIDs(ctx context.Context, categoryUuid pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getCategoryUserUUIDs, categoryUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var user_uuid pgtype.UUID
+		if err := rows.Scan(&user_uuid); err != nil {
+			return nil, err
+		}
+		items = append(items, user_uuid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasStudentScores = `-- name: HasStudentScores :one
+select exists(select 1 from student_score)::bool
+`
+
+func (q *Queries) HasStudentScores(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, hasStudentScores)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const lockAllStudentScores = `-- name: LockAllStudentScores :exec
+select pg_advisory_xact_lock(hashtext('student_score'))
+`
+
+// Taken by a rebuild of all scores, it waits for the running refreshes.
+func (q *Queries) LockAllStudentScores(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockAllStudentScores)
+	return err
+}
+
+const lockStudentScores = `-- name: LockStudentScores :exec
+select pg_advisory_xact_lock(hashtext(u.user_uuid::text))
+from unnest($1::uuid[]) as u(user_uuid)
+order by u.user_uuid
+`
+
+// Serializes score refreshes of the same students until the end of the transaction.
+func (q *Queries) LockStudentScores(ctx context.Context, userUuids []pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockStudentScores, userUuids)
+	return err
+}
+
+const shareStudentScoresLock = `-- name: ShareStudentScoresLock :exec
+select pg_advisory_xact_lock_shared(hashtext('student_score'))
+`
+
+// Taken by every refresh, so refreshes run concurrently but not during a rebuild.
+func (q *Queries) ShareStudentScoresLock(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, shareStudentScoresLock)
+	return err
+}
@@ -60,7 +60,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (pgtype.
 }
 
 const getApprovedUserByGradeBookNumber = `-- name: GetApprovedUserByGradeBookNumber :one
-select u.uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt, s.uuid, internal_value, display_value, type
+select u.uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt, org_unit_uuid, s.uuid, internal_value, display_value, type
 from sys_user u
          join status s on u.status_uuid = s.uuid and s.type = 'user_status'
 where u.gradebook_number = $1
@@ -79,6 +79,7 @@ type GetApprovedUserByGradeBookNumberRow struct {
 	StatusUuid      pgtype.UUID
 	Password        pgtype.Text
 	Salt            pgtype.Text
+	OrgUnitUuid     pgtype.UUID
 	Uuid_2          pgtype.UUID
 	InternalValue   pgtype.Text
 	DisplayValue    pgtype.Text
@@ -100,6 +101,7 @@ func (q *Queries) GetApprovedUserByGradeBookNumber(ctx context.Context, gradeboo
 		&i.StatusUuid,
 		&i.Password,
 		&i.Salt,
+		&i.OrgUnitUuid,
 		&i.Uuid_2,
 		&i.InternalValue,
 		&i.DisplayValue,
@@ -108,126 +110,8 @@ func (q *Queries) GetApprovedUserByGradeBookNumber(ctx context.Context, gradeboo
 	return i, err
 }
 
-const getShortRatingInfo = `-- name: GetShortRatingInfo :many
-with sub as (select distinct row_number() over ()           as position,
-                             u.uuid,
-                             u.name,
-                             second_name,
-                             patronymic,
-                             gradebook_number,
-                             birth_date,
-                             phone_number,
-                             email,
-                             u_s.display_value              as user_status,
-                             sum(cv.point) + c.point_amount as point_amount,
-                             count(a.uuid)                  as achievement_amount,
-                             count(*) over ()               as total_amount,
-                             c.point_amount                 as category_point_amount,
-                             case
-                                 when max(case when a_s.internal_value = 'unapproved' then 1 else 0 end) = 1
-                                     then false
-                                 else true
-                                 end                        as all_achievement_verified
-             from sys_user u
-                      left join achievement a on a.user_uuid = u.uuid and a.status_uuid in (select s.uuid
-                                                                                            from status s
-                                                                                            where s.type = 'achievement_status'
-                                                                                              and s.internal_value != 'declined')
-                      left join achievement_category ac on ac.achievement_uuid = a.uuid
-                      left join category c
-                                on c.uuid = ac.category_uuid and parent_category is null and
-                                   c.status_uuid in (select s.uuid
-                                                     from status s
-                                                     where s.type = 'category_status'
-                                                       and s.internal_value = 'active')
-                      left join category c_p on c_p.uuid = ac.category_uuid and c_p.parent_category is not null and
-                                                c_p.status_uuid in (select s.uuid
-                                                                    from status s
-                                                                    where s.type = 'category_status'
-                                                                      and s.internal_value = 'active')
-                      left join achievement_category_value acv on acv.achievement_uuid = a.uuid
-                      left join category_value cv on cv.uuid = acv.category_value_uuid and cv.status_uuid != (select s.uuid from status s where type = 'category_value_status' and internal_value = 'unactive')
-                      left join status u_s on u_s.uuid = u.status_uuid and u_s.type = 'user_status'
-                      left join status a_s on a_s.uuid = a.status_uuid and a_s.type = 'achievement_status'
-             where c.point_amount is not null
-             group by u.uuid, u.name, second_name, patronymic, gradebook_number, birth_date, phone_number, email,
-                      c.point_amount, u_s.display_value
-
-             order by c.point_amount)
-select s.position,
-       s.uuid,
-       s.name,
-       s.second_name,
-       s.patronymic,
-       s.gradebook_number,
-       s.birth_date,
-       s.phone_number,
-       s.email,
-       s.user_status,
-       s.point_amount,
-       s.achievement_amount,
-       s.total_amount,
-       s.all_achievement_verified
-from sub s
-where s.uuid = $1
-   or s.position = 1
-order by position
-`
-
-type GetShortRatingInfoRow struct {
-	Position               int64
-	Uuid                   pgtype.UUID
-	Name                   string
-	SecondName             string
-	Patronymic             pgtype.Text
-	GradebookNumber        string
-	BirthDate              pgtype.Date
-	PhoneNumber            pgtype.Text
-	Email                  pgtype.Text
-	UserStatus             pgtype.Text
-	PointAmount            int32
-	AchievementAmount      int64
-	TotalAmount            int64
-	AllAchievementVerified bool
-}
-
-func (q *Queries) GetShortRatingInfo(ctx context.Context, uuid pgtype.UUID) ([]GetShortRatingInfoRow, error) {
-	rows, err := q.db.Query(ctx, getShortRatingInfo, uuid)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetShortRatingInfoRow
-	for rows.Next() {
-		var i GetShortRatingInfoRow
-		if err := rows.Scan(
-			&i.Position,
-			&i.Uuid,
-			&i.Name,
-			&i.SecondName,
-			&i.Patronymic,
-			&i.GradebookNumber,
-			&i.BirthDate,
-			&i.PhoneNumber,
-			&i.Email,
-			&i.UserStatus,
-			&i.PointAmount,
-			&i.AchievementAmount,
-			&i.TotalAmount,
-			&i.AllAchievementVerified,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const getSimpleUserByUUID = `-- name: GetSimpleUserByUUID :one
-select uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt
+select uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt, org_unit_uuid
 from sys_user
 where uuid = $1
 `
@@ -247,12 +131,13 @@ func (q *Queries) GetSimpleUserByUUID(ctx context.Context, uuid pgtype.UUID) (Sy
 		&i.StatusUuid,
 		&i.Password,
 		&i.Salt,
+		&i.OrgUnitUuid,
 	)
 	return i, err
 }
 
 const getSimpleUserList = `-- name: GetSimpleUserList :many
-select uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt
+select uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt, org_unit_uuid
 from sys_user
 `
 
@@ -277,6 +162,7 @@ func (q *Queries) GetSimpleUserList(ctx context.Context) ([]SysUser, error) {
 			&i.StatusUuid,
 			&i.Password,
 			&i.Salt,
+			&i.OrgUnitUuid,
 		); err != nil {
 			return nil, err
 		}
@@ -289,7 +175,7 @@ func (q *Queries) GetSimpleUserList(ctx context.Context) ([]SysUser, error) {
 }
 
 const getSimpleUserListWithPagination = `-- name: GetSimpleUserListWithPagination :many
-select uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt, count(*) over () as total_amount
+select uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt, org_unit_uuid, count(*) over () as total_amount
 from sys_user
 limit $1 offset $2
 `
@@ -311,6 +197,7 @@ type GetSimpleUserListWithPaginationRow struct {
 	StatusUuid      pgtype.UUID
 	Password        pgtype.Text
 	Salt            pgtype.Text
+	OrgUnitUuid     pgtype.UUID
 	TotalAmount     int64
 }
 
@@ -335,6 +222,7 @@ func (q *Queries) GetSimpleUserListWithPagination(ctx context.Context, arg GetSi
 			&i.StatusUuid,
 			&i.Password,
 			&i.Salt,
+			&i.OrgUnitUuid,
 			&i.TotalAmount,
 		); err != nil {
 			return nil, err
@@ -348,7 +236,7 @@ func (q *Queries) GetSimpleUserListWithPagination(ctx context.Context, arg GetSi
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-select uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt
+select uuid, name, second_name, patronymic, gradebook_number, birth_date, email, phone_number, status_uuid, password, salt, org_unit_uuid
 from sys_user
 where email = $1
 `
@@ -368,6 +256,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email pgtype.Text) (SysUse
 		&i.StatusUuid,
 		&i.Password,
 		&i.Salt,
+		&i.OrgUnitUuid,
 	)
 	return i, err
 }
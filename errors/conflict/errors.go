@@ -0,0 +1,14 @@
+package conflict
+
+import (
+	"errors"
+)
+
+var (
+	InvalidTransitionErr = errors.New("Недопустимый переход статуса для текущего состояния записи")
+	AlreadyClaimedErr    = errors.New("Запись уже взята в работу другим пользователем")
+	NotClaimedErr        = errors.New("Запись не взята в работу текущим пользователем")
+	PeriodClosedErr      = errors.New("Прием достижений за этот период закрыт")
+	OrgUnitInUseErr      = errors.New("В подразделении есть вложенные подразделения или студенты")
+	PeriodInUseErr       = errors.New("По периоду уже сохранены снимки рейтинга")
+)
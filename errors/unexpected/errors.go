@@ -5,4 +5,7 @@ import "errors"
 var (
 	RequestErr  = errors.New("Возникла неожиданная ошибка при запросе в базу данных")
 	InternalErr = errors.New("Возникла неожиданная ошибка в процессе работы приложения")
+	// NotConfiguredErr is returned by features the server is not configured
+	// for, like reports without a font.
+	NotConfiguredErr = errors.New("Функция не настроена на сервере")
 )
@@ -0,0 +1,66 @@
+package evidence
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams never change the document a link points to.
+var trackingParams = map[string]struct{}{
+	"fbclid":    {},
+	"gclid":     {},
+	"yclid":     {},
+	"_openstat": {},
+}
+
+// NormalizeLink reduces an evidence URL to a canonical form, so the same
+// document pasted as http/https, with or without www, trailing slash,
+// fragment or tracking parameters is recognised as one. Path and the rest of
+// the query stay case sensitive because file ids of most hostings are.
+func NormalizeLink(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(link)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if _, ok := trackingParams[strings.ToLower(key)]; ok || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := url.Values{}
+	for _, key := range keys {
+		values[key] = query[key]
+	}
+
+	normalized := host + path
+	if encoded := values.Encode(); encoded != "" {
+		normalized += "?" + encoded
+	}
+
+	return normalized
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// utf8BOM makes Excel read the file as UTF-8 instead of the local code page.
+const utf8BOM = "\uFEFF"
+
+type csvWriter struct {
+	w       *bufio.Writer
+	csv     *csv.Writer
+	started bool
+}
+
+// newCSVWriter separates cells with semicolons, the list separator Excel
+// expects in the Russian locale. The BOM and the first rows are buffered
+// together, so nothing is written out before the first rows are known.
+func newCSVWriter(w io.Writer) *csvWriter {
+	buffered := bufio.NewWriter(w)
+
+	writer := csv.NewWriter(buffered)
+	writer.Comma = ';'
+	writer.UseCRLF = true
+
+	return &csvWriter{
+		w:   buffered,
+		csv: writer,
+	}
+}
+
+func (w *csvWriter) WriteHeader(columns ...string) error {
+	return w.write(columns)
+}
+
+func (w *csvWriter) WriteRow(values ...any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case string:
+			record[i] = escapeFormula(v)
+		case int:
+			record[i] = strconv.Itoa(v)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			record[i] = escapeFormula(fmt.Sprint(v))
+		}
+	}
+
+	return w.write(record)
+}
+
+// escapeFormula keeps spreadsheets from evaluating text that students entered,
+// e.g. an achievement name of =HYPERLINK(...). A cell that starts like a
+// formula gets a leading apostrophe and is shown as text. Numbers are written
+// by WriteRow without escaping, so negative points stay numbers.
+func escapeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+
+	return value
+}
+
+func (w *csvWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+
+	return w.w.Flush()
+}
+
+func (w *csvWriter) write(record []string) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	return w.csv.Write(record)
+}
+
+func (w *csvWriter) start() error {
+	if w.started {
+		return nil
+	}
+
+	w.started = true
+	_, err := w.w.WriteString(utf8BOM)
+	return err
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w := newCSVWriter(&buf)
+
+	err := w.WriteRow(
+		"=HYPERLINK(\"http://evil\")",
+		"+7 999",
+		"-1+2",
+		"@SUM(A1)",
+		"\tcmd",
+		"Иванов",
+		"a=b",
+		"",
+		-5,
+		-1.5,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), utf8BOM)))
+	reader.Comma = ';'
+	got, err := reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"'=HYPERLINK(\"http://evil\")",
+		"'+7 999",
+		"'-1+2",
+		"'@SUM(A1)",
+		"'\tcmd",
+		"Иванов",
+		"a=b",
+		"",
+		"-5",
+		"-1.5",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d cells, want %d: %q", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("cell %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := map[string]string{
+		"=1+1":   "'=1+1",
+		"+1":     "'+1",
+		"-1":     "'-1",
+		"@A1":    "'@A1",
+		"\tA1":   "'\tA1",
+		"\rA1":   "'\rA1",
+		"'=1+1":  "'=1+1",
+		"1-1":    "1-1",
+		" =1+1":  " =1+1",
+		"":       "",
+		"Петров": "Петров",
+	}
+
+	for value, want := range tests {
+		if got := escapeFormula(value); got != want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+// TestCSVWriterBuffersStart covers an export that fails before its first rows:
+// nothing, not even the BOM, may be written out yet, so the error can still be
+// reported instead of the file.
+func TestCSVWriterBuffersStart(t *testing.T) {
+	var buf bytes.Buffer
+	w := newCSVWriter(&buf)
+
+	if err := w.WriteHeader("Место", "Фамилия"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(1, "Иванов"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("%q is written before Close", buf.String())
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := utf8BOM + "Место;Фамилия\r\n1;Иванов\r\n"; buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
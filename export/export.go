@@ -0,0 +1,50 @@
+// Package export writes tables as spreadsheets for download. Rows are written
+// as they come, so large tables are not kept in memory.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// TableWriter writes the header and then the rows of one table. Cell values are
+// strings or numbers. Nothing is guaranteed to reach the underlying writer
+// before Close.
+type TableWriter interface {
+	WriteHeader(columns ...string) error
+	WriteRow(values ...any) error
+	Close() error
+}
+
+// NewTableWriter returns the writer of the format. The sheet name is only used
+// by formats that have sheets.
+func NewTableWriter(format string, w io.Writer, sheet string) (TableWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// ContentType returns the media type of the format.
+func ContentType(format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
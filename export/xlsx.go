@@ -0,0 +1,93 @@
+package export
+
+import (
+	"github.com/xuri/excelize/v2"
+	"io"
+)
+
+type xlsxWriter struct {
+	w           io.Writer
+	file        *excelize.File
+	stream      *excelize.StreamWriter
+	headerStyle int
+	row         int
+}
+
+// newXLSXWriter uses the stream writer of excelize, which moves the rows to a
+// temporary file once they no longer fit in memory. The workbook itself can
+// only be written out on Close.
+func newXLSXWriter(w io.Writer, sheet string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+
+	if sheet == "" {
+		sheet = file.GetSheetName(0)
+	} else if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &xlsxWriter{
+		w:           w,
+		file:        file,
+		stream:      stream,
+		headerStyle: headerStyle,
+	}, nil
+}
+
+func (w *xlsxWriter) WriteHeader(columns ...string) error {
+	cells := make([]any, len(columns))
+	for i, column := range columns {
+		cells[i] = excelize.Cell{StyleID: w.headerStyle, Value: column}
+	}
+
+	// The panes must be set before the first row.
+	err := w.stream.SetPanes(&excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.writeRow(cells)
+}
+
+func (w *xlsxWriter) WriteRow(values ...any) error {
+	return w.writeRow(values)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+
+	_, err := w.file.WriteTo(w.w)
+	return err
+}
+
+func (w *xlsxWriter) writeRow(values []any) error {
+	w.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+
+	return w.stream.SetRow(cell, values)
+}
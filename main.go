@@ -2,13 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
 	trmpgx "github.com/avito-tech/go-transaction-manager/pgxv5"
 	"github.com/igntnk/scholarship_point_system/config"
 	"github.com/igntnk/scholarship_point_system/controllers"
 	"github.com/igntnk/scholarship_point_system/jwk"
 	"github.com/igntnk/scholarship_point_system/middleware"
+	"github.com/igntnk/scholarship_point_system/report"
 	"github.com/igntnk/scholarship_point_system/repository"
 	"github.com/igntnk/scholarship_point_system/service"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/igntnk/scholarship_point_system/storage"
 	"github.com/igntnk/scholarship_point_system/web"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
@@ -17,6 +21,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -91,16 +96,109 @@ func main() {
 	)
 	authController := controllers.NewAuthController(authService, m)
 
+	rankingRules := models.RankingRules{
+		Mode:        cfg.Rating.RankMode,
+		TieBreakers: cfg.Rating.TieBreakers,
+	}
+	if err = service.ValidateRankingRules(rankingRules); err != nil {
+		logger.Fatal().Err(err).Msg("invalid rating config")
+		return
+	}
+
+	scoringRepo := repository.NewScoringRepository(conn)
+	scoringService := service.NewScoringService(scoringRepo)
+
+	orgUnitRepo := repository.NewOrgUnitRepository(conn)
+	settingRepo := repository.NewSettingRepository(conn)
+	settingService := service.NewSettingService(settingRepo)
+
+	periodRepo := repository.NewPeriodRepository(conn)
+	periodService := service.NewPeriodService(periodRepo, orgUnitRepo, settingService, scoringService, rankingRules)
+	periodController := controllers.NewPeriodController(periodService, m)
+
 	achievementRepo := repository.NewAchievementRepository(conn)
-	achievementService := service.NewAchievementService(achievementRepo, userRepo)
-	achievementController := controllers.NewAchievementController(achievementService, m)
+	attachmentRepo := repository.NewAttachmentRepository(conn)
+	achievementService := service.NewAchievementService(
+		achievementRepo,
+		userRepo,
+		attachmentRepo,
+		periodRepo,
+		permissionService,
+		scoringService,
+		time.Duration(cfg.Review.ClaimDuration)*time.Second,
+		models.DuplicateCheck{
+			Disabled:          cfg.DuplicateCheck.Disabled,
+			DateWindow:        time.Duration(cfg.DuplicateCheck.DateWindowDays) * 24 * time.Hour,
+			CommentSimilarity: cfg.DuplicateCheck.CommentSimilarity,
+		},
+	)
+	blobStore, err := storage.New(mainCtx, storage.Config{
+		Driver:    cfg.Storage.Driver,
+		LocalPath: cfg.Storage.LocalPath,
+		S3: storage.S3Config{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+			Bucket:    cfg.Storage.S3.Bucket,
+			Region:    cfg.Storage.S3.Region,
+			UseSSL:    cfg.Storage.S3.UseSSL,
+		},
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to init attachment storage")
+		return
+	}
 
-	ratingService := service.NewRatingService(userRepo)
+	attachmentService := service.NewAttachmentService(
+		attachmentRepo,
+		achievementRepo,
+		permissionService,
+		blobStore,
+		models.AttachmentLimits{
+			MaxFileSize:         cfg.Storage.MaxFileSize,
+			MaxAttachments:      cfg.Storage.MaxAttachments,
+			AllowedContentTypes: cfg.Storage.AllowedContentTypes,
+		},
+	)
+	maxUploadSize := cfg.Storage.MaxFileSize * int64(cfg.Storage.MaxAttachments)
+	achievementController := controllers.NewAchievementController(achievementService, attachmentService, m, maxUploadSize)
+	attachmentController := controllers.NewAttachmentController(attachmentService, m, maxUploadSize)
+
+	orgUnitService := service.NewOrgUnitService(orgUnitRepo)
+	orgUnitController := controllers.NewOrgUnitController(orgUnitService, m)
+
+	settingController := controllers.NewSettingController(m, settingService)
+
+	ratingSnapshotRepo := repository.NewRatingSnapshotRepository(conn)
+	reportRenderer, err := report.NewPDFRenderer(report.Config{
+		FontPath:     cfg.Report.FontPath,
+		BoldFontPath: cfg.Report.BoldFontPath,
+		HeaderText:   cfg.Report.HeaderText,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to load report fonts")
+		return
+	}
+	if cfg.Report.FontPath == "" {
+		logger.Warn().Msg("report font is not configured, winners reports are unavailable")
+	}
+
+	ratingService := service.NewRatingService(
+		userRepo,
+		periodRepo,
+		ratingSnapshotRepo,
+		orgUnitRepo,
+		categoryRepo,
+		settingService,
+		scoringService,
+		reportRenderer,
+		rankingRules,
+	)
 	ratingController := controllers.NewRatingController(m, ratingService)
 
-	constantRepo := repository.NewConstantRepository(conn)
-	constantService := service.NewConstantService(constantRepo)
-	constantController := controllers.NewConstantController(m, constantService)
+	auditRepo := repository.NewAuditRepository(conn)
+	auditService := service.NewAuditService(auditRepo)
+	auditController := controllers.NewAuditController(auditService, m)
 
 	httpServer, err := web.New(
 		logger,
@@ -111,35 +209,75 @@ func main() {
 		userController,
 		authController,
 		achievementController,
+		attachmentController,
 		ratingController,
-		constantController,
+		settingController,
+		auditController,
+		periodController,
+		orgUnitController,
 	)
 	if err != nil {
 		logger.Fatal().Err(err).Send()
 		return
 	}
 
-	adminUUID, err := authService.ActualizeAdmin(mainCtx, cfg.Secure.AdminEmail, cfg.Secure.AdminPassword)
+	err = permissionService.ActualizeAdminGroupAndRole(
+		mainCtx,
+		cfg.Secure.AdminGroupName,
+		cfg.Secure.AdminRoleName,
+	)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to reload admin data")
+		logger.Fatal().Err(err).Msg("failed to reload active admin group")
 		return
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "admin":
+			err = runAdminCommand(mainCtx, logger, os.Args[2:], cfg, authService, permissionService)
+		case "scores":
+			err = runScoresCommand(mainCtx, os.Args[2:], scoringService)
+		default:
+			err = fmt.Errorf("unknown command %q", os.Args[1])
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msg("command failed")
+		}
+		return
+	}
+
+	if cfg.Secure.BootstrapAdmin {
+		err = bootstrapAdmin(mainCtx, logger, cfg, authService, permissionService)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to bootstrap admin")
+			return
+		}
+	}
+
 	err = permissionService.ActualizeResources(mainCtx, httpServer.GetRoutes())
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to reload active resources")
 		return
 	}
 
-	err = permissionService.ActualizeAdminGroupAndRole(
-		mainCtx,
-		adminUUID,
-		cfg.Secure.AdminGroupName,
-		cfg.Secure.AdminRoleName,
-	)
+	fingerprinted, err := attachmentService.BackfillEvidenceFingerprints(mainCtx)
+	for _, skipped := range fingerprinted.Skipped {
+		logger.Warn().Err(skipped.Err).
+			Str("attachment", skipped.Attachment.UUID).
+			Str("key", skipped.Attachment.StorageKey).
+			Msg("skipped fingerprinting unreadable achievement evidence")
+	}
 	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to reload active admin group")
-		return
+		logger.Warn().Err(err).Msg("failed to fingerprint old achievement evidence")
+	} else if fingerprinted.Updated > 0 {
+		logger.Info().Int("count", fingerprinted.Updated).Msg("achievement evidence fingerprinted")
+	}
+
+	scored, err := scoringService.BackfillStudentScores(mainCtx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to fill student scores")
+	} else if scored > 0 {
+		logger.Info().Int("count", scored).Msg("student scores filled")
 	}
 
 	serverErrorChan := make(chan error, 1)
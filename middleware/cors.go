@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/igntnk/scholarship_point_system/audit"
 	"github.com/igntnk/scholarship_point_system/config"
 	"time"
 )
@@ -10,8 +11,8 @@ import (
 func NewCORS(cfg config.CorsConfig) gin.HandlerFunc {
 	c := cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", audit.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", audit.RequestIDHeader},
 		AllowCredentials: cfg.AllowCredentials,
 		MaxAge:           12 * time.Hour,
 	}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/igntnk/scholarship_point_system/audit"
+)
+
+func NewRequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(audit.RequestIDHeader)
+		if requestID == "" || len(requestID) > 100 {
+			requestID = uuid.NewString()
+		}
+
+		c.Header(audit.RequestIDHeader, requestID)
+		audit.WithRequest(c, requestID, c.ClientIP())
+		c.Next()
+	}
+}
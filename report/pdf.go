@@ -0,0 +1,293 @@
+package report
+
+import (
+	"fmt"
+	"github.com/go-pdf/fpdf"
+	"github.com/igntnk/scholarship_point_system/scoring"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	fontFamily = "report"
+
+	pageMargin = 15.0
+	fontSize   = 10.0
+	lineHeight = 5.0
+	cellMargin = 1.5
+)
+
+type pdfRenderer struct {
+	cfg  Config
+	font []byte
+	bold []byte
+}
+
+// NewPDFRenderer reads the fonts once, so a wrong path fails on start and not
+// on the first report. Without a font the renderer is created but every report
+// fails with ErrFontNotConfigured.
+func NewPDFRenderer(cfg Config) (Renderer, error) {
+	r := &pdfRenderer{cfg: cfg}
+	if cfg.FontPath == "" {
+		return r, nil
+	}
+
+	var err error
+	if r.font, err = os.ReadFile(cfg.FontPath); err != nil {
+		return nil, err
+	}
+
+	r.bold = r.font
+	if cfg.BoldFontPath != "" {
+		if r.bold, err = os.ReadFile(cfg.BoldFontPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+type column struct {
+	title string
+	width float64
+	align string
+}
+
+// RenderWinners writes the list of winners to sign with their points by
+// category, followed by the achievements of every winner if asked for.
+func (r *pdfRenderer) RenderWinners(w io.Writer, list models.WinnersList) error {
+	pdf, err := r.newDocument()
+	if err != nil {
+		return err
+	}
+
+	pdf.AddPage()
+
+	header := list.Header
+	if header == "" {
+		header = r.cfg.HeaderText
+	}
+	pdf.SetFont(fontFamily, "B", fontSize+2)
+	for _, line := range strings.Split(header, "\n") {
+		pdf.MultiCell(0, lineHeight+1, strings.TrimSpace(line), "", "C", false)
+	}
+	pdf.Ln(2)
+
+	pdf.SetFont(fontFamily, "", fontSize)
+	if list.PeriodName != "" {
+		pdf.MultiCell(0, lineHeight, fmt.Sprintf("Период: %s (%s — %s)", list.PeriodName,
+			list.StartDate.Format("02.01.2006"), list.EndDate.Format("02.01.2006")), "", "L", false)
+	}
+	pdf.MultiCell(0, lineHeight, fmt.Sprintf("Количество мест: %d", list.WinnerCount), "", "L", false)
+	pdf.MultiCell(0, lineHeight, "Дата формирования: "+list.GeneratedAt.Format("02.01.2006 15:04"), "", "L", false)
+	pdf.Ln(3)
+
+	columns := []column{
+		{title: "Место", width: 14, align: "C"},
+		{title: "ФИО", width: 55, align: "L"},
+		{title: "Зачётная книжка", width: 27, align: "C"},
+		{title: "Баллы", width: 18, align: "R"},
+		{title: "Баллы по категориям", width: 66, align: "L"},
+	}
+
+	if len(list.Winners) == 0 {
+		pdf.MultiCell(0, lineHeight, "Победителей нет.", "", "L", false)
+	} else {
+		r.tableHeader(pdf, columns)
+		for _, winner := range list.Winners {
+			r.tableRow(pdf, columns, []string{
+				strconv.Itoa(winner.Position),
+				winner.FullName,
+				winner.GradebookNumber,
+				formatPoints(winner.PointAmount),
+				categoryBreakdown(winner.Achievements),
+			})
+		}
+	}
+
+	r.signatures(pdf)
+
+	if list.Appendix && len(list.Winners) != 0 {
+		r.appendix(pdf, list.Winners)
+	}
+
+	if err = pdf.Error(); err != nil {
+		return err
+	}
+
+	return pdf.Output(w)
+}
+
+func (r *pdfRenderer) newDocument() (*fpdf.Fpdf, error) {
+	if r.font == nil {
+		return nil, ErrFontNotConfigured
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", r.font)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", r.bold)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pageMargin + 3)
+		pdf.SetFont(fontFamily, "", fontSize-2)
+		pdf.CellFormat(0, lineHeight, fmt.Sprintf("Страница %d из {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	return pdf, pdf.Error()
+}
+
+func (r *pdfRenderer) tableHeader(pdf *fpdf.Fpdf, columns []column) {
+	titles := make([]string, len(columns))
+	for i, col := range columns {
+		titles[i] = col.title
+	}
+
+	pdf.SetFont(fontFamily, "B", fontSize)
+	pdf.SetFillColor(230, 230, 230)
+	r.row(pdf, columns, titles, true)
+	pdf.SetFont(fontFamily, "", fontSize)
+}
+
+// tableRow starts a new page with the table header when the row does not fit,
+// rows are never split between pages.
+func (r *pdfRenderer) tableRow(pdf *fpdf.Fpdf, columns []column, values []string) {
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+r.rowHeight(pdf, columns, values) > pageHeight-pageMargin {
+		pdf.AddPage()
+		r.tableHeader(pdf, columns)
+	}
+
+	r.row(pdf, columns, values, false)
+}
+
+func (r *pdfRenderer) row(pdf *fpdf.Fpdf, columns []column, values []string, fill bool) {
+	height := r.rowHeight(pdf, columns, values)
+	x, y := pdf.GetXY()
+
+	// The page break is handled by tableRow, an automatic one would move
+	// every cell of the row to its own page.
+	pdf.SetAutoPageBreak(false, pageMargin)
+	defer pdf.SetAutoPageBreak(true, pageMargin)
+
+	style := "D"
+	if fill {
+		style = "FD"
+	}
+
+	for i, col := range columns {
+		pdf.Rect(x, y, col.width, height, style)
+		pdf.SetXY(x+cellMargin, y+cellMargin/2)
+		pdf.MultiCell(col.width-2*cellMargin, lineHeight, values[i], "", col.align, false)
+		x += col.width
+	}
+
+	pdf.SetXY(pageMargin, y+height)
+}
+
+func (r *pdfRenderer) rowHeight(pdf *fpdf.Fpdf, columns []column, values []string) float64 {
+	lines := 1
+	for i, col := range columns {
+		lines = max(lines, len(pdf.SplitText(values[i], col.width-2*cellMargin)))
+	}
+
+	return float64(lines)*lineHeight + cellMargin
+}
+
+func (r *pdfRenderer) signatures(pdf *fpdf.Fpdf) {
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+40 > pageHeight-pageMargin {
+		pdf.AddPage()
+	}
+
+	pdf.Ln(12)
+	for _, role := range []string{"Председатель комиссии", "Секретарь комиссии"} {
+		pdf.CellFormat(60, lineHeight, role, "", 0, "L", false, 0, "")
+		pdf.CellFormat(50, lineHeight, "____________________", "", 0, "C", false, 0, "")
+		pdf.CellFormat(0, lineHeight, "/ ____________________ /", "", 1, "L", false, 0, "")
+		pdf.Ln(8)
+	}
+}
+
+func (r *pdfRenderer) appendix(pdf *fpdf.Fpdf, winners []models.RatingSnapshotEntry) {
+	columns := []column{
+		{title: "Дата", width: 22, align: "C"},
+		{title: "Категория", width: 50, align: "L"},
+		{title: "Значения", width: 90, align: "L"},
+		{title: "Баллы", width: 18, align: "R"},
+	}
+
+	pdf.AddPage()
+	pdf.SetFont(fontFamily, "B", fontSize+2)
+	pdf.MultiCell(0, lineHeight+1, "Приложение. Достижения победителей", "", "C", false)
+	pdf.Ln(2)
+
+	for _, winner := range winners {
+		achievements := slices.Clone(winner.Achievements)
+		slices.SortStableFunc(achievements, func(a, b models.AchievementPoints) int {
+			return a.ScoreInput.AchievementDate.Compare(b.ScoreInput.AchievementDate)
+		})
+
+		_, pageHeight := pdf.GetPageSize()
+		if pdf.GetY()+4*lineHeight > pageHeight-pageMargin {
+			pdf.AddPage()
+		}
+
+		pdf.Ln(2)
+		pdf.SetFont(fontFamily, "B", fontSize)
+		pdf.MultiCell(0, lineHeight, fmt.Sprintf("%d. %s, зачётная книжка %s, баллов: %s", winner.Position,
+			winner.FullName, winner.GradebookNumber, formatPoints(winner.PointAmount)), "", "L", false)
+		pdf.Ln(1)
+
+		r.tableHeader(pdf, columns)
+		for _, achievement := range achievements {
+			r.tableRow(pdf, columns, []string{
+				achievement.ScoreInput.AchievementDate.Format("02.01.2006"),
+				achievement.CategoryName,
+				achievementValues(achievement.ScoreInput.Values),
+				formatPoints(achievement.PointAmount),
+			})
+		}
+	}
+}
+
+// categoryBreakdown sums the points of the achievements by category, one
+// category per line.
+func categoryBreakdown(achievements []models.AchievementPoints) string {
+	names := make([]string, 0)
+	points := map[string]float64{}
+	for _, achievement := range achievements {
+		if _, ok := points[achievement.CategoryName]; !ok {
+			names = append(names, achievement.CategoryName)
+		}
+		points[achievement.CategoryName] = scoring.Round(points[achievement.CategoryName] + achievement.PointAmount)
+	}
+	slices.Sort(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = fmt.Sprintf("%s: %s", name, formatPoints(points[name]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func achievementValues(values []models.ScoreValueInput) string {
+	lines := make([]string, 0, len(values))
+	for _, value := range values {
+		if value.Counted {
+			lines = append(lines, fmt.Sprintf("%s: %s", value.SubcategoryName, value.ValueName))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
@@ -0,0 +1,26 @@
+// Package report renders the official documents of the scholarship committee.
+package report
+
+import (
+	"errors"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"io"
+)
+
+var ErrFontNotConfigured = errors.New("report font is not configured")
+
+type Config struct {
+	// FontPath is a TrueType font with Cyrillic glyphs. The core PDF fonts
+	// only cover Latin, so reports can not be rendered without it.
+	FontPath string
+	// BoldFontPath is optional, the regular font is used for headings without
+	// it.
+	BoldFontPath string
+	// HeaderText is printed at the top of a report unless the report has its
+	// own. Lines are separated with a line break.
+	HeaderText string
+}
+
+type Renderer interface {
+	RenderWinners(w io.Writer, list models.WinnersList) error
+}
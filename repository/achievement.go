@@ -3,15 +3,19 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/avito-tech/go-transaction-manager/pgxv5"
 	"github.com/igntnk/scholarship_point_system/controllers/requests"
 	"github.com/igntnk/scholarship_point_system/controllers/responses"
 	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
 	"github.com/igntnk/scholarship_point_system/errors/parsing"
 	"github.com/igntnk/scholarship_point_system/errors/unexpected"
 	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/evidence"
 	"github.com/igntnk/scholarship_point_system/service/models"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"time"
 )
 
@@ -21,14 +25,23 @@ type AchievementRepository interface {
 	GetAchievementCategories(ctx context.Context, uuid string) ([]models.Category, error)
 	GetUserAchievements(ctx context.Context, uuid string) ([]models.SimpleAchievement, error)
 	GetUserAchievementsWithPagination(ctx context.Context, uuid string, limit, offset int) ([]models.SimpleAchievement, int, error)
-	MakeAchievementUnapproved(ctx context.Context, uuid string) error
-	MakeAchievementApproved(ctx context.Context, uuid string) error
+	GetAchievementReviews(ctx context.Context, uuid string) ([]models.AchievementReview, error)
+	GetAchievementQueue(ctx context.Context, filter models.AchievementQueueFilter, limit, offset int) ([]models.QueueAchievement, int, error)
+	CountAchievementQueueByStatus(ctx context.Context, filter models.AchievementQueueFilter) ([]models.AchievementStatusCount, error)
+	ClaimAchievement(ctx context.Context, uuid, reviewerUUID string, lease time.Duration) error
+	GetActiveAchievementClaim(ctx context.Context, uuid string) (models.AchievementClaim, error)
+	ReleaseAchievementClaim(ctx context.Context, uuid, reviewerUUID string) error
+	GetDuplicateLinkEvidence(ctx context.Context, uuid string) ([]models.DuplicateEvidence, error)
+	FillMissingLinkFingerprints(ctx context.Context) (int, error)
+	MakeAchievementUnapproved(ctx context.Context, uuid, from string, review models.AchievementReview, event models.AuditEvent) error
+	MakeAchievementApproved(ctx context.Context, uuid, from string, review models.AchievementReview, event models.AuditEvent) error
 	MakeAchievementUsed(ctx context.Context, uuid string) error
-	MakeAchievementDeclined(ctx context.Context, uuid string) error
-	MakeAchievementRemoved(ctx context.Context, uuid string) error
-	CreateAchievement(ctx context.Context, userUUID string, achievement requests.UpsertAchievement) (string, error)
+	MakeAchievementDeclined(ctx context.Context, uuid, from string, review models.AchievementReview, event models.AuditEvent) error
+	MakeAchievementRemoved(ctx context.Context, uuid, from string, review models.AchievementReview, event models.AuditEvent) error
+	CreateAchievement(ctx context.Context, userUUID string, achievement requests.UpsertAchievement, possibleDuplicateOf string) (string, error)
+	GetDuplicateCandidates(ctx context.Context, userUUID, categoryUUID string) ([]models.DuplicateCandidate, error)
 	UpdateAchievementDescFields(ctx context.Context, achievement models.SimpleAchievement) error
-	UpdateAchievementFull(ctx context.Context, achievement requests.UpsertAchievement) error
+	UpdateAchievementFull(ctx context.Context, achievement requests.UpsertAchievement, from string, review models.AchievementReview) error
 }
 
 type achievementRepository struct {
@@ -57,18 +70,15 @@ func (r *achievementRepository) GetSimpleUserAchievementByUUID(ctx context.Conte
 		return models.SimpleAchievement{}, errors.Join(err, unexpected.RequestErr)
 	}
 
-	pointAmountFloat, err := dbAchievement.PointAmount.Float64Value()
-	if err != nil {
-		return models.SimpleAchievement{}, errors.Join(err, unexpected.InternalErr)
-	}
-
 	return models.SimpleAchievement{
 		UUID:           dbAchievement.Uuid.String(),
 		AttachmentLink: dbAchievement.AttachmentLink,
 		Status:         dbAchievement.Status.String,
+		StatusCode:     dbAchievement.StatusCode.String,
+		UserUUID:       dbAchievement.UserUuid.String(),
 		CategoryName:   dbAchievement.CategoryName.String,
-		PointAmount:    float32(pointAmountFloat.Float64),
 		CategoryUUID:   dbAchievement.CategoryUuid.String(),
+		Comment:        dbAchievement.Comment.String,
 	}, nil
 }
 
@@ -126,24 +136,19 @@ func (r *achievementRepository) GetAchievementByUUID(ctx context.Context, uuid s
 		return responses.FullAchievement{}, errors.Join(err, unexpected.InternalErr)
 	}
 
-	pointAmountFloat, err := dbAchievement.PointAmount.Float64Value()
-	if err != nil {
-		return responses.FullAchievement{}, errors.Join(err, unexpected.InternalErr)
-	}
-
 	return responses.FullAchievement{
 		UUID:           dbAchievement.Uuid.String(),
 		Comment:        dbAchievement.Comment.String,
 		AttachmentLink: dbAchievement.AttachmentLink,
 		Status:         dbAchievement.Status.String,
-		PointAmount:    float32(pointAmountFloat.Float64),
 		Category: responses.Category{
 			UUID:   dbAchievement.CategoryUuid.String(),
 			Name:   dbAchievement.CategoryName.String,
 			Points: float32(catPointFL.Float64),
 		},
-		AchievementDate: dbAchievement.AchievementDate.Time.Format(time.RFC3339),
-		Subcategories:   subCatArr,
+		AchievementDate:     dbAchievement.AchievementDate.Time.Format(time.RFC3339),
+		Subcategories:       subCatArr,
+		PossibleDuplicateOf: formatPgUUID(dbAchievement.PossibleDuplicateOf),
 	}, nil
 }
 
@@ -195,11 +200,6 @@ func (r *achievementRepository) GetUserAchievements(ctx context.Context, uuid st
 
 	modelAchievement := make([]models.SimpleAchievement, len(dbAchievements))
 	for i, dbAchievement := range dbAchievements {
-
-		pointAmount, err := dbAchievement.PointAmount.Float64Value()
-		if err != nil {
-			return []models.SimpleAchievement{}, errors.Join(err, unexpected.InternalErr)
-		}
 		modelAchievement[i] = models.SimpleAchievement{
 			UUID:           dbAchievement.Uuid.String(),
 			AttachmentLink: dbAchievement.AttachmentLink,
@@ -207,7 +207,6 @@ func (r *achievementRepository) GetUserAchievements(ctx context.Context, uuid st
 			Comment:        dbAchievement.Comment.String,
 			CategoryName:   dbAchievement.CategoryName.String,
 			CategoryUUID:   dbAchievement.CategoryUuid.String(),
-			PointAmount:    float32(pointAmount.Float64),
 		}
 	}
 	return modelAchievement, nil
@@ -237,11 +236,6 @@ func (r *achievementRepository) GetUserAchievementsWithPagination(ctx context.Co
 	for i, dbAchievement := range dbAchievements {
 		totalRecords = int(dbAchievement.TotalRecords)
 
-		pointAmount, err := dbAchievement.PointAmount.Float64Value()
-		if err != nil {
-			return []models.SimpleAchievement{}, totalRecords, errors.Join(err, unexpected.InternalErr)
-		}
-
 		modelAchievements[i] = models.SimpleAchievement{
 			UUID:           dbAchievement.Uuid.String(),
 			AttachmentLink: dbAchievement.AttachmentLink,
@@ -249,93 +243,453 @@ func (r *achievementRepository) GetUserAchievementsWithPagination(ctx context.Co
 			Comment:        dbAchievement.Comment.String,
 			CategoryName:   dbAchievement.CategoryName.String,
 			CategoryUUID:   dbAchievement.CategoryUuid.String(),
-			PointAmount:    float32(pointAmount.Float64),
 		}
 	}
 	return modelAchievements, totalRecords, nil
 }
 
-func (r *achievementRepository) MakeAchievementUnapproved(ctx context.Context, uuid string) error {
+func (r *achievementRepository) GetAchievementReviews(ctx context.Context, uuid string) ([]models.AchievementReview, error) {
 	pgUUID, err := ParseToPgUUID(uuid)
 	if err != nil {
-		return errors.Join(err, validation.WrongInputErr)
+		return nil, errors.Join(err, validation.WrongInputErr)
 	}
-	err = r.queries.MakeAchievementUnapproved(ctx, pgUUID)
+
+	dbReviews, err := r.queries.GetAchievementReviews(ctx, pgUUID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return validation.NoDataFoundErr
+			return []models.AchievementReview{}, nil
 		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
 
-		return errors.Join(err, unexpected.RequestErr)
+	reviews := make([]models.AchievementReview, len(dbReviews))
+	for i, dbReview := range dbReviews {
+		reviews[i] = models.AchievementReview{
+			UUID:               dbReview.Uuid.String(),
+			ReviewerName:       dbReview.ReviewerName.String,
+			ReviewerSecondName: dbReview.ReviewerSecondName.String,
+			ReviewerPatronymic: dbReview.ReviewerPatronymic.String,
+			FromStatus:         dbReview.FromStatus.String,
+			ToStatus:           dbReview.ToStatus.String,
+			ToStatusCode:       dbReview.ToStatusCode.String,
+			Comment:            dbReview.Comment.String,
+			CreatedAt:          dbReview.CreatedAt.Time.Format(time.RFC3339),
+		}
+		if dbReview.ReviewerUuid.Valid {
+			reviews[i].ReviewerUUID = dbReview.ReviewerUuid.String()
+		}
 	}
-	return nil
+
+	return reviews, nil
 }
 
-func (r *achievementRepository) MakeAchievementApproved(ctx context.Context, uuid string) error {
+func (r *achievementRepository) GetAchievementQueue(ctx context.Context, filter models.AchievementQueueFilter, limit, offset int) ([]models.QueueAchievement, int, error) {
+	args := db.GetAchievementQueueParams{
+		RowLimit:  int32(limit),
+		RowOffset: int32(offset),
+	}
+
+	var err error
+	if args.Status, err = ParseToPgText(filter.Status); err != nil {
+		return nil, 0, errors.Join(err, parsing.InputDataErr)
+	}
+
+	args.CategoryUuid, args.CreatedFrom, args.CreatedTo, args.GroupUuid, err = parseAchievementQueueFilter(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dbAchievements, err := r.queries.GetAchievementQueue(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.QueueAchievement{}, 0, nil
+		}
+		return nil, 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	achievements := make([]models.QueueAchievement, len(dbAchievements))
+	totalRecords := 0
+	for i, dbAchievement := range dbAchievements {
+		totalRecords = int(dbAchievement.TotalRecords)
+
+		achievements[i] = models.QueueAchievement{
+			UUID:           dbAchievement.Uuid.String(),
+			Comment:        dbAchievement.Comment.String,
+			AttachmentLink: dbAchievement.AttachmentLink,
+			UserUUID:       dbAchievement.UserUuid.String(),
+			UserName:       dbAchievement.UserName,
+			UserSecondName: dbAchievement.UserSecondName,
+			UserPatronymic: dbAchievement.UserPatronymic.String,
+			CategoryUUID:   dbAchievement.CategoryUuid.String(),
+			CategoryName:   dbAchievement.CategoryName,
+			Status:         dbAchievement.Status.String,
+			StatusCode:     dbAchievement.StatusCode.String,
+			CreatedAt:      dbAchievement.CreatedAt.Time.Format(time.RFC3339),
+		}
+		achievements[i].AchievementDate = formatPgDate(dbAchievement.AchievementDate)
+		achievements[i].PossibleDuplicateOf = formatPgUUID(dbAchievement.PossibleDuplicateOf)
+		if dbAchievement.ClaimedByUuid.Valid {
+			achievements[i].Claim = &models.AchievementClaim{
+				AchievementUUID:    dbAchievement.Uuid.String(),
+				ReviewerUUID:       dbAchievement.ClaimedByUuid.String(),
+				ReviewerName:       dbAchievement.ClaimedByName.String,
+				ReviewerSecondName: dbAchievement.ClaimedBySecondName.String,
+				ReviewerPatronymic: dbAchievement.ClaimedByPatronymic.String,
+				ExpiresAt:          dbAchievement.ClaimExpiresAt.Time.Format(time.RFC3339),
+			}
+		}
+	}
+
+	return achievements, totalRecords, nil
+}
+
+func (r *achievementRepository) CountAchievementQueueByStatus(ctx context.Context, filter models.AchievementQueueFilter) ([]models.AchievementStatusCount, error) {
+	args := db.CountAchievementQueueByStatusParams{}
+
+	var err error
+	args.CategoryUuid, args.CreatedFrom, args.CreatedTo, args.GroupUuid, err = parseAchievementQueueFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dbCounts, err := r.queries.CountAchievementQueueByStatus(ctx, args)
+	if err != nil {
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	counts := make([]models.AchievementStatusCount, len(dbCounts))
+	for i, dbCount := range dbCounts {
+		counts[i] = models.AchievementStatusCount{
+			Status:     dbCount.Status.String,
+			StatusCode: dbCount.StatusCode.String,
+			Total:      int(dbCount.Total),
+		}
+	}
+
+	return counts, nil
+}
+
+// ClaimAchievement takes or prolongs the review lease. It fails with
+// conflict.AlreadyClaimedErr while another reviewer holds an unexpired lease.
+func (r *achievementRepository) ClaimAchievement(ctx context.Context, uuid, reviewerUUID string, lease time.Duration) error {
 	pgUUID, err := ParseToPgUUID(uuid)
 	if err != nil {
 		return errors.Join(err, validation.WrongInputErr)
 	}
-	err = r.queries.MakeAchievementApproved(ctx, pgUUID)
+
+	pgReviewerUUID, err := ParseToPgUUID(reviewerUUID)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	_, err = r.queries.ClaimAchievement(ctx, db.ClaimAchievementParams{
+		AchievementUuid: pgUUID,
+		ReviewerUuid:    pgReviewerUUID,
+		LeaseSeconds:    lease.Seconds(),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return validation.NoDataFoundErr
+			return conflict.AlreadyClaimedErr
 		}
-
 		return errors.Join(err, unexpected.RequestErr)
 	}
+
 	return nil
 }
 
-func (r *achievementRepository) MakeAchievementUsed(ctx context.Context, uuid string) error {
+func (r *achievementRepository) GetActiveAchievementClaim(ctx context.Context, uuid string) (models.AchievementClaim, error) {
 	pgUUID, err := ParseToPgUUID(uuid)
 	if err != nil {
-		return errors.Join(err, validation.WrongInputErr)
+		return models.AchievementClaim{}, errors.Join(err, validation.WrongInputErr)
 	}
-	err = r.queries.MakeAchievementUsed(ctx, pgUUID)
+
+	dbClaim, err := r.queries.GetActiveAchievementClaim(ctx, pgUUID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return validation.NoDataFoundErr
+			return models.AchievementClaim{}, validation.NoDataFoundErr
 		}
+		return models.AchievementClaim{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return models.AchievementClaim{
+		AchievementUUID:    dbClaim.AchievementUuid.String(),
+		ReviewerUUID:       dbClaim.ReviewerUuid.String(),
+		ReviewerName:       dbClaim.ReviewerName,
+		ReviewerSecondName: dbClaim.ReviewerSecondName,
+		ReviewerPatronymic: dbClaim.ReviewerPatronymic.String,
+		ClaimedAt:          dbClaim.ClaimedAt.Time.Format(time.RFC3339),
+		ExpiresAt:          dbClaim.ExpiresAt.Time.Format(time.RFC3339),
+	}, nil
+}
+
+func (r *achievementRepository) ReleaseAchievementClaim(ctx context.Context, uuid, reviewerUUID string) error {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
 
+	pgReviewerUUID, err := ParseToPgUUID(reviewerUUID)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	rows, err := r.queries.ReleaseAchievementClaim(ctx, db.ReleaseAchievementClaimParams{
+		AchievementUuid: pgUUID,
+		ReviewerUuid:    pgReviewerUUID,
+	})
+	if err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
+
+	if rows == 0 {
+		return conflict.NotClaimedErr
+	}
+
 	return nil
 }
 
-func (r *achievementRepository) MakeAchievementRemoved(ctx context.Context, uuid string) error {
+func (r *achievementRepository) GetDuplicateCandidates(ctx context.Context, userUUID, categoryUUID string) ([]models.DuplicateCandidate, error) {
+	pgUserUUID, err := ParseToPgUUID(userUUID)
+	if err != nil {
+		return nil, errors.Join(err, validation.WrongInputErr)
+	}
+
+	pgCategoryUUID, err := ParseToPgUUID(categoryUUID)
+	if err != nil {
+		return nil, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbCandidates, err := r.queries.GetDuplicateCandidates(ctx, db.GetDuplicateCandidatesParams{
+		CategoryUuid: pgCategoryUUID,
+		UserUuid:     pgUserUUID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.DuplicateCandidate{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	candidates := make([]models.DuplicateCandidate, len(dbCandidates))
+	for i, dbCandidate := range dbCandidates {
+		candidates[i] = models.DuplicateCandidate{
+			UUID:              dbCandidate.Uuid.String(),
+			Comment:           dbCandidate.Comment.String,
+			SubcategoryValues: dbCandidate.SubcategoryValues,
+		}
+		if dbCandidate.AchievementDate.Valid {
+			candidates[i].AchievementDate = dbCandidate.AchievementDate.Time
+		}
+	}
+
+	return candidates, nil
+}
+
+func (r *achievementRepository) GetDuplicateLinkEvidence(ctx context.Context, uuid string) ([]models.DuplicateEvidence, error) {
 	pgUUID, err := ParseToPgUUID(uuid)
 	if err != nil {
-		return errors.Join(err, validation.WrongInputErr)
+		return nil, errors.Join(err, validation.WrongInputErr)
 	}
-	err = r.queries.MakeAchievementRemoved(ctx, pgUUID)
+
+	dbDuplicates, err := r.queries.GetDuplicateLinkEvidence(ctx, pgUUID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return validation.NoDataFoundErr
+			return []models.DuplicateEvidence{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	duplicates := make([]models.DuplicateEvidence, len(dbDuplicates))
+	for i, dbDuplicate := range dbDuplicates {
+		duplicates[i] = models.DuplicateEvidence{
+			Kind:            models.EvidenceKindLink,
+			Evidence:        dbDuplicate.AttachmentLink,
+			AchievementUUID: dbDuplicate.AchievementUuid.String(),
+			UserUUID:        dbDuplicate.UserUuid.String(),
+		}
+	}
+
+	return duplicates, nil
+}
+
+// FillMissingLinkFingerprints normalizes links of achievements created before
+// link fingerprints existed and returns how many were updated.
+func (r *achievementRepository) FillMissingLinkFingerprints(ctx context.Context) (int, error) {
+	dbLinks, err := r.queries.GetAchievementLinksWithoutFingerprint(ctx)
+	if err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	for _, dbLink := range dbLinks {
+		err = r.queries.SetAchievementLinkFingerprint(ctx, db.SetAchievementLinkFingerprintParams{
+			Uuid:                     dbLink.Uuid,
+			AttachmentLinkNormalized: pgtype.Text{String: evidence.NormalizeLink(dbLink.AttachmentLink), Valid: true},
+		})
+		if err != nil {
+			return 0, errors.Join(err, unexpected.RequestErr)
 		}
+	}
+
+	return len(dbLinks), nil
+}
+
+func (r *achievementRepository) MakeAchievementUnapproved(ctx context.Context, uuid, from string, review models.AchievementReview, event models.AuditEvent) error {
+	return r.changeStatus(ctx, uuid, from, "unapproved", false, func(qtx *db.Queries, pgUUID pgtype.UUID) (int64, error) {
+		return qtx.MakeAchievementUnapproved(ctx, db.MakeAchievementUnapprovedParams{Uuid: pgUUID, FromStatus: from})
+	}, review, event)
+}
+
+func (r *achievementRepository) MakeAchievementApproved(ctx context.Context, uuid, from string, review models.AchievementReview, event models.AuditEvent) error {
+	return r.changeStatus(ctx, uuid, from, "approved", true, func(qtx *db.Queries, pgUUID pgtype.UUID) (int64, error) {
+		return qtx.MakeAchievementApproved(ctx, db.MakeAchievementApprovedParams{Uuid: pgUUID, FromStatus: from})
+	}, review, event)
+}
 
+// MakeAchievementUsed marks an approved achievement as used. Achievements in
+// any other status are left as they are and conflict.InvalidTransitionErr is
+// returned.
+func (r *achievementRepository) MakeAchievementUsed(ctx context.Context, uuid string) error {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return errors.Join(err, unexpected.InternalErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	if err = makeAchievementUsed(ctx, qtx, pgUUID); err != nil {
+		return err
+	}
+
+	if err = refreshAchievementScores(ctx, qtx, pgUUID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
 	return nil
 }
 
-func (r *achievementRepository) MakeAchievementDeclined(ctx context.Context, uuid string) error {
+func (r *achievementRepository) MakeAchievementRemoved(ctx context.Context, uuid, from string, review models.AchievementReview, event models.AuditEvent) error {
+	return r.changeStatus(ctx, uuid, from, "removed", false, func(qtx *db.Queries, pgUUID pgtype.UUID) (int64, error) {
+		return qtx.MakeAchievementRemoved(ctx, db.MakeAchievementRemovedParams{Uuid: pgUUID, FromStatus: from})
+	}, review, event)
+}
+
+func (r *achievementRepository) MakeAchievementDeclined(ctx context.Context, uuid, from string, review models.AchievementReview, event models.AuditEvent) error {
+	return r.changeStatus(ctx, uuid, from, "declined", true, func(qtx *db.Queries, pgUUID pgtype.UUID) (int64, error) {
+		return qtx.MakeAchievementDeclined(ctx, db.MakeAchievementDeclinedParams{Uuid: pgUUID, FromStatus: from})
+	}, review, event)
+}
+
+// changeStatus moves the achievement from the status the service checked the
+// transition against. The update only matches the achievement while it is
+// still in that status, so of two concurrent decisions the second one fails
+// with conflict.InvalidTransitionErr instead of overwriting the first.
+//
+// A reviewer decision consumes the claim of the reviewer in the same
+// transaction and fails with conflict.NotClaimedErr if the claim expired or
+// was taken over meanwhile. Other changes just drop any claim.
+func (r *achievementRepository) changeStatus(
+	ctx context.Context,
+	uuid string,
+	from string,
+	status string,
+	claimed bool,
+	update func(qtx *db.Queries, pgUUID pgtype.UUID) (int64, error),
+	review models.AchievementReview,
+	event models.AuditEvent,
+) error {
 	pgUUID, err := ParseToPgUUID(uuid)
 	if err != nil {
 		return errors.Join(err, validation.WrongInputErr)
 	}
-	err = r.queries.MakeAchievementDeclined(ctx, pgUUID)
+
+	tx, err := r.txCreator.CreateTx(ctx)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return validation.NoDataFoundErr
+		return errors.Join(err, unexpected.InternalErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	if claimed {
+		if err = takeAchievementClaim(ctx, qtx, pgUUID, review.ReviewerUUID); err != nil {
+			return err
 		}
+	} else if err = qtx.DeleteAchievementClaim(ctx, pgUUID); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
 
+	if err = createAchievementReview(ctx, qtx, pgUUID, status, review); err != nil {
+		return err
+	}
+
+	updated, err := update(qtx, pgUUID)
+	if err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
+	if updated == 0 {
+		return errors.Join(conflict.InvalidTransitionErr, fmt.Errorf("Статус достижения изменился, ожидался %s", from))
+	}
+
+	if err = refreshAchievementScores(ctx, qtx, pgUUID); err != nil {
+		return err
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+// takeAchievementClaim deletes the unexpired claim of the reviewer.
+func takeAchievementClaim(ctx context.Context, qtx *db.Queries, pgUUID pgtype.UUID, reviewerUUID string) error {
+	pgReviewerUUID, err := ParseToPgUUID(reviewerUUID)
+	if err != nil {
+		return errors.Join(conflict.NotClaimedErr, err)
+	}
+
+	deleted, err := qtx.TakeAchievementClaim(ctx, db.TakeAchievementClaimParams{
+		AchievementUuid: pgUUID,
+		ReviewerUuid:    pgReviewerUUID,
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	if deleted == 0 {
+		return errors.Join(conflict.NotClaimedErr, errors.New("Срок проверки истек или достижение взял другой проверяющий"))
+	}
+
+	return nil
+}
+
+// makeAchievementUsed is the only way an achievement becomes used: it must be
+// approved at the moment of the update.
+func makeAchievementUsed(ctx context.Context, qtx *db.Queries, pgUUID pgtype.UUID) error {
+	updated, err := qtx.MakeAchievementUsed(ctx, db.MakeAchievementUsedParams{Uuid: pgUUID, FromStatus: "approved"})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	if updated == 0 {
+		return errors.Join(conflict.InvalidTransitionErr, fmt.Errorf("Достижение %s больше не одобрено", pgUUID.String()))
+	}
+
 	return nil
 }
 
-func (r *achievementRepository) CreateAchievement(ctx context.Context, userUUID string, achievement requests.UpsertAchievement) (string, error) {
+func (r *achievementRepository) CreateAchievement(ctx context.Context, userUUID string, achievement requests.UpsertAchievement, possibleDuplicateOf string) (string, error) {
 	tx, err := r.txCreator.CreateTx(ctx)
 	if err != nil {
 		return "", errors.Join(err, unexpected.InternalErr)
@@ -355,10 +709,30 @@ func (r *achievementRepository) CreateAchievement(ctx context.Context, userUUID
 		return "", errors.Join(err, validation.WrongInputErr)
 	}
 
+	pgNormalizedLink, err := ParseToPgText(evidence.NormalizeLink(achievement.AttachmentLink))
+	if err != nil {
+		return "", errors.Join(err, validation.WrongInputErr)
+	}
+
+	pgAchievementDate, err := ParseToPgDate(achievement.AchievementDate)
+	if err != nil {
+		return "", errors.Join(err, validation.WrongInputErr)
+	}
+
+	var pgPossibleDuplicateOf pgtype.UUID
+	if possibleDuplicateOf != "" {
+		if pgPossibleDuplicateOf, err = ParseToPgUUID(possibleDuplicateOf); err != nil {
+			return "", errors.Join(err, validation.WrongInputErr)
+		}
+	}
+
 	achievementArg := db.CreateAchievementParams{
-		Comment:        pgComment,
-		AttachmentLink: achievement.AttachmentLink,
-		UserUuid:       pgUserUUUD,
+		Comment:                  pgComment,
+		AttachmentLink:           achievement.AttachmentLink,
+		UserUuid:                 pgUserUUUD,
+		AttachmentLinkNormalized: pgNormalizedLink,
+		AchievementDate:          pgAchievementDate,
+		PossibleDuplicateOf:      pgPossibleDuplicateOf,
 	}
 	achievementUUID, err := qtx.CreateAchievement(ctx, achievementArg)
 	if err != nil {
@@ -431,6 +805,10 @@ func (r *achievementRepository) CreateAchievement(ctx context.Context, userUUID
 		return "", errors.Join(err, unexpected.RequestErr)
 	}
 
+	if err = refreshAchievementScores(ctx, qtx, achievementUUID); err != nil {
+		return "", err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return "", errors.Join(err, unexpected.RequestErr)
 	}
@@ -450,20 +828,49 @@ func (r *achievementRepository) UpdateAchievementDescFields(ctx context.Context,
 		return errors.Join(err, validation.WrongInputErr)
 	}
 
+	pgNormalizedLink, err := ParseToPgText(evidence.NormalizeLink(achievement.AttachmentLink))
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	pgAchievementDate, err := ParseToPgDate(achievement.AttachmentDate)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
 	args := db.UpdateAchievementParams{
-		Comment:        pgComment,
-		AttachmentLink: achievement.AttachmentLink,
-		Uuid:           pgUUID,
+		Comment:                  pgComment,
+		AttachmentLink:           achievement.AttachmentLink,
+		Uuid:                     pgUUID,
+		AttachmentLinkNormalized: pgNormalizedLink,
+		AchievementDate:          pgAchievementDate,
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return errors.Join(err, unexpected.InternalErr)
 	}
-	err = r.queries.UpdateAchievement(ctx, args)
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	err = qtx.UpdateAchievement(ctx, args)
 	if err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
 
+	if err = refreshAchievementScores(ctx, qtx, pgUUID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
 	return nil
 }
 
-func (r *achievementRepository) UpdateAchievementFull(ctx context.Context, achievement requests.UpsertAchievement) error {
+func (r *achievementRepository) UpdateAchievementFull(ctx context.Context, achievement requests.UpsertAchievement, from string, review models.AchievementReview) error {
 	tx, err := r.txCreator.CreateTx(ctx)
 	if err != nil {
 		return errors.Join(err, unexpected.InternalErr)
@@ -483,14 +890,34 @@ func (r *achievementRepository) UpdateAchievementFull(ctx context.Context, achie
 		return errors.Join(err, validation.WrongInputErr)
 	}
 
-	err = qtx.UpdateAchievementWithStatus(ctx, db.UpdateAchievementWithStatusParams{
-		Comment:        pgComment,
-		AttachmentLink: achievement.AttachmentLink,
-		Uuid:           pgAchievementUUID,
+	if err = createAchievementReview(ctx, qtx, pgAchievementUUID, "unapproved", review); err != nil {
+		return err
+	}
+
+	pgNormalizedLink, err := ParseToPgText(evidence.NormalizeLink(achievement.AttachmentLink))
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	pgAchievementDate, err := ParseToPgDate(achievement.AchievementDate)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	updated, err := qtx.UpdateAchievementWithStatus(ctx, db.UpdateAchievementWithStatusParams{
+		Comment:                  pgComment,
+		AttachmentLink:           achievement.AttachmentLink,
+		Uuid:                     pgAchievementUUID,
+		AttachmentLinkNormalized: pgNormalizedLink,
+		AchievementDate:          pgAchievementDate,
+		FromStatus:               from,
 	})
 	if err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
+	if updated == 0 {
+		return errors.Join(conflict.InvalidTransitionErr, fmt.Errorf("Статус достижения изменился, ожидался %s", from))
+	}
 
 	err = qtx.DeleteAchievementCategoryValueByAchievementUUID(ctx, pgAchievementUUID)
 	if err != nil {
@@ -560,9 +987,74 @@ func (r *achievementRepository) UpdateAchievementFull(ctx context.Context, achie
 		return errors.Join(err, unexpected.RequestErr)
 	}
 
+	if err = refreshAchievementScores(ctx, qtx, pgAchievementUUID); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
 
 	return nil
 }
+
+// createAchievementReview records the transition of the achievement to status.
+// It must run before the status update so the previous status is captured,
+// and it writes nothing when the achievement already has that status.
+func createAchievementReview(ctx context.Context, qtx *db.Queries, achievementUUID pgtype.UUID, status string, review models.AchievementReview) error {
+	var reviewerUUID pgtype.UUID
+	if review.ReviewerUUID != "" {
+		var err error
+		reviewerUUID, err = ParseToPgUUID(review.ReviewerUUID)
+		if err != nil {
+			return errors.Join(err, validation.WrongInputErr)
+		}
+	}
+
+	pgComment, err := ParseToPgText(review.Comment)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	err = qtx.CreateAchievementReview(ctx, db.CreateAchievementReviewParams{
+		ReviewerUuid:    reviewerUUID,
+		Comment:         pgComment,
+		ToStatus:        pgtype.Text{String: status, Valid: true},
+		AchievementUuid: achievementUUID,
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+func parseAchievementQueueFilter(filter models.AchievementQueueFilter) (
+	categoryUUID pgtype.UUID,
+	createdFrom pgtype.Timestamptz,
+	createdTo pgtype.Timestamptz,
+	groupUUID pgtype.UUID,
+	err error,
+) {
+	if filter.CategoryUUID != "" {
+		if categoryUUID, err = ParseToPgUUID(filter.CategoryUUID); err != nil {
+			return categoryUUID, createdFrom, createdTo, groupUUID, errors.Join(err, parsing.InputDataErr)
+		}
+	}
+
+	if createdFrom, err = ParseToPgTimestamptz(filter.CreatedFrom); err != nil {
+		return categoryUUID, createdFrom, createdTo, groupUUID, errors.Join(err, parsing.InputDataErr)
+	}
+
+	if createdTo, err = ParseToPgTimestamptz(filter.CreatedTo); err != nil {
+		return categoryUUID, createdFrom, createdTo, groupUUID, errors.Join(err, parsing.InputDataErr)
+	}
+
+	if filter.GroupUUID != "" {
+		if groupUUID, err = ParseToPgUUID(filter.GroupUUID); err != nil {
+			return categoryUUID, createdFrom, createdTo, groupUUID, errors.Join(err, parsing.InputDataErr)
+		}
+	}
+
+	return categoryUUID, createdFrom, createdTo, groupUUID, nil
+}
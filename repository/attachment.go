@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/avito-tech/go-transaction-manager/pgxv5"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment models.Attachment) error
+	GetAttachments(ctx context.Context, achievementUUID string) ([]models.Attachment, error)
+	GetAttachment(ctx context.Context, uuid string) (models.Attachment, error)
+	CountAttachments(ctx context.Context, achievementUUID string) (int, error)
+	DeleteAttachment(ctx context.Context, uuid string) error
+	GetDuplicateAttachmentEvidence(ctx context.Context, achievementUUID string) ([]models.DuplicateEvidence, error)
+	GetAttachmentsWithoutHash(ctx context.Context) ([]models.Attachment, error)
+	SetAttachmentHash(ctx context.Context, uuid, hash string) error
+}
+
+type attachmentRepository struct {
+	queries *db.Queries
+}
+
+func NewAttachmentRepository(pool pgxv5.Tr) AttachmentRepository {
+	return &attachmentRepository{
+		queries: db.New(pool),
+	}
+}
+
+func (r *attachmentRepository) CreateAttachment(ctx context.Context, attachment models.Attachment) error {
+	pgUUID, err := ParseToPgUUID(attachment.UUID)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	pgAchievementUUID, err := ParseToPgUUID(attachment.AchievementUUID)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	var pgUploadedBy pgtype.UUID
+	if attachment.UploadedBy != "" {
+		if pgUploadedBy, err = ParseToPgUUID(attachment.UploadedBy); err != nil {
+			return errors.Join(err, validation.WrongInputErr)
+		}
+	}
+
+	err = r.queries.CreateAchievementAttachment(ctx, db.CreateAchievementAttachmentParams{
+		Uuid:            pgUUID,
+		AchievementUuid: pgAchievementUUID,
+		FileName:        attachment.FileName,
+		ContentType:     attachment.ContentType,
+		Size:            attachment.Size,
+		StorageKey:      attachment.StorageKey,
+		UploadedBy:      pgUploadedBy,
+		Sha256:          pgtype.Text{String: attachment.SHA256, Valid: attachment.SHA256 != ""},
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) GetAttachments(ctx context.Context, achievementUUID string) ([]models.Attachment, error) {
+	pgUUID, err := ParseToPgUUID(achievementUUID)
+	if err != nil {
+		return nil, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbAttachments, err := r.queries.GetAchievementAttachments(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.Attachment{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	attachments := make([]models.Attachment, len(dbAttachments))
+	for i, dbAttachment := range dbAttachments {
+		attachments[i] = attachmentFromDB(dbAttachment)
+	}
+
+	return attachments, nil
+}
+
+func (r *attachmentRepository) GetAttachment(ctx context.Context, uuid string) (models.Attachment, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return models.Attachment{}, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbAttachment, err := r.queries.GetAchievementAttachment(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Attachment{}, validation.NoDataFoundErr
+		}
+		return models.Attachment{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return attachmentFromDB(dbAttachment), nil
+}
+
+func (r *attachmentRepository) CountAttachments(ctx context.Context, achievementUUID string) (int, error) {
+	pgUUID, err := ParseToPgUUID(achievementUUID)
+	if err != nil {
+		return 0, errors.Join(err, validation.WrongInputErr)
+	}
+
+	count, err := r.queries.CountAchievementAttachments(ctx, pgUUID)
+	if err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return int(count), nil
+}
+
+func (r *attachmentRepository) DeleteAttachment(ctx context.Context, uuid string) error {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	if err = r.queries.DeleteAchievementAttachment(ctx, pgUUID); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) GetDuplicateAttachmentEvidence(ctx context.Context, achievementUUID string) ([]models.DuplicateEvidence, error) {
+	pgUUID, err := ParseToPgUUID(achievementUUID)
+	if err != nil {
+		return nil, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbDuplicates, err := r.queries.GetDuplicateAttachmentEvidence(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.DuplicateEvidence{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	duplicates := make([]models.DuplicateEvidence, len(dbDuplicates))
+	for i, dbDuplicate := range dbDuplicates {
+		duplicates[i] = models.DuplicateEvidence{
+			Kind:            models.EvidenceKindFile,
+			Evidence:        dbDuplicate.FileName,
+			DuplicateOf:     dbDuplicate.DuplicateFileName,
+			AchievementUUID: dbDuplicate.DuplicateAchievementUuid.String(),
+			UserUUID:        dbDuplicate.DuplicateUserUuid.String(),
+		}
+	}
+
+	return duplicates, nil
+}
+
+func (r *attachmentRepository) GetAttachmentsWithoutHash(ctx context.Context) ([]models.Attachment, error) {
+	dbAttachments, err := r.queries.GetAttachmentsWithoutHash(ctx)
+	if err != nil {
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	attachments := make([]models.Attachment, len(dbAttachments))
+	for i, dbAttachment := range dbAttachments {
+		attachments[i] = attachmentFromDB(dbAttachment)
+	}
+
+	return attachments, nil
+}
+
+func (r *attachmentRepository) SetAttachmentHash(ctx context.Context, uuid, hash string) error {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	err = r.queries.SetAttachmentHash(ctx, db.SetAttachmentHashParams{
+		Uuid:   pgUUID,
+		Sha256: pgtype.Text{String: hash, Valid: true},
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+func attachmentFromDB(dbAttachment db.AchievementAttachment) models.Attachment {
+	attachment := models.Attachment{
+		UUID:            dbAttachment.Uuid.String(),
+		AchievementUUID: dbAttachment.AchievementUuid.String(),
+		FileName:        dbAttachment.FileName,
+		ContentType:     dbAttachment.ContentType,
+		Size:            dbAttachment.Size,
+		StorageKey:      dbAttachment.StorageKey,
+		SHA256:          dbAttachment.Sha256.String,
+		CreatedAt:       dbAttachment.CreatedAt.Time.Format(time.RFC3339),
+	}
+	if dbAttachment.UploadedBy.Valid {
+		attachment.UploadedBy = dbAttachment.UploadedBy.String()
+	}
+
+	return attachment
+}
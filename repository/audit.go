@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type AuditRepository interface {
+	ListAuditEvents(ctx context.Context, filter models.AuditFilter, limit, offset int) ([]models.AuditEvent, int, error)
+}
+
+type auditRepository struct {
+	queries *db.Queries
+}
+
+func NewAuditRepository(pool db.DBTX) AuditRepository {
+	return &auditRepository{
+		queries: db.New(pool),
+	}
+}
+
+func (r *auditRepository) ListAuditEvents(ctx context.Context, filter models.AuditFilter, limit, offset int) ([]models.AuditEvent, int, error) {
+	args := db.ListAuditEventsParams{
+		RowLimit:  int32(limit),
+		RowOffset: int32(offset),
+	}
+
+	var err error
+	if filter.ActorUUID != "" {
+		if args.ActorUuid, err = ParseToPgUUID(filter.ActorUUID); err != nil {
+			return nil, 0, errors.Join(err, parsing.InputDataErr)
+		}
+	}
+
+	if args.Action, err = ParseToPgText(filter.Action); err != nil {
+		return nil, 0, errors.Join(err, parsing.InputDataErr)
+	}
+
+	if args.EntityType, err = ParseToPgText(filter.EntityType); err != nil {
+		return nil, 0, errors.Join(err, parsing.InputDataErr)
+	}
+
+	if args.EntityID, err = ParseToPgText(filter.EntityID); err != nil {
+		return nil, 0, errors.Join(err, parsing.InputDataErr)
+	}
+
+	if args.CreatedFrom, err = ParseToPgTimestamptz(filter.CreatedFrom); err != nil {
+		return nil, 0, errors.Join(err, parsing.InputDataErr)
+	}
+
+	if args.CreatedTo, err = ParseToPgTimestamptz(filter.CreatedTo); err != nil {
+		return nil, 0, errors.Join(err, parsing.InputDataErr)
+	}
+
+	dbEvents, err := r.queries.ListAuditEvents(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.AuditEvent{}, 0, nil
+		}
+		return nil, 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	events := make([]models.AuditEvent, len(dbEvents))
+	totalRecords := 0
+	for i, dbEvent := range dbEvents {
+		totalRecords = int(dbEvent.TotalRecords)
+
+		event := models.AuditEvent{
+			UUID:       dbEvent.Uuid.String(),
+			Action:     dbEvent.Action,
+			EntityType: dbEvent.EntityType,
+			EntityID:   dbEvent.EntityID,
+			IP:         dbEvent.Ip.String,
+			RequestID:  dbEvent.RequestID.String,
+			CreatedAt:  dbEvent.CreatedAt.Time.Format(time.RFC3339),
+		}
+		if dbEvent.ActorUuid.Valid {
+			event.ActorUUID = dbEvent.ActorUuid.String()
+		}
+		if dbEvent.BeforeData != nil {
+			event.Before = json.RawMessage(dbEvent.BeforeData)
+		}
+		if dbEvent.AfterData != nil {
+			event.After = json.RawMessage(dbEvent.AfterData)
+		}
+
+		events[i] = event
+	}
+
+	return events, totalRecords, nil
+}
+
+// createAuditEvent must be called with the transaction queries of the change
+// being audited so that the event is committed or rolled back together with it.
+func createAuditEvent(ctx context.Context, qtx *db.Queries, event models.AuditEvent) error {
+	args := db.CreateAuditEventParams{
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+	}
+
+	var err error
+	if event.ActorUUID != "" {
+		if args.ActorUuid, err = ParseToPgUUID(event.ActorUUID); err != nil {
+			return errors.Join(err, validation.WrongInputErr)
+		}
+	}
+
+	if event.Before != nil {
+		if args.BeforeData, err = json.Marshal(event.Before); err != nil {
+			return errors.Join(err, unexpected.InternalErr)
+		}
+	}
+
+	if event.After != nil {
+		if args.AfterData, err = json.Marshal(event.After); err != nil {
+			return errors.Join(err, unexpected.InternalErr)
+		}
+	}
+
+	if args.Ip, err = ParseToPgText(event.IP); err != nil {
+		return errors.Join(err, parsing.InputDataErr)
+	}
+
+	if args.RequestID, err = ParseToPgText(event.RequestID); err != nil {
+		return errors.Join(err, parsing.InputDataErr)
+	}
+
+	if err = qtx.CreateAuditEvent(ctx, args); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
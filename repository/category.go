@@ -12,10 +12,11 @@ import (
 	"github.com/igntnk/scholarship_point_system/service/models"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"strconv"
 )
 
 type CategoryRepository interface {
-	CreateCategory(ctx context.Context, name string, pointAmount float32) (string, error)
+	CreateCategory(ctx context.Context, name string, pointAmount float32, rules models.CategoryScoringRules) (string, error)
 	CreateSubCategory(ctx context.Context, name, parentUUID string, catVal []requests.CategoryValues) (string, error)
 	CheckCategoryExistsByNameAndParentNull(context.Context, string) error
 	CheckCategoryExistsByNameAndParentUUID(ctx context.Context, args db.GetCategoryByNameAndParentUUIDParams) error
@@ -24,8 +25,9 @@ type CategoryRepository interface {
 	GetParentCategoriesWithPagination(context.Context, db.ListParentCategoriesWithPaginationParams) ([]db.ListParentCategoriesWithPaginationRow, error)
 	GetParentCategories(ctx context.Context) ([]db.ListParentCategoriesRow, error)
 	DeleteCategory(context.Context, pgtype.UUID) error
-	UpdateCategory(ctx context.Context, uuid, name string, pointAmount float32) error
-	UpdateSubCategory(ctx context.Context, uuid, name string, catVal []requests.CategoryValues) error
+	UpdateCategory(ctx context.Context, uuid, name string, pointAmount float32, rules models.CategoryScoringRules, event models.AuditEvent) error
+	UpdateSubCategory(ctx context.Context, uuid, name string, catVal []requests.CategoryValues, event models.AuditEvent) error
+	GetCategoryValues(ctx context.Context, uuid string) ([]models.CategoryValues, error)
 	GetChildCategories(ctx context.Context, uuid string) ([]models.Category, error)
 }
 
@@ -41,16 +43,22 @@ func NewCategoryRepository(pool pgxv5.Tr) CategoryRepository {
 	}
 }
 
-func (r *categoryRepository) CreateCategory(ctx context.Context, name string, pointAmount float32) (string, error) {
+func (r *categoryRepository) CreateCategory(ctx context.Context, name string, pointAmount float32, rules models.CategoryScoringRules) (string, error) {
 	pgPointAmount, err := ParseToPgNumeric(pointAmount)
 	if err != nil {
 		return "", errors.Join(err, parsing.InputDataErr)
 	}
 
-	catUUID, err := r.queries.CreateCategory(ctx, db.CreateCategoryParams{
+	args := db.CreateCategoryParams{
 		Name:        name,
 		PointAmount: pgPointAmount,
-	})
+	}
+	args.MaxPoints, args.MaxAchievements, args.Multiplier, args.DiminishingFactor, err = categoryScoringRulesToDB(rules)
+	if err != nil {
+		return "", errors.Join(err, parsing.InputDataErr)
+	}
+
+	catUUID, err := r.queries.CreateCategory(ctx, args)
 	if err != nil {
 		return "", errors.Join(err, parsing.InputDataErr)
 	}
@@ -141,10 +149,30 @@ func (r *categoryRepository) GetParentCategories(ctx context.Context) ([]db.List
 }
 
 func (r *categoryRepository) DeleteCategory(ctx context.Context, uuid pgtype.UUID) error {
-	return r.queries.DeleteCategory(ctx, uuid)
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	if err = qtx.DeleteCategory(ctx, uuid); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	if err = refreshCategoryScores(ctx, qtx, uuid); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
 }
 
-func (r *categoryRepository) UpdateCategory(ctx context.Context, uuid, name string, pointAmount float32) error {
+func (r *categoryRepository) UpdateCategory(ctx context.Context, uuid, name string, pointAmount float32, rules models.CategoryScoringRules, event models.AuditEvent) error {
 	pgUUID, err := ParseToPgUUID(uuid)
 	if err != nil {
 		return errors.Join(err, parsing.InputDataErr)
@@ -161,19 +189,45 @@ func (r *categoryRepository) UpdateCategory(ctx context.Context, uuid, name stri
 		return errors.Join(err, parsing.InputDataErr)
 	}
 
-	err = r.queries.UpdateCategory(ctx, db.UpdateCategoryParams{
+	args := db.UpdateCategoryParams{
 		Name:         name,
 		PointAmount:  pgPointAmount,
 		DisplayValue: pgDisplayValue,
 		Uuid:         pgUUID,
-	})
+	}
+	args.MaxPoints, args.MaxAchievements, args.Multiplier, args.DiminishingFactor, err = categoryScoringRulesToDB(rules)
+	if err != nil {
+		return errors.Join(err, parsing.InputDataErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
 	if err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	err = qtx.UpdateCategory(ctx, args)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	if err = refreshCategoryScores(ctx, qtx, pgUUID); err != nil {
+		return err
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
 	return nil
 }
 
-func (r *categoryRepository) UpdateSubCategory(ctx context.Context, uuid, name string, catVal []requests.CategoryValues) error {
+func (r *categoryRepository) UpdateSubCategory(ctx context.Context, uuid, name string, catVal []requests.CategoryValues, event models.AuditEvent) error {
 	catUUID, err := ParseToPgUUID(uuid)
 	if err != nil {
 		return errors.Join(err, parsing.InputDataErr)
@@ -256,6 +310,14 @@ func (r *categoryRepository) UpdateSubCategory(ctx context.Context, uuid, name s
 		return errors.Join(err, unexpected.RequestErr)
 	}
 
+	if err = refreshCategoryScores(ctx, qtx, catUUID); err != nil {
+		return err
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
@@ -263,6 +325,36 @@ func (r *categoryRepository) UpdateSubCategory(ctx context.Context, uuid, name s
 	return nil
 }
 
+func (r *categoryRepository) GetCategoryValues(ctx context.Context, uuid string) ([]models.CategoryValues, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return nil, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbValues, err := r.queries.GetCategoryValuesBySubCategoryUUID(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.CategoryValues{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	values := make([]models.CategoryValues, len(dbValues))
+	for i, dbValue := range dbValues {
+		point, err := dbValue.Point.Float64Value()
+		if err != nil {
+			return nil, errors.Join(err, unexpected.InternalErr)
+		}
+
+		values[i] = models.CategoryValues{
+			Name:   dbValue.Name,
+			Points: float32(point.Float64),
+		}
+	}
+
+	return values, nil
+}
+
 func (r *categoryRepository) GetChildCategories(ctx context.Context, uuid string) ([]models.Category, error) {
 	pgUUID, err := ParseToPgUUID(uuid)
 	if err != nil {
@@ -310,3 +402,71 @@ func (r *categoryRepository) GetChildCategories(ctx context.Context, uuid string
 
 	return result, nil
 }
+
+// categoryScoringRulesToDB converts the rules to column values. Zero values are
+// stored as null, the database keeps the default multiplier and factor then.
+func categoryScoringRulesToDB(rules models.CategoryScoringRules) (
+	maxPoints pgtype.Numeric,
+	maxAchievements pgtype.Int4,
+	multiplier pgtype.Numeric,
+	diminishingFactor pgtype.Numeric,
+	err error,
+) {
+	if rules.MaxPoints != 0 {
+		if maxPoints, err = ParseToPgNumeric(strconv.FormatFloat(rules.MaxPoints, 'f', -1, 64)); err != nil {
+			return
+		}
+	}
+
+	if rules.MaxAchievements != 0 {
+		maxAchievements = pgtype.Int4{Int32: int32(rules.MaxAchievements), Valid: true}
+	}
+
+	if rules.Multiplier != 0 {
+		if multiplier, err = ParseToPgNumeric(strconv.FormatFloat(rules.Multiplier, 'f', -1, 64)); err != nil {
+			return
+		}
+	}
+
+	if rules.DiminishingFactor != 0 {
+		if diminishingFactor, err = ParseToPgNumeric(strconv.FormatFloat(rules.DiminishingFactor, 'f', -1, 64)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// CategoryScoringRulesFromDB converts the rule columns of a category, null
+// columns become zero values.
+func CategoryScoringRulesFromDB(
+	maxPoints pgtype.Numeric,
+	maxAchievements pgtype.Int4,
+	multiplier pgtype.Numeric,
+	diminishingFactor pgtype.Numeric,
+) (models.CategoryScoringRules, error) {
+	rules := models.CategoryScoringRules{
+		MaxAchievements: int(maxAchievements.Int32),
+	}
+
+	for _, column := range []struct {
+		value  pgtype.Numeric
+		target *float64
+	}{
+		{maxPoints, &rules.MaxPoints},
+		{multiplier, &rules.Multiplier},
+		{diminishingFactor, &rules.DiminishingFactor},
+	} {
+		if !column.value.Valid {
+			continue
+		}
+
+		value, err := column.value.Float64Value()
+		if err != nil {
+			return models.CategoryScoringRules{}, errors.Join(err, parsing.OutputDataErr)
+		}
+		*column.target = value.Float64
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/avito-tech/go-transaction-manager/pgxv5"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+type OrgUnitRepository interface {
+	CreateOrgUnit(ctx context.Context, unit models.OrgUnit, event models.AuditEvent) (string, error)
+	GetOrgUnitByUUID(ctx context.Context, uuid string) (models.OrgUnit, error)
+	ListOrgUnits(ctx context.Context) ([]models.OrgUnit, error)
+	UpdateOrgUnit(ctx context.Context, unit models.OrgUnit, event models.AuditEvent) error
+	DeleteOrgUnit(ctx context.Context, uuid string, event models.AuditEvent) error
+	// GetOrgUnitSubtree returns the uuids of the unit and of all units nested
+	// in it.
+	GetOrgUnitSubtree(ctx context.Context, uuid string) ([]string, error)
+	AssignUsers(ctx context.Context, uuid string, userUUIDs []string, event models.AuditEvent) (int, error)
+	UnassignUsers(ctx context.Context, uuid string, userUUIDs []string, event models.AuditEvent) (int, error)
+}
+
+type orgUnitRepository struct {
+	queries   *db.Queries
+	txCreator db.TxCreator
+}
+
+func NewOrgUnitRepository(pool pgxv5.Tr) OrgUnitRepository {
+	return &orgUnitRepository{
+		queries:   db.New(pool),
+		txCreator: db.NewTxCreator(pool),
+	}
+}
+
+func (r *orgUnitRepository) CreateOrgUnit(ctx context.Context, unit models.OrgUnit, event models.AuditEvent) (string, error) {
+	pgParentUUID, err := parseOptionalPgUUID(unit.ParentUUID)
+	if err != nil {
+		return "", errors.Join(err, validation.WrongInputErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return "", errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	pgUUID, err := qtx.CreateOrgUnit(ctx, db.CreateOrgUnitParams{
+		Name:        unit.Name,
+		Kind:        unit.Kind,
+		ParentUuid:  pgParentUUID,
+		WinnerQuota: pgtype.Int4{Int32: int32(unit.WinnerQuota), Valid: unit.WinnerQuota > 0},
+	})
+	if err != nil {
+		return "", errors.Join(err, unexpected.RequestErr)
+	}
+
+	event.EntityID = pgUUID.String()
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", errors.Join(err, unexpected.RequestErr)
+	}
+
+	return pgUUID.String(), nil
+}
+
+func (r *orgUnitRepository) GetOrgUnitByUUID(ctx context.Context, uuid string) (models.OrgUnit, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return models.OrgUnit{}, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbUnit, err := r.queries.GetOrgUnitByUUID(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.OrgUnit{}, validation.NoDataFoundErr
+		}
+		return models.OrgUnit{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	unit := orgUnitFromDB(db.OrgUnit{
+		Uuid:        dbUnit.Uuid,
+		Name:        dbUnit.Name,
+		Kind:        dbUnit.Kind,
+		ParentUuid:  dbUnit.ParentUuid,
+		WinnerQuota: dbUnit.WinnerQuota,
+		CreatedAt:   dbUnit.CreatedAt,
+	})
+	unit.UserAmount = int(dbUnit.UserAmount)
+
+	return unit, nil
+}
+
+func (r *orgUnitRepository) ListOrgUnits(ctx context.Context) ([]models.OrgUnit, error) {
+	dbUnits, err := r.queries.ListOrgUnits(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.OrgUnit{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	units := make([]models.OrgUnit, len(dbUnits))
+	for i, dbUnit := range dbUnits {
+		units[i] = orgUnitFromDB(db.OrgUnit{
+			Uuid:        dbUnit.Uuid,
+			Name:        dbUnit.Name,
+			Kind:        dbUnit.Kind,
+			ParentUuid:  dbUnit.ParentUuid,
+			WinnerQuota: dbUnit.WinnerQuota,
+			CreatedAt:   dbUnit.CreatedAt,
+		})
+		units[i].UserAmount = int(dbUnit.UserAmount)
+	}
+
+	return units, nil
+}
+
+func (r *orgUnitRepository) UpdateOrgUnit(ctx context.Context, unit models.OrgUnit, event models.AuditEvent) error {
+	pgUUID, err := ParseToPgUUID(unit.UUID)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	pgParentUUID, err := parseOptionalPgUUID(unit.ParentUUID)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	err = qtx.UpdateOrgUnit(ctx, db.UpdateOrgUnitParams{
+		Uuid:        pgUUID,
+		Name:        unit.Name,
+		Kind:        unit.Kind,
+		ParentUuid:  pgParentUUID,
+		WinnerQuota: pgtype.Int4{Int32: int32(unit.WinnerQuota), Valid: unit.WinnerQuota > 0},
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+// DeleteOrgUnit only deletes an empty unit, nested units and students have to
+// be moved out first.
+func (r *orgUnitRepository) DeleteOrgUnit(ctx context.Context, uuid string, event models.AuditEvent) error {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	inUse, err := qtx.HasOrgUnitDependents(ctx, pgUUID)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	if inUse {
+		return conflict.OrgUnitInUseErr
+	}
+
+	deleted, err := qtx.DeleteOrgUnit(ctx, pgUUID)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	if deleted == 0 {
+		return validation.NoDataFoundErr
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+func (r *orgUnitRepository) GetOrgUnitSubtree(ctx context.Context, uuid string) ([]string, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return nil, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbUUIDs, err := r.queries.GetOrgUnitSubtree(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []string{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	uuids := make([]string, len(dbUUIDs))
+	for i, dbUUID := range dbUUIDs {
+		uuids[i] = dbUUID.String()
+	}
+
+	return uuids, nil
+}
+
+func (r *orgUnitRepository) AssignUsers(ctx context.Context, uuid string, userUUIDs []string, event models.AuditEvent) (int, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return 0, errors.Join(err, validation.WrongInputErr)
+	}
+
+	pgUserUUIDs, err := parsePgUUIDs(userUUIDs)
+	if err != nil {
+		return 0, errors.Join(err, validation.WrongInputErr)
+	}
+
+	return r.changeUsers(ctx, event, func(qtx *db.Queries) (int64, error) {
+		return qtx.AssignUsersToOrgUnit(ctx, db.AssignUsersToOrgUnitParams{
+			OrgUnitUuid: pgUUID,
+			UserUuids:   pgUserUUIDs,
+		})
+	})
+}
+
+func (r *orgUnitRepository) UnassignUsers(ctx context.Context, uuid string, userUUIDs []string, event models.AuditEvent) (int, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return 0, errors.Join(err, validation.WrongInputErr)
+	}
+
+	pgUserUUIDs, err := parsePgUUIDs(userUUIDs)
+	if err != nil {
+		return 0, errors.Join(err, validation.WrongInputErr)
+	}
+
+	return r.changeUsers(ctx, event, func(qtx *db.Queries) (int64, error) {
+		return qtx.UnassignUsersFromOrgUnit(ctx, db.UnassignUsersFromOrgUnitParams{
+			OrgUnitUuid: pgUUID,
+			UserUuids:   pgUserUUIDs,
+		})
+	})
+}
+
+func (r *orgUnitRepository) changeUsers(ctx context.Context, event models.AuditEvent, change func(qtx *db.Queries) (int64, error)) (int, error) {
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	changed, err := change(qtx)
+	if err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return int(changed), nil
+}
+
+func orgUnitFromDB(dbUnit db.OrgUnit) models.OrgUnit {
+	return models.OrgUnit{
+		UUID:        dbUnit.Uuid.String(),
+		Name:        dbUnit.Name,
+		Kind:        dbUnit.Kind,
+		ParentUUID:  formatPgUUID(dbUnit.ParentUuid),
+		WinnerQuota: int(dbUnit.WinnerQuota.Int32),
+		CreatedAt:   dbUnit.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+// parseOptionalPgUUID turns an empty string into null.
+func parseOptionalPgUUID(input string) (pgtype.UUID, error) {
+	if input == "" {
+		return pgtype.UUID{}, nil
+	}
+
+	return ParseToPgUUID(input)
+}
@@ -0,0 +1,436 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/avito-tech/go-transaction-manager/pgxv5"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+type PeriodRepository interface {
+	CreatePeriod(ctx context.Context, period models.Period, event models.AuditEvent) (string, error)
+	GetPeriodByUUID(ctx context.Context, uuid string) (models.Period, error)
+	ListPeriods(ctx context.Context, status string, limit, offset int) ([]models.Period, int, error)
+	UpdatePeriod(ctx context.Context, period models.Period, event models.AuditEvent) error
+	DeletePeriod(ctx context.Context, uuid string, event models.AuditEvent) error
+	GetOverlappingPeriods(ctx context.Context, period models.Period) ([]models.Period, error)
+	GetPeriodByDate(ctx context.Context, date time.Time) (models.Period, error)
+	GetAchievementPeriod(ctx context.Context, achievementUUID string) (models.Period, error)
+	GetPeriodAchievementPoints(ctx context.Context, period models.Period, statuses []string) ([]models.AchievementPoints, error)
+	ClosePeriod(
+		ctx context.Context,
+		uuid string,
+		closeOut func(models.Period, []models.AchievementPoints) (models.PeriodCloseOut, error),
+		review models.AchievementReview,
+		event models.AuditEvent,
+	) (models.PeriodCloseOut, error)
+}
+
+type periodRepository struct {
+	queries   *db.Queries
+	txCreator db.TxCreator
+}
+
+func NewPeriodRepository(pool pgxv5.Tr) PeriodRepository {
+	return &periodRepository{
+		queries:   db.New(pool),
+		txCreator: db.NewTxCreator(pool),
+	}
+}
+
+func (r *periodRepository) CreatePeriod(ctx context.Context, period models.Period, event models.AuditEvent) (string, error) {
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return "", errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	pgUUID, err := qtx.CreatePeriod(ctx, db.CreatePeriodParams{
+		Name:               period.Name,
+		StartDate:          pgtype.Date{Time: period.StartDate, Valid: true},
+		EndDate:            pgtype.Date{Time: period.EndDate, Valid: true},
+		SubmissionDeadline: pgtype.Date{Time: period.SubmissionDeadline, Valid: true},
+		Status:             period.Status,
+		WinnerCount:        int32(period.WinnerCount),
+	})
+	if err != nil {
+		return "", errors.Join(err, unexpected.RequestErr)
+	}
+
+	event.EntityID = pgUUID.String()
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", errors.Join(err, unexpected.RequestErr)
+	}
+
+	return pgUUID.String(), nil
+}
+
+func (r *periodRepository) GetPeriodByUUID(ctx context.Context, uuid string) (models.Period, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return models.Period{}, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbPeriod, err := r.queries.GetPeriodByUUID(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Period{}, validation.NoDataFoundErr
+		}
+		return models.Period{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return periodFromDB(dbPeriod), nil
+}
+
+func (r *periodRepository) ListPeriods(ctx context.Context, status string, limit, offset int) ([]models.Period, int, error) {
+	pgStatus, err := ParseToPgText(status)
+	if err != nil {
+		return nil, 0, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbPeriods, err := r.queries.ListPeriods(ctx, db.ListPeriodsParams{
+		Status:    pgStatus,
+		RowLimit:  int32(limit),
+		RowOffset: int32(offset),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.Period{}, 0, nil
+		}
+		return nil, 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	periods := make([]models.Period, len(dbPeriods))
+	totalRecords := 0
+	for i, dbPeriod := range dbPeriods {
+		totalRecords = int(dbPeriod.TotalRecords)
+		periods[i] = periodFromDB(db.Period{
+			Uuid:               dbPeriod.Uuid,
+			Name:               dbPeriod.Name,
+			StartDate:          dbPeriod.StartDate,
+			EndDate:            dbPeriod.EndDate,
+			SubmissionDeadline: dbPeriod.SubmissionDeadline,
+			Status:             dbPeriod.Status,
+			WinnerCount:        dbPeriod.WinnerCount,
+			CreatedAt:          dbPeriod.CreatedAt,
+		})
+	}
+
+	return periods, totalRecords, nil
+}
+
+func (r *periodRepository) UpdatePeriod(ctx context.Context, period models.Period, event models.AuditEvent) error {
+	pgUUID, err := ParseToPgUUID(period.UUID)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	err = qtx.UpdatePeriod(ctx, db.UpdatePeriodParams{
+		Uuid:               pgUUID,
+		Name:               period.Name,
+		StartDate:          pgtype.Date{Time: period.StartDate, Valid: true},
+		EndDate:            pgtype.Date{Time: period.EndDate, Valid: true},
+		SubmissionDeadline: pgtype.Date{Time: period.SubmissionDeadline, Valid: true},
+		Status:             period.Status,
+		WinnerCount:        int32(period.WinnerCount),
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+// DeletePeriod only deletes a period without rating snapshots, they are the
+// record of the published results.
+func (r *periodRepository) DeletePeriod(ctx context.Context, uuid string, event models.AuditEvent) error {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return errors.Join(err, validation.WrongInputErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	hasSnapshots, err := qtx.HasPeriodSnapshots(ctx, pgUUID)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	if hasSnapshots {
+		return conflict.PeriodInUseErr
+	}
+
+	deleted, err := qtx.DeletePeriod(ctx, pgUUID)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	if deleted == 0 {
+		return validation.NoDataFoundErr
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
+
+func (r *periodRepository) GetOverlappingPeriods(ctx context.Context, period models.Period) ([]models.Period, error) {
+	args := db.GetOverlappingPeriodsParams{
+		StartDate: pgtype.Date{Time: period.StartDate, Valid: true},
+		EndDate:   pgtype.Date{Time: period.EndDate, Valid: true},
+	}
+
+	var err error
+	if period.UUID != "" {
+		if args.ExcludeUuid, err = ParseToPgUUID(period.UUID); err != nil {
+			return nil, errors.Join(err, validation.WrongInputErr)
+		}
+	}
+
+	dbPeriods, err := r.queries.GetOverlappingPeriods(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.Period{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	periods := make([]models.Period, len(dbPeriods))
+	for i, dbPeriod := range dbPeriods {
+		periods[i] = periodFromDB(dbPeriod)
+	}
+
+	return periods, nil
+}
+
+func (r *periodRepository) GetPeriodByDate(ctx context.Context, date time.Time) (models.Period, error) {
+	dbPeriod, err := r.queries.GetPeriodByDate(ctx, pgtype.Date{Time: date, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Period{}, validation.NoDataFoundErr
+		}
+		return models.Period{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return periodFromDB(dbPeriod), nil
+}
+
+func (r *periodRepository) GetAchievementPeriod(ctx context.Context, achievementUUID string) (models.Period, error) {
+	pgUUID, err := ParseToPgUUID(achievementUUID)
+	if err != nil {
+		return models.Period{}, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbPeriod, err := r.queries.GetAchievementPeriod(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Period{}, validation.NoDataFoundErr
+		}
+		return models.Period{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return periodFromDB(dbPeriod), nil
+}
+
+func (r *periodRepository) GetPeriodAchievementPoints(ctx context.Context, period models.Period, statuses []string) ([]models.AchievementPoints, error) {
+	return getPeriodAchievementPoints(ctx, r.queries, period, statuses)
+}
+
+// ClosePeriod locks the period, lets closeOut decide on the final ranking and
+// then stores the snapshot, marks the winning achievements as used and archives
+// the period in a single transaction.
+func (r *periodRepository) ClosePeriod(
+	ctx context.Context,
+	uuid string,
+	closeOut func(models.Period, []models.AchievementPoints) (models.PeriodCloseOut, error),
+	review models.AchievementReview,
+	event models.AuditEvent,
+) (models.PeriodCloseOut, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return models.PeriodCloseOut{}, errors.Join(err, validation.WrongInputErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return models.PeriodCloseOut{}, errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	dbPeriod, err := qtx.GetPeriodByUUIDForUpdate(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PeriodCloseOut{}, validation.NoDataFoundErr
+		}
+		return models.PeriodCloseOut{}, errors.Join(err, unexpected.RequestErr)
+	}
+	period := periodFromDB(dbPeriod)
+
+	points, err := getPeriodAchievementPoints(ctx, qtx, period, models.WinnerStatuses)
+	if err != nil {
+		return models.PeriodCloseOut{}, err
+	}
+
+	result, err := closeOut(period, points)
+	if err != nil {
+		return models.PeriodCloseOut{}, err
+	}
+
+	result.Snapshot, err = createRatingSnapshot(ctx, qtx, result.Snapshot)
+	if err != nil {
+		return models.PeriodCloseOut{}, err
+	}
+
+	usedAchievements := make([]pgtype.UUID, len(result.UsedAchievements))
+	for i, achievementUUID := range result.UsedAchievements {
+		pgAchievementUUID, err := ParseToPgUUID(achievementUUID)
+		if err != nil {
+			return models.PeriodCloseOut{}, errors.Join(err, validation.WrongInputErr)
+		}
+		usedAchievements[i] = pgAchievementUUID
+
+		if err = createAchievementReview(ctx, qtx, pgAchievementUUID, "used", review); err != nil {
+			return models.PeriodCloseOut{}, err
+		}
+
+		if err = makeAchievementUsed(ctx, qtx, pgAchievementUUID); err != nil {
+			return models.PeriodCloseOut{}, err
+		}
+
+		if err = qtx.DeleteAchievementClaim(ctx, pgAchievementUUID); err != nil {
+			return models.PeriodCloseOut{}, errors.Join(err, unexpected.RequestErr)
+		}
+	}
+
+	if err = refreshAchievementScores(ctx, qtx, usedAchievements...); err != nil {
+		return models.PeriodCloseOut{}, err
+	}
+
+	err = qtx.UpdatePeriodStatus(ctx, db.UpdatePeriodStatusParams{
+		Uuid:   pgUUID,
+		Status: result.Period.Status,
+	})
+	if err != nil {
+		return models.PeriodCloseOut{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return models.PeriodCloseOut{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.PeriodCloseOut{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return result, nil
+}
+
+// getPeriodAchievementPoints returns every achievement dated inside the period
+// together with its score components. A period without dates selects
+// achievements of all time. The points are left for the scoring service.
+func getPeriodAchievementPoints(ctx context.Context, queries *db.Queries, period models.Period, statuses []string) ([]models.AchievementPoints, error) {
+	dbPoints, err := queries.GetPeriodAchievementPoints(ctx, db.GetPeriodAchievementPointsParams{
+		Statuses:  statuses,
+		StartDate: pgtype.Date{Time: period.StartDate, Valid: !period.StartDate.IsZero()},
+		EndDate:   pgtype.Date{Time: period.EndDate, Valid: !period.EndDate.IsZero()},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.AchievementPoints{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	if len(dbPoints) == 0 {
+		return []models.AchievementPoints{}, nil
+	}
+
+	achievementUUIDs := make([]string, len(dbPoints))
+	for i, dbPoint := range dbPoints {
+		achievementUUIDs[i] = dbPoint.AchievementUuid.String()
+	}
+
+	inputs, err := getAchievementScoreInputs(ctx, queries, models.ScoringFilter{AchievementUUIDs: achievementUUIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	inputsByAchievement := make(map[string]models.AchievementScoreInput, len(inputs))
+	for _, input := range inputs {
+		inputsByAchievement[input.AchievementUUID] = input
+	}
+
+	points := make([]models.AchievementPoints, len(dbPoints))
+	for i, dbPoint := range dbPoints {
+		points[i] = models.AchievementPoints{
+			AchievementUUID: achievementUUIDs[i],
+			UserUUID:        dbPoint.UserUuid.String(),
+			UserName:        dbPoint.UserName,
+			UserSecondName:  dbPoint.UserSecondName,
+			UserPatronymic:  dbPoint.UserPatronymic.String,
+			GradebookNumber: dbPoint.GradebookNumber,
+			UserStatus:      dbPoint.UserStatus.String,
+			UserOrgUnitUUID: formatPgUUID(dbPoint.UserOrgUnitUuid),
+			CategoryUUID:    dbPoint.CategoryUuid.String(),
+			CategoryName:    dbPoint.CategoryName,
+			SubmittedAt:     dbPoint.SubmittedAt.Time,
+			ScoreInput:      inputsByAchievement[achievementUUIDs[i]],
+		}
+	}
+
+	return points, nil
+}
+
+func periodFromDB(dbPeriod db.Period) models.Period {
+	return models.Period{
+		UUID:               dbPeriod.Uuid.String(),
+		Name:               dbPeriod.Name,
+		StartDate:          dbPeriod.StartDate.Time,
+		EndDate:            dbPeriod.EndDate.Time,
+		SubmissionDeadline: dbPeriod.SubmissionDeadline.Time,
+		Status:             dbPeriod.Status,
+		WinnerCount:        int(dbPeriod.WinnerCount),
+		CreatedAt:          dbPeriod.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
@@ -20,7 +20,7 @@ type PermissionRepository interface {
 	CreateRole(ctx context.Context, role models.Role) (string, error)
 	CreateGroup(ctx context.Context, group models.Group) (string, error)
 	UpdateRoleWithMembers(ctx context.Context, role models.UpdateRole) error
-	UpdateGroupWithRolesAndResources(ctx context.Context, group models.UpdateGroup) error
+	UpdateGroupWithRolesAndResources(ctx context.Context, group models.UpdateGroup, event models.AuditEvent) error
 	RenameGroup(ctx context.Context, uuid, name string) error
 	RenameRole(ctx context.Context, uuid, name string) error
 	DeleteRole(ctx context.Context, uuid string) error
@@ -314,7 +314,7 @@ func (r *permissionRepository) UpdateRoleWithMembers(ctx context.Context, role m
 	return nil
 }
 
-func (r *permissionRepository) UpdateGroupWithRolesAndResources(ctx context.Context, group models.UpdateGroup) error {
+func (r *permissionRepository) UpdateGroupWithRolesAndResources(ctx context.Context, group models.UpdateGroup, event models.AuditEvent) error {
 	tx, err := r.txCreator.CreateTx(ctx)
 	if err != nil {
 		return errors.Join(err, unexpected.RequestErr)
@@ -412,6 +412,10 @@ func (r *permissionRepository) UpdateGroupWithRolesAndResources(ctx context.Cont
 		}
 	}
 
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return errors.Join(err, unexpected.RequestErr)
 	}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ratingQuery collects the arguments of a dynamically built rating query.
+// Every value coming from a request must go through arg, only identifiers
+// from the whitelists below may be written into the query text.
+type ratingQuery struct {
+	args []any
+}
+
+// arg binds the value to the next positional parameter and returns its placeholder.
+func (q *ratingQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+var ratingRankFunctions = map[string]string{
+	models.RankModeCompetition: "rank()",
+	models.RankModeDense:       "dense_rank()",
+}
+
+var ratingTieBreakers = map[string]string{
+	models.TieBreakerAchievements:       "achievement_amount desc",
+	models.TieBreakerEarliestSubmission: "last_submitted_at asc nulls last",
+}
+
+// rankedFor returns the common table expressions of the students the filter
+// selects, the same for every query built from a rating filter.
+func (q *ratingQuery) rankedFor(filter models.RatingFilter) (string, error) {
+	return q.rankedRating(filter.Scores, filter.Valid || filter.Winners, filter.OrgUnitUUIDs, filter.Ranking)
+}
+
+// rankedRating returns the common table expressions of the rating. The ranked
+// expression holds one row per student with the points, the number of counted
+// achievements and the rank computed over all selected students. Only the
+// achievements present in scores are counted, with the points given there.
+// Without scores the stored all-time totals of student_score are used. With
+// org units only their students are selected, so they are ranked among
+// themselves.
+func (q *ratingQuery) rankedRating(scores map[string]float64, validOnly bool, orgUnitUUIDs []string, rules models.RankingRules) (string, error) {
+	userWhere := "true"
+	if validOnly {
+		userWhere = "u_s.internal_value = 'approved'"
+	}
+
+	if orgUnitUUIDs != nil {
+		pgOrgUnitUUIDs, err := parsePgUUIDs(orgUnitUUIDs)
+		if err != nil {
+			return "", errors.Join(err, validation.WrongInputErr)
+		}
+		userWhere = fmt.Sprintf("%s and u.org_unit_uuid = any (%s::uuid[])", userWhere, q.arg(pgOrgUnitUUIDs))
+	}
+
+	totals := `student_totals as (select ss.user_uuid,
+                                   ss.point_amount::float8 as point_amount,
+                                   ss.achievement_amount,
+                                   ss.achievement_proofed,
+                                   ss.last_submitted_at
+                            from student_score ss)`
+	if scores != nil {
+		achievementUUIDs := make([]pgtype.UUID, 0, len(scores))
+		points := make([]float64, 0, len(scores))
+		for uuid, point := range scores {
+			pgUUID, err := ParseToPgUUID(uuid)
+			if err != nil {
+				return "", errors.Join(err, validation.WrongInputErr)
+			}
+			achievementUUIDs = append(achievementUUIDs, pgUUID)
+			points = append(points, point)
+		}
+
+		totals = fmt.Sprintf(`student_totals as (select a.user_uuid,
+                                   sum(scores.point_amount)::float8         as point_amount,
+                                   count(a.uuid)::int                       as achievement_amount,
+                                   bool_and(a_s.internal_value != 'unapproved') as achievement_proofed,
+                                   max(a.created_at)                        as last_submitted_at
+                            from unnest(%s::uuid[], %s::float8[]) as scores(uuid, point_amount)
+                                     join achievement a on a.uuid = scores.uuid
+                                     join status a_s on a_s.uuid = a.status_uuid
+                            group by a.user_uuid)`,
+			q.arg(achievementUUIDs),
+			q.arg(points),
+		)
+	}
+
+	return fmt.Sprintf(`
+with %s,
+     rating as (select u.uuid,
+                       u.name,
+                       u.second_name,
+                       u.patronymic,
+                       u.gradebook_number,
+                       u.birth_date,
+                       u.phone_number,
+                       u.email,
+                       u.org_unit_uuid,
+                       u_s.internal_value                            as user_status,
+                       coalesce(st.point_amount, 0)::float8          as point_amount,
+                       coalesce(st.achievement_amount, 0)::int       as achievement_amount,
+                       coalesce(st.achievement_proofed, true)        as achievement_proofed,
+                       st.last_submitted_at
+                from sys_user u
+                         join status u_s on u_s.uuid = u.status_uuid
+                         left join student_totals st on st.user_uuid = u.uuid
+                where %s),
+     ranked as (select rating.*,
+                       %s over (%s)::int as rank
+                from rating)
+`,
+		totals,
+		userWhere,
+		ratingRankFunction(rules.Mode),
+		ratingRankOrder(rules.TieBreakers),
+	), nil
+}
+
+func ratingRankFunction(mode string) string {
+	if function, ok := ratingRankFunctions[mode]; ok {
+		return function
+	}
+
+	return ratingRankFunctions[models.RankModeCompetition]
+}
+
+// ratingRankOrder orders by points and then by the configured tie-breakers.
+// Students equal on all of them share a rank.
+func ratingRankOrder(tieBreakers []string) string {
+	terms := []string{"point_amount desc"}
+	for _, tieBreaker := range tieBreakers {
+		if term, ok := ratingTieBreakers[tieBreaker]; ok {
+			terms = append(terms, term)
+		}
+	}
+
+	return "order by " + strings.Join(terms, ", ")
+}
+
+// ratingSearchCondition matches students by name, gradebook number and email.
+// Punctuation is replaced the same way ratingSearchQuery does it, so parts of
+// an email or a gradebook number can be found on their own.
+func (q *ratingQuery) ratingSearchCondition(search string) string {
+	searchQuery := ratingSearchQuery(search)
+	if searchQuery == "" {
+		return "true"
+	}
+
+	return fmt.Sprintf("to_tsvector('simple', regexp_replace(concat_ws(' ', name, second_name, patronymic, gradebook_number, email), '[^[:alnum:]]+', ' ', 'g')) @@ to_tsquery('simple', %s)",
+		q.arg(searchQuery),
+	)
+}
+
+var ratingSortColumns = map[string][]string{
+	models.RatingSortName:         {"second_name", "name", "patronymic"},
+	models.RatingSortAchievements: {"achievement_amount"},
+}
+
+// ratingOrderBy builds the order by clause for a whitelisted sort option.
+// Sorting by points follows the rank, so tie-breakers apply to the list as
+// well. The uuid makes the order of equal students stable between pages.
+func ratingOrderBy(sortBy, sortOrder string) string {
+	direction := "desc"
+	if sortOrder == models.SortOrderAsc {
+		direction = "asc"
+	}
+
+	columns, ok := ratingSortColumns[sortBy]
+	if !ok {
+		columns = []string{"rank"}
+		if direction == "desc" {
+			direction = "asc"
+		} else {
+			direction = "desc"
+		}
+	}
+
+	terms := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		terms = append(terms, column+" "+direction+" nulls last")
+	}
+	terms = append(terms, "uuid")
+
+	return "order by " + strings.Join(terms, ", ")
+}
+
+// ratingSearchQuery turns free text into a prefix tsquery where every word
+// must match. Words are reduced to letters and digits so the result is always
+// valid tsquery syntax. An empty string means there is nothing to search for.
+func ratingSearchQuery(search string) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"errors"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// hostileInputs are request values that must never reach the query text.
+var hostileInputs = []string{
+	"'; drop table sys_user; --",
+	"%",
+	`\`,
+	"&|!:*",
+	"",
+	"') or 1=1 --",
+	"$1",
+}
+
+var (
+	placeholderPattern  = regexp.MustCompile(`\$\d+`)
+	searchQueryPattern  = regexp.MustCompile(`^([\p{L}\p{N}]+:\*( & [\p{L}\p{N}]+:\*)*)?$`)
+	searchConditionText = "to_tsvector('simple', regexp_replace(concat_ws(' ', name, second_name, patronymic, gradebook_number, email), '[^[:alnum:]]+', ' ', 'g')) @@ to_tsquery('simple', $1)"
+)
+
+func TestRatingSearchQuery(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{"'; drop table sys_user; --", "drop:* & table:* & sys:* & user:*"},
+		{"%", ""},
+		{`\`, ""},
+		{"&|!:*", ""},
+		{"", ""},
+		{"   ", ""},
+		{"Иванов", "иванов:*"},
+		{"ivan.petrov@mail.ru", "ivan:* & petrov:* & mail:* & ru:*"},
+		{"ИБ-21/034", "иб:* & 21:* & 034:*"},
+		{"a:* | b", "a:* & b:*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			got := ratingSearchQuery(tt.search)
+			if got != tt.want {
+				t.Errorf("ratingSearchQuery(%q) = %q, want %q", tt.search, got, tt.want)
+			}
+			if !searchQueryPattern.MatchString(got) {
+				t.Errorf("ratingSearchQuery(%q) = %q is not a plain prefix tsquery", tt.search, got)
+			}
+		})
+	}
+}
+
+func TestRatingSearchCondition(t *testing.T) {
+	for _, search := range append(hostileInputs, "Иванов Иван") {
+		t.Run(search, func(t *testing.T) {
+			q := &ratingQuery{}
+			condition := q.ratingSearchCondition(search)
+
+			want := ratingSearchQuery(search)
+			if want == "" {
+				if condition != "true" || len(q.args) != 0 {
+					t.Fatalf("condition = %q with args %v, want true without args", condition, q.args)
+				}
+				return
+			}
+
+			if condition != searchConditionText {
+				t.Fatalf("condition = %q, want %q", condition, searchConditionText)
+			}
+			if len(q.args) != 1 || q.args[0] != want {
+				t.Fatalf("args = %v, want [%q]", q.args, want)
+			}
+		})
+	}
+}
+
+func TestRatingOrderBy(t *testing.T) {
+	tests := []struct {
+		sortBy, sortOrder string
+		want              string
+	}{
+		{models.RatingSortPoints, models.SortOrderDesc, "order by rank asc nulls last, uuid"},
+		{models.RatingSortPoints, models.SortOrderAsc, "order by rank desc nulls last, uuid"},
+		{"", "", "order by rank asc nulls last, uuid"},
+		{models.RatingSortName, models.SortOrderAsc, "order by second_name asc nulls last, name asc nulls last, patronymic asc nulls last, uuid"},
+		{models.RatingSortName, "", "order by second_name desc nulls last, name desc nulls last, patronymic desc nulls last, uuid"},
+		{models.RatingSortAchievements, models.SortOrderDesc, "order by achievement_amount desc nulls last, uuid"},
+	}
+
+	for _, tt := range tests {
+		if got := ratingOrderBy(tt.sortBy, tt.sortOrder); got != tt.want {
+			t.Errorf("ratingOrderBy(%q, %q) = %q, want %q", tt.sortBy, tt.sortOrder, got, tt.want)
+		}
+	}
+
+	allowed := map[string]bool{
+		"order by rank asc nulls last, uuid":  true,
+		"order by rank desc nulls last, uuid": true,
+	}
+	for _, input := range hostileInputs {
+		for _, got := range []string{ratingOrderBy(input, models.SortOrderAsc), ratingOrderBy(models.RatingSortPoints, input)} {
+			if !allowed[got] {
+				t.Errorf("ratingOrderBy with %q = %q, want the rank order", input, got)
+			}
+		}
+	}
+}
+
+// TestRatingQueryPlaceholders builds the whole rating expression from request
+// values and checks that they are only passed as arguments.
+func TestRatingQueryPlaceholders(t *testing.T) {
+	const (
+		achievementUUID = "0195e3a2-7d4c-7c1e-9b0a-3f2d1c4b5a69"
+		orgUnitUUID     = "0195e3a2-7d4c-7c1e-9b0a-3f2d1c4b5a70"
+	)
+
+	for _, input := range hostileInputs {
+		t.Run(input, func(t *testing.T) {
+			q := &ratingQuery{}
+			ranked, err := q.rankedRating(
+				map[string]float64{achievementUUID: 10},
+				true,
+				[]string{orgUnitUUID},
+				models.RankingRules{Mode: input, TieBreakers: []string{input, models.TieBreakerAchievements}},
+			)
+			if err != nil {
+				t.Fatalf("rankedRating() = %v", err)
+			}
+
+			sql := ranked + " where " + q.ratingSearchCondition(input) + " " + ratingOrderBy(input, input)
+
+			placeholders := placeholderPattern.FindAllString(sql, -1)
+			if len(placeholders) != len(q.args) {
+				t.Errorf("%d placeholders for %d args", len(placeholders), len(q.args))
+			}
+			for i := range q.args {
+				if !slices.Contains(placeholders, "$"+strconv.Itoa(i+1)) {
+					t.Errorf("arg %d is not used in the query", i+1)
+				}
+			}
+
+			stripped := placeholderPattern.ReplaceAllString(sql, "")
+			for _, leaked := range []string{"drop table", "1=1", "--", achievementUUID, orgUnitUUID} {
+				if strings.Contains(stripped, leaked) {
+					t.Errorf("query text contains %q", leaked)
+				}
+			}
+			if input != "" && strings.Contains(stripped, input) {
+				t.Errorf("query text contains the input %q", input)
+			}
+		})
+	}
+}
+
+func TestRatingQueryRejectsInvalidUUIDs(t *testing.T) {
+	for _, input := range hostileInputs {
+		q := &ratingQuery{}
+		if _, err := q.rankedRating(map[string]float64{input: 1}, false, nil, models.RankingRules{}); !errors.Is(err, validation.WrongInputErr) {
+			t.Errorf("achievement uuid %q: err = %v, want WrongInputErr", input, err)
+		}
+
+		q = &ratingQuery{}
+		if _, err := q.rankedRating(nil, false, []string{input}, models.RankingRules{}); !errors.Is(err, validation.WrongInputErr) {
+			t.Errorf("org unit uuid %q: err = %v, want WrongInputErr", input, err)
+		}
+	}
+}
+
+func TestRatingRankOrder(t *testing.T) {
+	tests := []struct {
+		tieBreakers []string
+		want        string
+	}{
+		{nil, "order by point_amount desc"},
+		{[]string{models.TieBreakerAchievements}, "order by point_amount desc, achievement_amount desc"},
+		{[]string{models.TieBreakerEarliestSubmission}, "order by point_amount desc, last_submitted_at asc nulls last"},
+		{
+			[]string{models.TieBreakerEarliestSubmission, models.TieBreakerAchievements},
+			"order by point_amount desc, last_submitted_at asc nulls last, achievement_amount desc",
+		},
+		{[]string{"'; drop table sys_user; --", models.TieBreakerAchievements}, "order by point_amount desc, achievement_amount desc"},
+	}
+
+	for _, tt := range tests {
+		if got := ratingRankOrder(tt.tieBreakers); got != tt.want {
+			t.Errorf("ratingRankOrder(%q) = %q, want %q", tt.tieBreakers, got, tt.want)
+		}
+	}
+}
+
+func TestRatingRankFunction(t *testing.T) {
+	tests := map[string]string{
+		models.RankModeCompetition: "rank()",
+		models.RankModeDense:       "dense_rank()",
+		"":                         "rank()",
+		"row_number()":             "rank()",
+	}
+
+	for mode, want := range tests {
+		if got := ratingRankFunction(mode); got != want {
+			t.Errorf("ratingRankFunction(%q) = %q, want %q", mode, got, want)
+		}
+	}
+}
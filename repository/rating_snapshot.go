@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/avito-tech/go-transaction-manager/pgxv5"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5"
+	"strconv"
+	"time"
+)
+
+type RatingSnapshotRepository interface {
+	CreateRatingSnapshot(
+		ctx context.Context,
+		period models.Period,
+		statuses []string,
+		build func([]models.AchievementPoints) models.RatingSnapshot,
+		event models.AuditEvent,
+	) (models.RatingSnapshot, error)
+	ListRatingSnapshots(ctx context.Context, filter models.RatingSnapshotFilter, limit, offset int) ([]models.RatingSnapshot, int, error)
+	GetRatingSnapshot(ctx context.Context, uuid string) (models.RatingSnapshot, error)
+	GetCloseOutSnapshot(ctx context.Context, periodUUID string) (models.RatingSnapshot, error)
+}
+
+type ratingSnapshotRepository struct {
+	queries   *db.Queries
+	txCreator db.TxCreator
+}
+
+func NewRatingSnapshotRepository(pool pgxv5.Tr) RatingSnapshotRepository {
+	return &ratingSnapshotRepository{
+		queries:   db.New(pool),
+		txCreator: db.NewTxCreator(pool),
+	}
+}
+
+// CreateRatingSnapshot reads the achievement points and stores the snapshot
+// built from them in one transaction, so the snapshot matches the rules it was
+// computed with.
+func (r *ratingSnapshotRepository) CreateRatingSnapshot(
+	ctx context.Context,
+	period models.Period,
+	statuses []string,
+	build func([]models.AchievementPoints) models.RatingSnapshot,
+	event models.AuditEvent,
+) (models.RatingSnapshot, error) {
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	points, err := getPeriodAchievementPoints(ctx, qtx, period, statuses)
+	if err != nil {
+		return models.RatingSnapshot{}, err
+	}
+
+	snapshot, err := createRatingSnapshot(ctx, qtx, build(points))
+	if err != nil {
+		return models.RatingSnapshot{}, err
+	}
+
+	event.EntityID = snapshot.UUID
+	if err = createAuditEvent(ctx, qtx, event); err != nil {
+		return models.RatingSnapshot{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return snapshot, nil
+}
+
+func (r *ratingSnapshotRepository) ListRatingSnapshots(ctx context.Context, filter models.RatingSnapshotFilter, limit, offset int) ([]models.RatingSnapshot, int, error) {
+	args := db.ListRatingSnapshotsParams{
+		RowLimit:  int32(limit),
+		RowOffset: int32(offset),
+	}
+
+	var err error
+	if filter.PeriodUUID != "" {
+		if args.PeriodUuid, err = ParseToPgUUID(filter.PeriodUUID); err != nil {
+			return nil, 0, errors.Join(err, parsing.InputDataErr)
+		}
+	}
+
+	dbSnapshots, err := r.queries.ListRatingSnapshots(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.RatingSnapshot{}, 0, nil
+		}
+		return nil, 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	snapshots := make([]models.RatingSnapshot, len(dbSnapshots))
+	totalRecords := 0
+	for i, dbSnapshot := range dbSnapshots {
+		totalRecords = int(dbSnapshot.TotalRecords)
+		snapshots[i] = ratingSnapshotFromDB(db.GetRatingSnapshotRow{
+			Uuid:        dbSnapshot.Uuid,
+			PeriodUuid:  dbSnapshot.PeriodUuid,
+			PeriodName:  dbSnapshot.PeriodName,
+			Reason:      dbSnapshot.Reason,
+			CreatedBy:   dbSnapshot.CreatedBy,
+			RuleVersion: dbSnapshot.RuleVersion,
+			CreatedAt:   dbSnapshot.CreatedAt,
+			EntryAmount: dbSnapshot.EntryAmount,
+		})
+	}
+
+	return snapshots, totalRecords, nil
+}
+
+func (r *ratingSnapshotRepository) GetRatingSnapshot(ctx context.Context, uuid string) (models.RatingSnapshot, error) {
+	pgUUID, err := ParseToPgUUID(uuid)
+	if err != nil {
+		return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbSnapshot, err := r.queries.GetRatingSnapshot(ctx, pgUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.RatingSnapshot{}, validation.NoDataFoundErr
+		}
+		return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+	}
+	snapshot := ratingSnapshotFromDB(dbSnapshot)
+
+	dbAchievements, err := r.queries.GetRatingSnapshotAchievements(ctx, pgUUID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	achievements := map[string][]models.AchievementPoints{}
+	for _, dbAchievement := range dbAchievements {
+		pointAmount, err := dbAchievement.PointAmount.Float64Value()
+		if err != nil {
+			return models.RatingSnapshot{}, errors.Join(err, parsing.OutputDataErr)
+		}
+
+		userUUID := dbAchievement.UserUuid.String()
+		achievements[userUUID] = append(achievements[userUUID], models.AchievementPoints{
+			AchievementUUID: dbAchievement.AchievementUuid.String(),
+			UserUUID:        userUUID,
+			CategoryUUID:    formatPgUUID(dbAchievement.CategoryUuid),
+			CategoryName:    dbAchievement.CategoryName,
+			PointAmount:     pointAmount.Float64,
+		})
+	}
+
+	dbEntries, err := r.queries.GetRatingSnapshotEntries(ctx, pgUUID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	snapshot.Entries = make([]models.RatingSnapshotEntry, len(dbEntries))
+	for i, dbEntry := range dbEntries {
+		pointAmount, err := dbEntry.PointAmount.Float64Value()
+		if err != nil {
+			return models.RatingSnapshot{}, errors.Join(err, parsing.OutputDataErr)
+		}
+
+		userUUID := dbEntry.UserUuid.String()
+		snapshot.Entries[i] = models.RatingSnapshotEntry{
+			UserUUID:          userUUID,
+			FullName:          dbEntry.FullName,
+			GradebookNumber:   dbEntry.GradebookNumber,
+			Position:          int(dbEntry.Position),
+			PointAmount:       pointAmount.Float64,
+			AchievementAmount: int(dbEntry.AchievementAmount),
+			IsWinner:          dbEntry.IsWinner,
+			Achievements:      achievements[userUUID],
+		}
+	}
+
+	return snapshot, nil
+}
+
+// GetCloseOutSnapshot returns the snapshot taken when the period was closed.
+func (r *ratingSnapshotRepository) GetCloseOutSnapshot(ctx context.Context, periodUUID string) (models.RatingSnapshot, error) {
+	pgPeriodUUID, err := ParseToPgUUID(periodUUID)
+	if err != nil {
+		return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+	}
+
+	snapshotUUID, err := r.queries.GetCloseOutRatingSnapshotUUID(ctx, pgPeriodUUID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.RatingSnapshot{}, validation.NoDataFoundErr
+		}
+		return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return r.GetRatingSnapshot(ctx, snapshotUUID.String())
+}
+
+// createRatingSnapshot must be called with the transaction queries of the
+// operation the snapshot is taken for.
+func createRatingSnapshot(ctx context.Context, qtx *db.Queries, snapshot models.RatingSnapshot) (models.RatingSnapshot, error) {
+	ruleVersion, err := qtx.GetRatingRuleVersion(ctx, snapshot.WinnerRules)
+	if err != nil {
+		return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	args := db.CreateRatingSnapshotParams{
+		Reason:      snapshot.Reason,
+		RuleVersion: ruleVersion,
+	}
+
+	if snapshot.PeriodUUID != "" {
+		if args.PeriodUuid, err = ParseToPgUUID(snapshot.PeriodUUID); err != nil {
+			return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+		}
+	}
+
+	if snapshot.CreatedBy != "" {
+		if args.CreatedBy, err = ParseToPgUUID(snapshot.CreatedBy); err != nil {
+			return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+		}
+	}
+
+	created, err := qtx.CreateRatingSnapshot(ctx, args)
+	if err != nil {
+		return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+	}
+
+	snapshot.UUID = created.Uuid.String()
+	snapshot.RuleVersion = ruleVersion
+	snapshot.CreatedAt = created.CreatedAt.Time.Format(time.RFC3339)
+	snapshot.EntryAmount = len(snapshot.Entries)
+
+	for _, entry := range snapshot.Entries {
+		pgUserUUID, err := ParseToPgUUID(entry.UserUUID)
+		if err != nil {
+			return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+		}
+
+		pgPointAmount, err := ParseToPgNumeric(strconv.FormatFloat(entry.PointAmount, 'f', -1, 64))
+		if err != nil {
+			return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+		}
+
+		err = qtx.CreateRatingSnapshotEntry(ctx, db.CreateRatingSnapshotEntryParams{
+			SnapshotUuid:      created.Uuid,
+			UserUuid:          pgUserUUID,
+			Position:          int32(entry.Position),
+			PointAmount:       pgPointAmount,
+			AchievementAmount: int32(entry.AchievementAmount),
+			IsWinner:          entry.IsWinner,
+			FullName:          entry.FullName,
+			GradebookNumber:   entry.GradebookNumber,
+		})
+		if err != nil {
+			return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+		}
+
+		for _, achievement := range entry.Achievements {
+			args := db.CreateRatingSnapshotAchievementParams{
+				SnapshotUuid: created.Uuid,
+				UserUuid:     pgUserUUID,
+				CategoryName: achievement.CategoryName,
+			}
+
+			if args.AchievementUuid, err = ParseToPgUUID(achievement.AchievementUUID); err != nil {
+				return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+			}
+
+			if achievement.CategoryUUID != "" {
+				if args.CategoryUuid, err = ParseToPgUUID(achievement.CategoryUUID); err != nil {
+					return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+				}
+			}
+
+			if args.PointAmount, err = ParseToPgNumeric(strconv.FormatFloat(achievement.PointAmount, 'f', -1, 64)); err != nil {
+				return models.RatingSnapshot{}, errors.Join(err, validation.WrongInputErr)
+			}
+
+			if err = qtx.CreateRatingSnapshotAchievement(ctx, args); err != nil {
+				return models.RatingSnapshot{}, errors.Join(err, unexpected.RequestErr)
+			}
+		}
+	}
+
+	return snapshot, nil
+}
+
+func ratingSnapshotFromDB(dbSnapshot db.GetRatingSnapshotRow) models.RatingSnapshot {
+	return models.RatingSnapshot{
+		UUID:        dbSnapshot.Uuid.String(),
+		PeriodUUID:  formatPgUUID(dbSnapshot.PeriodUuid),
+		PeriodName:  dbSnapshot.PeriodName.String,
+		Reason:      dbSnapshot.Reason,
+		CreatedBy:   formatPgUUID(dbSnapshot.CreatedBy),
+		RuleVersion: dbSnapshot.RuleVersion,
+		CreatedAt:   dbSnapshot.CreatedAt.Time.Format(time.RFC3339),
+		EntryAmount: int(dbSnapshot.EntryAmount),
+	}
+}
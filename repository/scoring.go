@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/avito-tech/go-transaction-manager/pgxv5"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ScoringRepository interface {
+	GetAchievementScoreInputs(ctx context.Context, filter models.ScoringFilter) ([]models.AchievementScoreInput, error)
+	RebuildStudentScores(ctx context.Context) (int, error)
+	HasStudentScores(ctx context.Context) (bool, error)
+}
+
+type scoringRepository struct {
+	queries   *db.Queries
+	txCreator db.TxCreator
+}
+
+func NewScoringRepository(pool pgxv5.Tr) ScoringRepository {
+	return &scoringRepository{
+		queries:   db.New(pool),
+		txCreator: db.NewTxCreator(pool),
+	}
+}
+
+func (r *scoringRepository) GetAchievementScoreInputs(ctx context.Context, filter models.ScoringFilter) ([]models.AchievementScoreInput, error) {
+	return getAchievementScoreInputs(ctx, r.queries, filter)
+}
+
+func (r *scoringRepository) RebuildStudentScores(ctx context.Context) (int, error) {
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	total, err := rebuildStudentScores(ctx, qtx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return total, nil
+}
+
+func (r *scoringRepository) HasStudentScores(ctx context.Context) (bool, error) {
+	exists, err := r.queries.HasStudentScores(ctx)
+	if err != nil {
+		return false, errors.Join(err, unexpected.RequestErr)
+	}
+
+	return exists, nil
+}
+
+// getAchievementScoreInputs loads the raw score components of the selected
+// achievements. Points are never computed here, that is up to the scoring
+// service.
+func getAchievementScoreInputs(ctx context.Context, queries *db.Queries, filter models.ScoringFilter) ([]models.AchievementScoreInput, error) {
+	args := db.GetAchievementScoreInputsParams{
+		Statuses:  filter.Statuses,
+		StartDate: pgtype.Date{Time: filter.StartDate, Valid: !filter.StartDate.IsZero()},
+		EndDate:   pgtype.Date{Time: filter.EndDate, Valid: !filter.EndDate.IsZero()},
+	}
+
+	var err error
+	if args.AchievementUuids, err = parsePgUUIDs(filter.AchievementUUIDs); err != nil {
+		return nil, errors.Join(err, validation.WrongInputErr)
+	}
+
+	if args.UserUuids, err = parsePgUUIDs(filter.UserUUIDs); err != nil {
+		return nil, errors.Join(err, validation.WrongInputErr)
+	}
+
+	dbInputs, err := queries.GetAchievementScoreInputs(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.AchievementScoreInput{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	inputs := make([]models.AchievementScoreInput, 0)
+	for _, dbInput := range dbInputs {
+		achievementUUID := dbInput.AchievementUuid.String()
+		if len(inputs) == 0 || inputs[len(inputs)-1].AchievementUUID != achievementUUID {
+			categoryPoints, err := dbInput.CategoryPoints.Float64Value()
+			if err != nil {
+				return nil, errors.Join(err, parsing.OutputDataErr)
+			}
+
+			categoryRules, err := CategoryScoringRulesFromDB(dbInput.CategoryMaxPoints, dbInput.CategoryMaxAchievements,
+				dbInput.CategoryMultiplier, dbInput.CategoryDiminishingFactor)
+			if err != nil {
+				return nil, err
+			}
+
+			inputs = append(inputs, models.AchievementScoreInput{
+				AchievementUUID: achievementUUID,
+				UserUUID:        formatPgUUID(dbInput.UserUuid),
+				Status:          dbInput.AchievementStatus.String,
+				AchievementDate: dbInput.AchievementDate.Time,
+				SubmittedAt:     dbInput.SubmittedAt.Time,
+				CategoryUUID:    dbInput.CategoryUuid.String(),
+				CategoryName:    dbInput.CategoryName,
+				CategoryPoints:  categoryPoints.Float64,
+				CategoryActive:  dbInput.CategoryActive,
+				CategoryRules:   categoryRules,
+			})
+		}
+
+		if !dbInput.ValueUuid.Valid {
+			continue
+		}
+
+		valuePoints, err := dbInput.ValuePoints.Float64Value()
+		if err != nil {
+			return nil, errors.Join(err, parsing.OutputDataErr)
+		}
+
+		input := &inputs[len(inputs)-1]
+		input.Values = append(input.Values, models.ScoreValueInput{
+			ValueUUID:       dbInput.ValueUuid.String(),
+			ValueName:       dbInput.ValueName.String,
+			SubcategoryUUID: formatPgUUID(dbInput.SubcategoryUuid),
+			Points:          valuePoints.Float64,
+			Counted:         dbInput.ValueCounted,
+		})
+	}
+
+	return inputs, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/avito-tech/go-transaction-manager/pgxv5"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type SettingRepository interface {
+	ListSettings(ctx context.Context) ([]models.Setting, error)
+	// UpdateSetting locks the stored value of the setting, stores the new one
+	// and records the change in its history. change.OldValue is the default,
+	// it is taken as the old value of a setting that was never stored. The
+	// audit event is built from the locked old value.
+	UpdateSetting(ctx context.Context, change models.SettingChange, newEvent func(oldValue string) models.AuditEvent) error
+	ListSettingChanges(ctx context.Context, key string, limit, offset int) ([]models.SettingChange, int, error)
+}
+
+type settingRepository struct {
+	queries   *db.Queries
+	txCreator db.TxCreator
+}
+
+func NewSettingRepository(pool pgxv5.Tr) SettingRepository {
+	return &settingRepository{
+		queries:   db.New(pool),
+		txCreator: db.NewTxCreator(pool),
+	}
+}
+
+func (r *settingRepository) ListSettings(ctx context.Context) ([]models.Setting, error) {
+	dbSettings, err := r.queries.ListSettings(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.Setting{}, nil
+		}
+		return nil, errors.Join(err, unexpected.RequestErr)
+	}
+
+	settings := make([]models.Setting, len(dbSettings))
+	for i, dbSetting := range dbSettings {
+		settings[i] = models.Setting{
+			Key:       dbSetting.Name,
+			Value:     dbSetting.Value,
+			UpdatedAt: dbSetting.UpdatedAt.Time.Format(time.RFC3339),
+			UpdatedBy: formatPgUUID(dbSetting.UpdatedBy),
+		}
+	}
+
+	return settings, nil
+}
+
+func (r *settingRepository) UpdateSetting(
+	ctx context.Context,
+	change models.SettingChange,
+	newEvent func(oldValue string) models.AuditEvent,
+) error {
+	changedBy, err := parseOptionalPgUUID(change.ChangedBy)
+	if err != nil {
+		return errors.Join(err, parsing.InputDataErr)
+	}
+
+	tx, err := r.txCreator.CreateTx(ctx)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	// Concurrent changes of the setting wait for each other here, so every
+	// change records the value it replaced.
+	err = qtx.CreateMissingSetting(ctx, db.CreateMissingSettingParams{
+		Name:  change.Key,
+		Value: change.OldValue,
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	if change.OldValue, err = qtx.GetSettingValueForUpdate(ctx, change.Key); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	err = qtx.UpsertSetting(ctx, db.UpsertSettingParams{
+		Name:      change.Key,
+		Value:     change.NewValue,
+		UpdatedBy: changedBy,
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	err = qtx.CreateSettingChange(ctx, db.CreateSettingChangeParams{
+		Name:      change.Key,
+		OldValue:  change.OldValue,
+		NewValue:  change.NewValue,
+		ChangedBy: changedBy,
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	if err = createAuditEvent(ctx, qtx, newEvent(change.OldValue)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+	return nil
+}
+
+func (r *settingRepository) ListSettingChanges(ctx context.Context, key string, limit, offset int) ([]models.SettingChange, int, error) {
+	dbChanges, err := r.queries.ListSettingChanges(ctx, db.ListSettingChangesParams{
+		Name:      key,
+		RowLimit:  int32(limit),
+		RowOffset: int32(offset),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.SettingChange{}, 0, nil
+		}
+		return nil, 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	changes := make([]models.SettingChange, len(dbChanges))
+	totalRecords := 0
+	for i, dbChange := range dbChanges {
+		totalRecords = int(dbChange.TotalRecords)
+		changes[i] = models.SettingChange{
+			UUID:      dbChange.Uuid.String(),
+			Key:       dbChange.Name,
+			OldValue:  dbChange.OldValue,
+			NewValue:  dbChange.NewValue,
+			ChangedBy: formatPgUUID(dbChange.ChangedBy),
+			ChangedAt: dbChange.ChangedAt.Time.Format(time.RFC3339),
+		}
+	}
+
+	return changes, totalRecords, nil
+}
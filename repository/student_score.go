@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/parsing"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/scoring"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strconv"
+)
+
+// refreshAchievementScores recomputes the stored scores of the students owning
+// the achievements. It must be called with the transaction queries of the
+// change, after the change, so the scores are committed together with it.
+func refreshAchievementScores(ctx context.Context, qtx *db.Queries, achievementUUIDs ...pgtype.UUID) error {
+	dbUserUUIDs, err := qtx.GetAchievementUserUUIDs(ctx, achievementUUIDs)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	userUUIDs := make([]string, len(dbUserUUIDs))
+	for i, dbUserUUID := range dbUserUUIDs {
+		userUUIDs[i] = dbUserUUID.String()
+	}
+
+	return refreshStudentScores(ctx, qtx, userUUIDs)
+}
+
+// refreshStudentScores recomputes the stored scores of the students. Students
+// left without counted achievements get an empty score.
+func refreshStudentScores(ctx context.Context, qtx *db.Queries, userUUIDs []string) error {
+	if len(userUUIDs) == 0 {
+		return nil
+	}
+
+	pgUserUUIDs, err := parsePgUUIDs(userUUIDs)
+	if err != nil {
+		return errors.Join(err, parsing.InputDataErr)
+	}
+
+	if err = qtx.ShareStudentScoresLock(ctx); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	// Without the lock two concurrent changes of one student could each miss
+	// the other and the last one to commit would store a stale score.
+	if err = qtx.LockStudentScores(ctx, pgUserUUIDs); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	inputs, err := getAchievementScoreInputs(ctx, qtx, models.ScoringFilter{
+		UserUUIDs: userUUIDs,
+		Statuses:  models.LiveRatingStatuses,
+	})
+	if err != nil {
+		return err
+	}
+
+	students := scoring.StudentScores(inputs)
+	for _, userUUID := range userUUIDs {
+		if _, ok := students[userUUID]; !ok {
+			students[userUUID] = models.StudentScore{UserUUID: userUUID, AchievementProofed: true}
+		}
+	}
+
+	return saveStudentScores(ctx, qtx, students)
+}
+
+// refreshCategoryScores recomputes the stored scores of the students with
+// achievements depending on the category. It must be called after the change
+// of the category, with its transaction queries.
+func refreshCategoryScores(ctx context.Context, qtx *db.Queries, categoryUUID pgtype.UUID) error {
+	dbUserUUIDs, err := qtx.GetCategoryUserUUIDs(ctx, categoryUUID)
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	userUUIDs := make([]string, len(dbUserUUIDs))
+	for i, dbUserUUID := range dbUserUUIDs {
+		userUUIDs[i] = dbUserUUID.String()
+	}
+
+	return refreshStudentScores(ctx, qtx, userUUIDs)
+}
+
+// rebuildStudentScores replaces the stored scores of all students. It waits
+// for the running refreshes and holds off new ones until the transaction ends.
+func rebuildStudentScores(ctx context.Context, qtx *db.Queries) (int, error) {
+	if err := qtx.LockAllStudentScores(ctx); err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	if err := qtx.DeleteStudentScores(ctx); err != nil {
+		return 0, errors.Join(err, unexpected.RequestErr)
+	}
+
+	inputs, err := getAchievementScoreInputs(ctx, qtx, models.ScoringFilter{Statuses: models.LiveRatingStatuses})
+	if err != nil {
+		return 0, err
+	}
+
+	students := scoring.StudentScores(inputs)
+	if err = saveStudentScores(ctx, qtx, students); err != nil {
+		return 0, err
+	}
+
+	return len(students), nil
+}
+
+func saveStudentScores(ctx context.Context, qtx *db.Queries, students map[string]models.StudentScore) error {
+	args := make([]db.UpsertStudentScoresParams, 0, len(students))
+	for _, student := range students {
+		pgUserUUID, err := ParseToPgUUID(student.UserUUID)
+		if err != nil {
+			return errors.Join(err, parsing.InputDataErr)
+		}
+
+		pgPointAmount, err := ParseToPgNumeric(strconv.FormatFloat(student.PointAmount, 'f', -1, 64))
+		if err != nil {
+			return errors.Join(err, parsing.InputDataErr)
+		}
+
+		args = append(args, db.UpsertStudentScoresParams{
+			UserUuid:           pgUserUUID,
+			PointAmount:        pgPointAmount,
+			AchievementAmount:  int32(student.AchievementAmount),
+			AchievementProofed: student.AchievementProofed,
+			LastSubmittedAt: pgtype.Timestamptz{
+				Time:  student.LastSubmittedAt,
+				Valid: !student.LastSubmittedAt.IsZero(),
+			},
+		})
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	var err error
+	qtx.UpsertStudentScores(ctx, args).Exec(func(_ int, errL error) {
+		if errL != nil {
+			err = errors.Join(err, errL)
+		}
+	})
+	if err != nil {
+		return errors.Join(err, unexpected.RequestErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/scoring"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"os"
+	"testing"
+)
+
+// The benchmarks compare the rebuild of all scores, which category changes
+// used to run, with the refresh of the students of the changed category, and
+// the all-time rating read from the stored scores with the aggregation it
+// replaced. They need a migrated database with data, e.g. a copy of
+// production:
+//
+//	SPS_TEST_DATABASE_URL=postgres://... go test ./repository/ -run '^$' -bench 'StudentScores|Rating'
+//
+// Every iteration that writes runs in a transaction that is rolled back.
+
+func benchmarkPool(b *testing.B) *pgxpool.Pool {
+	uri := os.Getenv("SPS_TEST_DATABASE_URL")
+	if uri == "" {
+		b.Skip("SPS_TEST_DATABASE_URL is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), uri)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(pool.Close)
+
+	return pool
+}
+
+func BenchmarkRebuildStudentScores(b *testing.B) {
+	ctx := context.Background()
+	pool := benchmarkPool(b)
+	queries := db.New(pool)
+
+	for b.Loop() {
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err = rebuildStudentScores(ctx, queries.WithTx(tx)); err != nil {
+			b.Fatal(err)
+		}
+
+		if err = tx.Rollback(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRefreshCategoryScores refreshes the root category with the most
+// achievements, the worst case of a category change.
+func BenchmarkRefreshCategoryScores(b *testing.B) {
+	ctx := context.Background()
+	pool := benchmarkPool(b)
+	queries := db.New(pool)
+
+	var categoryUUID pgtype.UUID
+	err := pool.QueryRow(ctx, `select ac.category_uuid
+                               from achievement_category ac
+                                        join category c on c.uuid = ac.category_uuid and c.parent_category is null
+                               group by ac.category_uuid
+                               order by count(*) desc
+                               limit 1`).Scan(&categoryUUID)
+	if err != nil {
+		b.Skipf("no category with achievements: %v", err)
+	}
+
+	for b.Loop() {
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if err = refreshCategoryScores(ctx, queries.WithTx(tx), categoryUUID); err != nil {
+			b.Fatal(err)
+		}
+
+		if err = tx.Rollback(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkRatingFilter is the filter of the default all-time rating.
+var benchmarkRatingFilter = models.RatingFilter{
+	Ranking:   models.RankingRules{Mode: models.RankModeCompetition},
+	SortBy:    models.RatingSortPoints,
+	SortOrder: models.SortOrderDesc,
+}
+
+func BenchmarkRatingStudentScores(b *testing.B) {
+	ctx := context.Background()
+	users := NewUserRepository(benchmarkPool(b))
+
+	for b.Loop() {
+		if _, _, err := users.GetRating(ctx, benchmarkRatingFilter); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRatingAggregation ranks the same students the way the all-time
+// rating did before the scores were stored: every live achievement is read
+// with its categories and values, scored and summed up on each request.
+func BenchmarkRatingAggregation(b *testing.B) {
+	ctx := context.Background()
+	pool := benchmarkPool(b)
+	queries := db.New(pool)
+	users := NewUserRepository(pool)
+
+	for b.Loop() {
+		inputs, err := getAchievementScoreInputs(ctx, queries, models.ScoringFilter{Statuses: models.LiveRatingStatuses})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		filter := benchmarkRatingFilter
+		filter.Scores = scoring.ScoreRating(inputs)
+
+		if _, _, err = users.GetRating(ctx, filter); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/avito-tech/go-transaction-manager/pgxv5"
@@ -15,6 +14,7 @@ import (
 	"github.com/igntnk/scholarship_point_system/errors/validation"
 	"github.com/igntnk/scholarship_point_system/service/models"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"time"
 )
 
@@ -27,8 +27,11 @@ type UserRepository interface {
 	UpdateUserWithoutGradeBook(ctx context.Context, args db.UpdateUserInfoWithoutGradeBookParams) error
 	UpdateUserWithGradeBook(ctx context.Context, args db.UpdateUserInfoWithGradeBookParams) error
 	GetUserWithCredentialsByEmail(ctx context.Context, email string) (models.UserWithCredentials, error)
-	GetRating(ctx context.Context, searchWords []string, Valid bool, Winners bool, Limit int, Offset int) ([]responses.User, int, error)
-	GetShortInfoRating(ctx context.Context, userUUID string) (responses.RatingShortInfo, error)
+	GetRating(ctx context.Context, filter models.RatingFilter) ([]responses.User, int, error)
+	// ForEachRatingUser calls fn for every student of the rating in order
+	// while reading the rows, so the rating is never held in memory.
+	ForEachRatingUser(ctx context.Context, filter models.RatingFilter, fn func(user responses.User, totalRecords int) error) error
+	GetShortInfoRating(ctx context.Context, userUUID string, filter models.RatingFilter) (responses.RatingShortInfo, error)
 	ApproveUser(context *gin.Context, uuid string) error
 	DeclineUser(context *gin.Context, uuid string) error
 }
@@ -187,19 +190,46 @@ func (r *userRepository) GetUserWithCredentialsByEmail(ctx context.Context, emai
 	}, nil
 }
 
-func (r *userRepository) GetRating(
+// GetRating builds the rating query from fixed fragments. Search text, scores,
+// winner count and pagination are passed as bound parameters, sorting and
+// ranking are limited to whitelisted columns.
+func (r *userRepository) GetRating(ctx context.Context, filter models.RatingFilter) ([]responses.User, int, error) {
+	users := make([]responses.User, 0)
+	totalAmount := 0
+	err := r.ForEachRatingUser(ctx, filter, func(user responses.User, totalRecords int) error {
+		users = append(users, user)
+		totalAmount = totalRecords
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, totalAmount, nil
+}
+
+func (r *userRepository) ForEachRatingUser(
 	ctx context.Context,
-	searchWords []string,
-	Valid bool,
-	Winners bool,
-	Limit int,
-	Offset int,
-) (
-	[]responses.User,
-	int,
-	error,
-) {
-	request := `
+	filter models.RatingFilter,
+	fn func(user responses.User, totalRecords int) error,
+) error {
+	q := &ratingQuery{}
+
+	request, err := q.rankedFor(filter)
+	if err != nil {
+		return err
+	}
+
+	where := q.ratingSearchCondition(filter.Search)
+	if filter.Winners {
+		pgWinnerUUIDs, err := parsePgUUIDs(filter.WinnerUUIDs)
+		if err != nil {
+			return errors.Join(err, validation.WrongInputErr)
+		}
+		where = fmt.Sprintf("%s and uuid = any (%s::uuid[])", where, q.arg(pgWinnerUUIDs))
+	}
+
+	request = fmt.Sprintf(`%s
 select uuid,
        name,
        second_name,
@@ -208,136 +238,51 @@ select uuid,
        birth_date,
        phone_number,
        email,
+       org_unit_uuid,
        user_status,
        point_amount,
        achievement_amount,
        count(*) over () as total_amount,
-       achievement_proofed
-from (select u.uuid,
-             u.name,
-             second_name,
-             patronymic,
-             gradebook_number,
-             birth_date,
-             phone_number,
-             email,
-             u_s.display_value                                           as user_status,
-             count(distinct a.uuid)                                      as achievement_amount,
-             coalesce(sum(cv.point) + sum(p_c.point_amount), 0)::numeric as point_amount,
-             not bool_or(a_s.internal_value = 'unapproved')              as achievement_proofed
-      from sys_user u
-               join status u_s on u_s.uuid = u.status_uuid
-               left join achievement a on a.user_uuid = u.uuid
-               left join status a_s on a_s.uuid = a.status_uuid and a_s.type = 'achievement_status'
-               left join achievement_category ac on ac.achievement_uuid = a.uuid
-               left join category p_c
-                         on p_c.uuid = ac.category_uuid and p_c.parent_category is null and
-                            p_c.status_uuid in (select uuid
-                                                from status
-                                                where type = 'category_status'
-                                                  and internal_value = 'active')
-               left join category c_c
-                         on c_c.uuid = ac.category_uuid and c_c.parent_category is not null and
-                            c_c.status_uuid in (select uuid
-                                                from status
-                                                where type = 'category_status'
-                                                  and internal_value = 'active')
-               left join achievement_category_value acv on acv.achievement_uuid = a.uuid
-               left join category_value cv on cv.uuid = acv.category_value_uuid and cv.category_uuid = c_c.uuid and
-                                              cv.status_uuid != (select s.uuid
-                                                                 from status s
-                                                                 where type = 'category_value_status'
-                                                                   and internal_value = 'unactive')
-      where ((a_s.internal_value != 'removed' and a_s.internal_value != 'declined') or a_s.internal_value is null) %s
-      group by u.uuid, u.name, second_name, patronymic, gradebook_number, birth_date, phone_number, email,
-               u_s.display_value,
-               c_c.point_amount
-      %s) as sub
-where 1 = 1
-`
-
-	searchTemplate := ""
-	for i, searchWord := range searchWords {
-		if searchWord == "" {
-			continue
-		}
-
-		if i == 0 {
-			searchTemplate = fmt.Sprintf("(%%s ilike '%%%%%s%%%%'", searchWord)
-		} else {
-			searchTemplate = fmt.Sprintf("%s or %%s ilike '%%%%%s%%%%'", searchTemplate, searchWord)
-		}
-
-		if i == len(searchWords)-1 {
-			searchTemplate = fmt.Sprintf("%s)", searchTemplate)
-		}
-	}
-
-	searchStmt := ""
-	if searchTemplate != "" {
-		searchStmt = fmt.Sprintf("and (%s or %s or %s or %s or %s or %s or %s)",
-			searchTemplate,
-			searchTemplate,
-			searchTemplate,
-			searchTemplate,
-			searchTemplate,
-			searchTemplate,
-			searchTemplate,
-		)
-		searchStmt = fmt.Sprintf(searchStmt, "u.name",
-			"second_name",
-			"patronymic",
-			"gradebook_number",
-			"u_s.display_value",
-			"phone_number",
-			"email",
-		)
+       achievement_proofed,
+       rank
+from ranked
+where %s
+%s`,
+		request,
+		where,
+		ratingOrderBy(filter.SortBy, filter.SortOrder),
+	)
+
+	if filter.Limit != 0 {
+		request = fmt.Sprintf("%s limit %s offset %s", request, q.arg(filter.Limit), q.arg(filter.Offset))
 	}
 
-	if Valid {
-		searchStmt = fmt.Sprintf("%s and u_s.display_value = 'Подтвержденный'", searchStmt)
-	}
-
-	limitStmt := ""
-	if Winners {
-		if !Valid {
-			searchStmt = fmt.Sprintf("%s and u_s.display_value = 'Подтвержденный'", searchStmt)
-		}
-		limitStmt = "limit (select value::bigint from constants where name = 'available_student_grades')"
-	}
-
-	request = fmt.Sprintf(request, searchStmt, limitStmt)
-
-	request = fmt.Sprintf("%s order by point_amount", request)
-
-	if Limit != 0 {
-		request = fmt.Sprintf("%s limit %d offset %d", request, Limit, Offset)
-	}
-
-	rows, err := r.querier.Exec(ctx, request)
+	rows, err := r.querier.Exec(ctx, request, q.args...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, 0, validation.NoDataFoundErr
+			return validation.NoDataFoundErr
 		}
-		return nil, 0, errors.Join(err, unexpected.RequestErr)
+		return errors.Join(err, unexpected.RequestErr)
 	}
+	defer rows.Close()
 
-	users := make([]responses.User, 0)
-	TotalAmount := 0
 	for rows.Next() {
 		var (
-			name                   sql.NullString
-			secondName             sql.NullString
-			uuid                   string
-			patronymic             sql.NullString
-			phoneNumber            sql.NullString
+			uuid                   pgtype.UUID
+			name                   string
+			secondName             string
+			patronymic             pgtype.Text
 			gradebookNumber        string
-			status                 string
-			birthDate              sql.NullString
-			email                  sql.NullString
-			allAchievementVerified sql.NullBool
+			birthDate              pgtype.Date
+			phoneNumber            pgtype.Text
+			email                  pgtype.Text
+			orgUnitUUID            pgtype.UUID
+			status                 pgtype.Text
 			pointAmount            float64
 			achievementAmount      int
+			allAchievementVerified bool
+			rank                   int
+			totalAmount            int
 		)
 		if err = rows.Scan(
 			&uuid,
@@ -348,63 +293,106 @@ where 1 = 1
 			&birthDate,
 			&phoneNumber,
 			&email,
+			&orgUnitUUID,
 			&status,
 			&pointAmount,
 			&achievementAmount,
-			&TotalAmount,
+			&totalAmount,
 			&allAchievementVerified,
+			&rank,
 		); err != nil {
-			return nil, 0, errors.Join(err, unexpected.RequestErr)
+			return errors.Join(err, unexpected.RequestErr)
 		}
 
 		user := responses.User{
-			UUID:                   uuid,
-			Name:                   name.String,
-			SecondName:             secondName.String,
+			UUID:                   uuid.String(),
+			Name:                   name,
+			SecondName:             secondName,
 			Patronymic:             patronymic.String,
-			BirthDate:              birthDate.String,
 			PhoneNumber:            phoneNumber.String,
 			GradebookNumber:        gradebookNumber,
 			Email:                  email.String,
+			OrgUnitUUID:            formatPgUUID(orgUnitUUID),
 			PointsAmount:           pointAmount,
 			AchievementAmount:      achievementAmount,
-			Valid:                  status == "Подтвержденный",
-			AllAchievementVerified: allAchievementVerified.Bool,
+			Valid:                  status.String == "approved",
+			AllAchievementVerified: allAchievementVerified,
+			Rank:                   rank,
+		}
+		if birthDate.Valid {
+			user.BirthDate = birthDate.Time.Format(time.RFC3339)
 		}
 
-		users = append(users, user)
+		if err = fn(user, totalAmount); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return errors.Join(err, unexpected.RequestErr)
 	}
 
-	return users, TotalAmount, nil
+	return nil
 }
 
-func (r *userRepository) GetShortInfoRating(ctx context.Context, userUUID string) (responses.RatingShortInfo, error) {
+// GetShortInfoRating returns the rank and points of the user together with the
+// points of the leader among the students GetRating selects with the filter.
+// Search, winners, sorting and pagination of the filter do not apply.
+func (r *userRepository) GetShortInfoRating(ctx context.Context, userUUID string, filter models.RatingFilter) (responses.RatingShortInfo, error) {
 	pgUUID, err := ParseToPgUUID(userUUID)
 	if err != nil {
 		return responses.RatingShortInfo{}, errors.Join(err, parsing.InputDataErr)
 	}
 
-	dbShortInfo, err := r.queries.GetShortRatingInfo(ctx, pgUUID)
+	q := &ratingQuery{}
+	ranked, err := q.rankedFor(filter)
+	if err != nil {
+		return responses.RatingShortInfo{}, err
+	}
+
+	request := fmt.Sprintf(`%s
+select uuid, rank, point_amount
+from ranked
+where uuid = %s
+   or rank = 1
+order by rank, uuid`,
+		ranked,
+		q.arg(pgUUID),
+	)
+
+	rows, err := r.querier.Exec(ctx, request, q.args...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return responses.RatingShortInfo{}, validation.NoDataFoundErr
-		}
 		return responses.RatingShortInfo{}, errors.Join(err, unexpected.RequestErr)
 	}
+	defer rows.Close()
 
 	result := responses.RatingShortInfo{}
-	for i, info := range dbShortInfo {
+	found := false
+	for i := 0; rows.Next(); i++ {
+		var (
+			uuid        pgtype.UUID
+			rank        int
+			pointAmount float64
+		)
+		if err = rows.Scan(&uuid, &rank, &pointAmount); err != nil {
+			return responses.RatingShortInfo{}, errors.Join(err, unexpected.RequestErr)
+		}
+
 		if i == 0 {
-			result.LeaderPoints = float64(info.PointAmount)
-			if len(dbShortInfo)-1 == 0 {
-				result.CurrentPoints = float64(info.PointAmount)
-				result.CurrentPosition = int(info.Position)
-			}
-			continue
+			result.LeaderPoints = pointAmount
+		}
+
+		if uuid == pgUUID {
+			found = true
+			result.CurrentPoints = pointAmount
+			result.CurrentPosition = rank
 		}
+	}
+	if err = rows.Err(); err != nil {
+		return responses.RatingShortInfo{}, errors.Join(err, unexpected.RequestErr)
+	}
 
-		result.CurrentPoints = float64(info.PointAmount)
-		result.CurrentPosition = int(info.Position)
+	if !found {
+		return responses.RatingShortInfo{}, validation.NoDataFoundErr
 	}
 
 	return result, nil
@@ -13,6 +13,23 @@ func ParseToPgUUID(input string) (pgtype.UUID, error) {
 	return pgUUID, err
 }
 
+// parsePgUUIDs keeps a nil slice nil, so it is sent as null.
+func parsePgUUIDs(input []string) ([]pgtype.UUID, error) {
+	if input == nil {
+		return nil, nil
+	}
+
+	pgUUIDs := make([]pgtype.UUID, len(input))
+	for i, uuid := range input {
+		var err error
+		if pgUUIDs[i], err = ParseToPgUUID(uuid); err != nil {
+			return nil, err
+		}
+	}
+
+	return pgUUIDs, nil
+}
+
 func ParseToPgText(input any) (pgtype.Text, error) {
 	pgText := pgtype.Text{}
 
@@ -44,6 +61,9 @@ func ParseToPgDate(input any) (pgtype.Date, error) {
 		}
 
 		date, err = time.Parse(time.RFC3339, inp)
+		if err != nil {
+			date, err = time.Parse(time.DateOnly, inp)
+		}
 		if err != nil {
 			return pgDate, err
 		}
@@ -74,6 +94,22 @@ func ParseToPgDate(input any) (pgtype.Date, error) {
 	return pgDate, nil
 }
 
+func ParseToPgTimestamptz(input string) (pgtype.Timestamptz, error) {
+	pgTimestamp := pgtype.Timestamptz{}
+	if input == "" {
+		return pgTimestamp, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, input)
+	if err != nil {
+		return pgTimestamp, err
+	}
+
+	pgTimestamp.Time = t
+	pgTimestamp.Valid = true
+	return pgTimestamp, nil
+}
+
 func ParseToPgNumeric(input any) (pgtype.Numeric, error) {
 	pgPointAmount := pgtype.Numeric{}
 	var err error
@@ -117,3 +153,19 @@ func decode[T any](input any) (T, error) {
 	err = decoder.Decode(input)
 	return result, err
 }
+
+func formatPgDate(date pgtype.Date) string {
+	if !date.Valid {
+		return ""
+	}
+
+	return date.Time.Format(time.DateOnly)
+}
+
+func formatPgUUID(uuid pgtype.UUID) string {
+	if !uuid.Valid {
+		return ""
+	}
+
+	return uuid.String()
+}
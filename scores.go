@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/service"
+	"os"
+)
+
+// runScoresCommand handles `sps scores rebuild`. The stored student scores are
+// kept up to date by every change, rebuilding them is only needed to recover
+// after they were changed by hand or restored from a backup.
+func runScoresCommand(ctx context.Context, args []string, scoringService service.ScoringService) error {
+	if len(args) == 0 {
+		return errors.New("usage: sps scores rebuild")
+	}
+
+	switch args[0] {
+	case "rebuild":
+		total, err := scoringService.RebuildStudentScores(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "rebuilt scores of %d students\n", total)
+		return nil
+	default:
+		return fmt.Errorf("unknown scores command %q", args[0])
+	}
+}
@@ -0,0 +1,271 @@
+// Package scoring holds the rules that turn achievements into points. It is
+// the only place points are computed: the scoring service uses it for the API
+// and the repositories use it to keep the stored student scores up to date.
+//
+// Rules of a single achievement:
+//
+//  1. An achievement is scored against its root category, the category without
+//     a parent. An achievement whose root category is not active is not scored
+//     at all and is not counted in the rating.
+//  2. The root category gives the base points.
+//  3. Every selected subcategory value adds its points if the value and its
+//     subcategory are active and the subcategory belongs to the root category
+//     of the achievement. Any other selected value adds nothing.
+//  4. The sum is rounded to three decimals, the precision points are stored with.
+//
+// Category rules, applied in the rating to the achievements of one student in
+// one root category:
+//
+//  5. The achievements are ordered by their points, the best first. Equal ones
+//     are ordered by achievement date and then by uuid.
+//  6. Only the first max achievements are counted, the rest are left out.
+//  7. The k-th counted achievement, starting from zero, gets its points
+//     multiplied by the multiplier and by the diminishing factor to the power
+//     of k.
+//  8. Once the student reaches the max points of the category, the next
+//     achievements only add what is left up to the cap, the rest add nothing.
+//
+// The points of a single achievement shown on its own are the ones before the
+// category rules, as those depend on the other achievements of the student.
+package scoring
+
+import (
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"math"
+	"slices"
+	"strings"
+)
+
+// precision matches numeric(22,3) of category and value points.
+const precision = 1000
+
+// Score applies the single achievement rules. The flag is false when the
+// achievement is not scored.
+func Score(input models.AchievementScoreInput) (float64, bool) {
+	if input.CategoryUUID == "" || !input.CategoryActive {
+		return 0, false
+	}
+
+	points := input.CategoryPoints
+	for _, value := range input.Values {
+		if value.Counted {
+			points += value.Points
+		}
+	}
+
+	return Round(points), true
+}
+
+type scoredAchievement struct {
+	input  models.AchievementScoreInput
+	points float64
+}
+
+// ScoreRating scores the achievements and applies the category rules per
+// student and root category. It returns the points every counted achievement
+// brings to the rating by uuid.
+func ScoreRating(inputs []models.AchievementScoreInput) map[string]float64 {
+	groups := map[[2]string][]scoredAchievement{}
+	for _, input := range inputs {
+		points, ok := Score(input)
+		if !ok {
+			continue
+		}
+
+		key := [2]string{input.UserUUID, input.CategoryUUID}
+		groups[key] = append(groups[key], scoredAchievement{input: input, points: points})
+	}
+
+	scores := make(map[string]float64, len(inputs))
+	for _, group := range groups {
+		slices.SortFunc(group, func(a, b scoredAchievement) int {
+			if a.points != b.points {
+				if a.points > b.points {
+					return -1
+				}
+				return 1
+			}
+			if c := a.input.AchievementDate.Compare(b.input.AchievementDate); c != 0 {
+				return c
+			}
+			return strings.Compare(a.input.AchievementUUID, b.input.AchievementUUID)
+		})
+
+		rules := group[0].input.CategoryRules
+		multiplier := rules.Multiplier
+		if multiplier == 0 {
+			multiplier = 1
+		}
+		factor := rules.DiminishingFactor
+		if factor == 0 {
+			factor = 1
+		}
+
+		total := 0.0
+		for k, achievement := range group {
+			if rules.MaxAchievements > 0 && k >= rules.MaxAchievements {
+				break
+			}
+
+			points := Round(achievement.points * multiplier * math.Pow(factor, float64(k)))
+			if rules.MaxPoints > 0 {
+				points = min(points, max(Round(rules.MaxPoints-total), 0))
+			}
+
+			total += points
+			scores[achievement.input.AchievementUUID] = points
+		}
+	}
+
+	return scores
+}
+
+// Round rounds points to the stored precision.
+func Round(points float64) float64 {
+	return math.Round(points*precision) / precision
+}
+
+// StudentScores sums the rating points of the counted achievements per
+// student, the way the live rating does.
+func StudentScores(inputs []models.AchievementScoreInput) map[string]models.StudentScore {
+	scores := ScoreRating(inputs)
+
+	students := map[string]models.StudentScore{}
+	for _, input := range inputs {
+		points, ok := scores[input.AchievementUUID]
+		if !ok {
+			continue
+		}
+
+		student, ok := students[input.UserUUID]
+		if !ok {
+			student = models.StudentScore{UserUUID: input.UserUUID, AchievementProofed: true}
+		}
+
+		student.PointAmount = Round(student.PointAmount + points)
+		student.AchievementAmount++
+		student.AchievementProofed = student.AchievementProofed && input.Status != models.AchievementStatusUnapproved
+		if input.SubmittedAt.After(student.LastSubmittedAt) {
+			student.LastSubmittedAt = input.SubmittedAt
+		}
+
+		students[input.UserUUID] = student
+	}
+
+	return students
+}
+
+// CategoryScores sums the rating points per student and root category. The
+// categories are the active ones of the achievements, ordered by name.
+func CategoryScores(inputs []models.AchievementScoreInput) models.CategoryScores {
+	scores := ScoreRating(inputs)
+
+	categoryScores := models.CategoryScores{
+		Categories: make([]models.ScoredCategory, 0),
+		Points:     map[string]map[string]float64{},
+	}
+	categories := map[string]bool{}
+	for _, input := range inputs {
+		points, ok := scores[input.AchievementUUID]
+		if !ok {
+			continue
+		}
+
+		if !categories[input.CategoryUUID] {
+			categories[input.CategoryUUID] = true
+			categoryScores.Categories = append(categoryScores.Categories, models.ScoredCategory{
+				UUID: input.CategoryUUID,
+				Name: input.CategoryName,
+			})
+		}
+
+		student, ok := categoryScores.Points[input.UserUUID]
+		if !ok {
+			student = map[string]float64{}
+			categoryScores.Points[input.UserUUID] = student
+		}
+		student[input.CategoryUUID] = Round(student[input.CategoryUUID] + points)
+	}
+
+	slices.SortFunc(categoryScores.Categories, func(a, b models.ScoredCategory) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.UUID, b.UUID)
+	})
+
+	return categoryScores
+}
+
+// Breakdown explains the live rating total of a student. Every achievement of
+// the student is listed under its root category with its state: counted,
+// pending review or excluded along with the reason.
+func Breakdown(userUUID string, inputs []models.AchievementScoreInput) models.ScoreBreakdown {
+	live := make([]models.AchievementScoreInput, 0, len(inputs))
+	for _, input := range inputs {
+		if slices.Contains(models.LiveRatingStatuses, input.Status) {
+			live = append(live, input)
+		}
+	}
+	scores := ScoreRating(live)
+
+	breakdown := models.ScoreBreakdown{UserUUID: userUUID, Categories: make([]models.CategoryScoreBreakdown, 0)}
+	categories := map[string]int{}
+	for _, input := range inputs {
+		achievement := models.AchievementScoreBreakdown{
+			AchievementUUID: input.AchievementUUID,
+			Status:          input.Status,
+			AchievementDate: input.AchievementDate,
+			Values:          input.Values,
+		}
+		achievement.BasePoints, _ = Score(input)
+
+		points, counted := scores[input.AchievementUUID]
+		switch {
+		case input.Status == models.AchievementStatusDeclined:
+			achievement.State, achievement.ExclusionReason = models.ScoreStateExcluded, models.ScoreExclusionDeclined
+		case input.Status == models.AchievementStatusRemoved:
+			achievement.State, achievement.ExclusionReason = models.ScoreStateExcluded, models.ScoreExclusionRemoved
+		case !input.CategoryActive:
+			achievement.State, achievement.ExclusionReason = models.ScoreStateExcluded, models.ScoreExclusionCategoryInactive
+		case !counted:
+			achievement.State, achievement.ExclusionReason = models.ScoreStateExcluded, models.ScoreExclusionCategoryLimit
+		case input.Status == models.AchievementStatusUnapproved:
+			achievement.State, achievement.PointAmount = models.ScoreStatePending, points
+		default:
+			achievement.State, achievement.PointAmount = models.ScoreStateCounted, points
+		}
+
+		i, ok := categories[input.CategoryUUID]
+		if !ok {
+			i = len(breakdown.Categories)
+			categories[input.CategoryUUID] = i
+			breakdown.Categories = append(breakdown.Categories, models.CategoryScoreBreakdown{
+				CategoryUUID: input.CategoryUUID,
+				CategoryName: input.CategoryName,
+			})
+		}
+
+		category := &breakdown.Categories[i]
+		category.PointAmount = Round(category.PointAmount + achievement.PointAmount)
+		category.Achievements = append(category.Achievements, achievement)
+		breakdown.PointAmount = Round(breakdown.PointAmount + achievement.PointAmount)
+	}
+
+	slices.SortFunc(breakdown.Categories, func(a, b models.CategoryScoreBreakdown) int {
+		if c := strings.Compare(a.CategoryName, b.CategoryName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.CategoryUUID, b.CategoryUUID)
+	})
+	for _, category := range breakdown.Categories {
+		slices.SortFunc(category.Achievements, func(a, b models.AchievementScoreBreakdown) int {
+			if c := b.AchievementDate.Compare(a.AchievementDate); c != 0 {
+				return c
+			}
+			return strings.Compare(a.AchievementUUID, b.AchievementUUID)
+		})
+	}
+
+	return breakdown
+}
@@ -0,0 +1,144 @@
+package scoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+// golden is everything the package computes for one set of inputs.
+type golden struct {
+	Scores     map[string]*float64
+	Rating     map[string]float64
+	Students   map[string]models.StudentScore
+	Categories models.CategoryScores
+	Breakdowns map[string]models.ScoreBreakdown
+}
+
+// TestGolden runs every testdata/<case>.input.json through the package and
+// compares the result with testdata/<case>.golden.json. Run the test with
+// -update to write the golden files after an intended change of the rules.
+func TestGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.input.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no test cases in testdata")
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".input.json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var inputs []models.AchievementScoreInput
+			if err = json.Unmarshal(data, &inputs); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.MarshalIndent(computeGolden(inputs), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			goldenPath := filepath.Join("testdata", name+".golden.json")
+			if *update {
+				if err = os.WriteFile(goldenPath, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("%v, run the test with -update to create it", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("result differs from %s, got:\n%s", goldenPath, got)
+			}
+		})
+	}
+}
+
+func computeGolden(inputs []models.AchievementScoreInput) golden {
+	result := golden{
+		Scores:     map[string]*float64{},
+		Rating:     ScoreRating(inputs),
+		Students:   StudentScores(inputs),
+		Categories: CategoryScores(inputs),
+		Breakdowns: map[string]models.ScoreBreakdown{},
+	}
+
+	byUser := map[string][]models.AchievementScoreInput{}
+	for _, input := range inputs {
+		// An achievement that is not scored is written as null.
+		if points, ok := Score(input); ok {
+			result.Scores[input.AchievementUUID] = &points
+		} else {
+			result.Scores[input.AchievementUUID] = nil
+		}
+		byUser[input.UserUUID] = append(byUser[input.UserUUID], input)
+	}
+
+	for userUUID, userInputs := range byUser {
+		result.Breakdowns[userUUID] = Breakdown(userUUID, userInputs)
+	}
+
+	return result
+}
+
+func TestRound(t *testing.T) {
+	tests := map[float64]float64{
+		0.1 + 0.2: 0.3,
+		1.0005:    1.001,
+		2.3334:    2.333,
+		-1.0005:   -1.001,
+		5:         5,
+	}
+
+	for points, want := range tests {
+		if got := Round(points); got != want {
+			t.Errorf("Round(%v) = %v, want %v", points, got, want)
+		}
+	}
+}
+
+// TestScoreRatingOrder checks that the input order does not change which
+// achievements the category rules count.
+func TestScoreRatingOrder(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "category_rules.input.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inputs []models.AchievementScoreInput
+	if err = json.Unmarshal(data, &inputs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := ScoreRating(inputs)
+	slices.Reverse(inputs)
+	got := ScoreRating(inputs)
+
+	if len(got) != len(want) {
+		t.Fatalf("%d scores, want %d", len(got), len(want))
+	}
+	for uuid, points := range want {
+		if got[uuid] != points {
+			t.Errorf("%s = %v, want %v", uuid, got[uuid], points)
+		}
+	}
+}
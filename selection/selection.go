@@ -0,0 +1,203 @@
+// Package selection decides who of the ranked students win a scholarship.
+//
+// The students are taken in the order of the rating:
+//
+//  1. A student who does not meet the conditions, being confirmed, having all
+//     achievements reviewed and having the minimum points, is not selected.
+//     Every condition that fails is reported.
+//  2. A student who meets them is selected when there is room in every quota
+//     the student falls under: the winner count, the quotas of the org unit of
+//     the student and of the units above it, and the quota of the leading
+//     category of the student. Otherwise the full quotas are reported.
+//  3. A student tied with the last student selected within a quota fits the
+//     quota as well, the same way the rating makes all tied students winners.
+package selection
+
+import (
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type quota struct {
+	limit    int
+	used     int
+	lastRank int
+}
+
+// fits reports whether a student of the rank has room in the quota.
+func (q *quota) fits(rank int) bool {
+	return q.used < q.limit || (q.used > 0 && rank == q.lastRank)
+}
+
+func (q *quota) take(rank int) {
+	q.used++
+	q.lastRank = rank
+}
+
+// Select applies the rules to the candidates, which must be ordered by rank.
+func Select(candidates []models.SelectionCandidate, rules models.SelectionRules) []models.SelectionResult {
+	winners := &quota{limit: rules.WinnerCount}
+	units := map[string]*quota{}
+	for uuid, limit := range rules.UnitQuotas {
+		units[uuid] = &quota{limit: limit}
+	}
+	categories := map[string]*quota{}
+	for uuid, limit := range rules.CategoryQuotas {
+		categories[uuid] = &quota{limit: limit}
+	}
+
+	results := make([]models.SelectionResult, len(candidates))
+	for i, candidate := range candidates {
+		result := models.SelectionResult{
+			Candidate:           candidate,
+			LeadingCategoryUUID: LeadingCategory(candidate.Entry.Achievements),
+			Reasons:             make([]models.SelectionReason, 0),
+		}
+
+		if rules.RequireApproved && !candidate.Approved {
+			result.Reasons = append(result.Reasons, models.SelectionReason{
+				Code:   models.SelectionReasonNotApproved,
+				Detail: "Студент не подтверждён",
+			})
+		}
+
+		if rules.RequireVerified && !candidate.Verified {
+			result.Reasons = append(result.Reasons, models.SelectionReason{
+				Code:   models.SelectionReasonUnverified,
+				Detail: "Не все достижения проверены",
+			})
+		}
+
+		if candidate.Entry.PointAmount < rules.MinPoints {
+			result.Reasons = append(result.Reasons, models.SelectionReason{
+				Code:   models.SelectionReasonMinPoints,
+				Detail: fmt.Sprintf("Баллов меньше минимума %s", formatPoints(rules.MinPoints)),
+			})
+		}
+
+		if len(result.Reasons) != 0 {
+			results[i] = result
+			continue
+		}
+
+		rank := candidate.Entry.Position
+		taken := make([]*quota, 0)
+
+		if winners.fits(rank) {
+			taken = append(taken, winners)
+		} else {
+			result.Reasons = append(result.Reasons, models.SelectionReason{
+				Code:   models.SelectionReasonWinnerQuota,
+				Detail: fmt.Sprintf("Все %d мест заняты", winners.limit),
+			})
+		}
+
+		for _, unitUUID := range unitPath(candidate.OrgUnitUUID, rules.UnitParents) {
+			unit, ok := units[unitUUID]
+			if !ok {
+				continue
+			}
+
+			if unit.fits(rank) {
+				taken = append(taken, unit)
+			} else {
+				result.Reasons = append(result.Reasons, models.SelectionReason{
+					Code:   models.SelectionReasonUnitQuota,
+					UUID:   unitUUID,
+					Detail: fmt.Sprintf("Квота подразделения (%d) исчерпана", unit.limit),
+				})
+			}
+		}
+
+		if category, ok := categories[result.LeadingCategoryUUID]; ok {
+			if category.fits(rank) {
+				taken = append(taken, category)
+			} else {
+				result.Reasons = append(result.Reasons, models.SelectionReason{
+					Code:   models.SelectionReasonCategoryQuota,
+					UUID:   result.LeadingCategoryUUID,
+					Detail: fmt.Sprintf("Квота категории (%d) исчерпана", category.limit),
+				})
+			}
+		}
+
+		if len(result.Reasons) == 0 {
+			result.Selected = true
+			for _, q := range taken {
+				q.take(rank)
+			}
+		}
+
+		results[i] = result
+	}
+
+	return results
+}
+
+// Explain sums the decision on a student up in a sentence.
+func Explain(result models.SelectionResult) string {
+	if result.Selected {
+		return "Выбран: все условия выполнены, квоты не исчерпаны"
+	}
+
+	details := make([]string, len(result.Reasons))
+	for i, reason := range result.Reasons {
+		first, size := utf8.DecodeRuneInString(reason.Detail)
+		details[i] = string(unicode.ToLower(first)) + reason.Detail[size:]
+	}
+
+	return "Не выбран: " + strings.Join(details, "; ")
+}
+
+// LeadingCategory returns the root category that brings the most points.
+// Equal categories are ordered by name and then by uuid.
+func LeadingCategory(achievements []models.AchievementPoints) string {
+	type category struct {
+		uuid   string
+		name   string
+		points float64
+	}
+
+	byUUID := map[string]*category{}
+	for _, achievement := range achievements {
+		c, ok := byUUID[achievement.CategoryUUID]
+		if !ok {
+			c = &category{uuid: achievement.CategoryUUID, name: achievement.CategoryName}
+			byUUID[achievement.CategoryUUID] = c
+		}
+		c.points += achievement.PointAmount
+	}
+
+	var leading *category
+	for _, c := range byUUID {
+		if leading == nil || c.points > leading.points ||
+			c.points == leading.points && (c.name < leading.name || c.name == leading.name && c.uuid < leading.uuid) {
+			leading = c
+		}
+	}
+
+	if leading == nil {
+		return ""
+	}
+
+	return leading.uuid
+}
+
+// unitPath returns the unit and the units above it, the nearest first.
+func unitPath(unitUUID string, parents map[string]string) []string {
+	path := make([]string, 0)
+	for unitUUID != "" && !slices.Contains(path, unitUUID) {
+		path = append(path, unitUUID)
+		unitUUID = parents[unitUUID]
+	}
+
+	return path
+}
+
+func formatPoints(points float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", points), "0"), ".")
+}
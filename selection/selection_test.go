@@ -0,0 +1,463 @@
+package selection
+
+import (
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"slices"
+	"testing"
+)
+
+// testCandidate is a confirmed student with reviewed achievements, the points
+// of each achievement coming from the category of the same index.
+type testCandidate struct {
+	uuid       string
+	position   int
+	points     []float64
+	categories []string
+	unit       string
+	unapproved bool
+	unverified bool
+}
+
+func testCandidates(students ...testCandidate) []models.SelectionCandidate {
+	candidates := make([]models.SelectionCandidate, len(students))
+	for i, student := range students {
+		entry := models.RatingSnapshotEntry{
+			UserUUID:          student.uuid,
+			Position:          student.position,
+			AchievementAmount: len(student.points),
+		}
+		for j, point := range student.points {
+			category := "science"
+			if j < len(student.categories) {
+				category = student.categories[j]
+			}
+
+			entry.PointAmount += point
+			entry.Achievements = append(entry.Achievements, models.AchievementPoints{
+				UserUUID:     student.uuid,
+				CategoryUUID: category,
+				CategoryName: category,
+				PointAmount:  point,
+			})
+		}
+
+		candidates[i] = models.SelectionCandidate{
+			Entry:       entry,
+			OrgUnitUUID: student.unit,
+			Approved:    !student.unapproved,
+			Verified:    !student.unverified,
+		}
+	}
+
+	return candidates
+}
+
+// testOutcome is the decision on a student: the reason codes, with the uuid of
+// a quota after a colon, or none for a selected student.
+type testOutcome struct {
+	uuid    string
+	reasons []string
+}
+
+func outcomes(results []models.SelectionResult) []testOutcome {
+	got := make([]testOutcome, len(results))
+	for i, result := range results {
+		got[i] = testOutcome{uuid: result.Candidate.Entry.UserUUID, reasons: make([]string, 0)}
+		for _, reason := range result.Reasons {
+			code := reason.Code
+			if reason.UUID != "" {
+				code += ":" + reason.UUID
+			}
+			got[i].reasons = append(got[i].reasons, code)
+		}
+
+		if result.Selected != (len(result.Reasons) == 0) {
+			got[i].reasons = append(got[i].reasons, "selected with reasons")
+		}
+	}
+
+	return got
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		students []testCandidate
+		rules    models.SelectionRules
+		want     []testOutcome
+	}{
+		{
+			name: "winner count",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}},
+				{uuid: "b", position: 2, points: []float64{8}},
+				{uuid: "c", position: 3, points: []float64{6}},
+			},
+			rules: models.SelectionRules{WinnerCount: 2},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b"},
+				{uuid: "c", reasons: []string{models.SelectionReasonWinnerQuota}},
+			},
+		},
+		{
+			name: "tie at the cutoff",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}},
+				{uuid: "b", position: 2, points: []float64{8}},
+				{uuid: "c", position: 2, points: []float64{8}},
+				{uuid: "d", position: 4, points: []float64{6}},
+			},
+			rules: models.SelectionRules{WinnerCount: 2},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b"},
+				{uuid: "c"},
+				{uuid: "d", reasons: []string{models.SelectionReasonWinnerQuota}},
+			},
+		},
+		{
+			name: "min points",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}},
+				{uuid: "b", position: 2, points: []float64{4.5}},
+			},
+			rules: models.SelectionRules{WinnerCount: 2, MinPoints: 5},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b", reasons: []string{models.SelectionReasonMinPoints}},
+			},
+		},
+		{
+			name: "min points reached exactly",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{5}},
+			},
+			rules: models.SelectionRules{WinnerCount: 1, MinPoints: 5},
+			want:  []testOutcome{{uuid: "a"}},
+		},
+		{
+			name: "rejected student leaves the place to the next",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unapproved: true},
+				{uuid: "b", position: 2, points: []float64{8}},
+			},
+			rules: models.SelectionRules{WinnerCount: 1, RequireApproved: true},
+			want: []testOutcome{
+				{uuid: "a", reasons: []string{models.SelectionReasonNotApproved}},
+				{uuid: "b"},
+			},
+		},
+		{
+			name: "approval not required",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unapproved: true},
+			},
+			rules: models.SelectionRules{WinnerCount: 1},
+			want:  []testOutcome{{uuid: "a"}},
+		},
+		{
+			name: "verified achievements required",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unverified: true},
+				{uuid: "b", position: 2, points: []float64{8}},
+			},
+			rules: models.SelectionRules{WinnerCount: 2, RequireVerified: true},
+			want: []testOutcome{
+				{uuid: "a", reasons: []string{models.SelectionReasonUnverified}},
+				{uuid: "b"},
+			},
+		},
+		{
+			name: "verification not required",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unverified: true},
+			},
+			rules: models.SelectionRules{WinnerCount: 1},
+			want:  []testOutcome{{uuid: "a"}},
+		},
+		{
+			name: "every failed condition reported",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{3}, unapproved: true, unverified: true},
+			},
+			rules: models.SelectionRules{WinnerCount: 1, MinPoints: 5, RequireApproved: true, RequireVerified: true},
+			want: []testOutcome{
+				{uuid: "a", reasons: []string{
+					models.SelectionReasonNotApproved,
+					models.SelectionReasonUnverified,
+					models.SelectionReasonMinPoints,
+				}},
+			},
+		},
+		{
+			name: "category quota by leading category",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}},
+				{uuid: "b", position: 2, points: []float64{5, 3}, categories: []string{"science", "sport"}},
+				{uuid: "c", position: 3, points: []float64{3, 4}, categories: []string{"science", "sport"}},
+			},
+			rules: models.SelectionRules{WinnerCount: 3, CategoryQuotas: map[string]int{"science": 1}},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b", reasons: []string{models.SelectionReasonCategoryQuota + ":science"}},
+				{uuid: "c"},
+			},
+		},
+		{
+			name: "tie at the cutoff of a category quota",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}},
+				{uuid: "b", position: 1, points: []float64{10}},
+				{uuid: "c", position: 3, points: []float64{8}},
+			},
+			rules: models.SelectionRules{WinnerCount: 3, CategoryQuotas: map[string]int{"science": 1}},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b"},
+				{uuid: "c", reasons: []string{models.SelectionReasonCategoryQuota + ":science"}},
+			},
+		},
+		{
+			name: "unit quota",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unit: "g1"},
+				{uuid: "b", position: 2, points: []float64{8}, unit: "g1"},
+				{uuid: "c", position: 3, points: []float64{6}, unit: "g2"},
+			},
+			rules: models.SelectionRules{WinnerCount: 3, UnitQuotas: map[string]int{"g1": 1}},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b", reasons: []string{models.SelectionReasonUnitQuota + ":g1"}},
+				{uuid: "c"},
+			},
+		},
+		{
+			name: "quota of a unit above",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unit: "g1"},
+				{uuid: "b", position: 2, points: []float64{8}, unit: "g2"},
+				{uuid: "c", position: 3, points: []float64{6}, unit: "g3"},
+			},
+			rules: models.SelectionRules{
+				WinnerCount: 3,
+				UnitQuotas:  map[string]int{"faculty": 1},
+				UnitParents: map[string]string{"g1": "faculty", "g2": "faculty", "faculty": "institute"},
+			},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b", reasons: []string{models.SelectionReasonUnitQuota + ":faculty"}},
+				{uuid: "c"},
+			},
+		},
+		{
+			name: "full quotas of the unit and of the units above",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unit: "g1"},
+				{uuid: "b", position: 2, points: []float64{8}, unit: "g1"},
+			},
+			rules: models.SelectionRules{
+				WinnerCount: 1,
+				UnitQuotas:  map[string]int{"g1": 1, "faculty": 1, "institute": 2},
+				UnitParents: map[string]string{"g1": "faculty", "faculty": "institute"},
+			},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b", reasons: []string{
+					models.SelectionReasonWinnerQuota,
+					models.SelectionReasonUnitQuota + ":g1",
+					models.SelectionReasonUnitQuota + ":faculty",
+				}},
+			},
+		},
+		{
+			name: "student rejected by a quota takes no place in the others",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unit: "g1"},
+				{uuid: "b", position: 2, points: []float64{8}, unit: "g1"},
+				{uuid: "c", position: 3, points: []float64{6}, unit: "g2"},
+			},
+			rules: models.SelectionRules{
+				WinnerCount: 3,
+				UnitQuotas:  map[string]int{"g1": 1, "faculty": 2},
+				UnitParents: map[string]string{"g1": "faculty", "g2": "faculty"},
+			},
+			want: []testOutcome{
+				{uuid: "a"},
+				{uuid: "b", reasons: []string{models.SelectionReasonUnitQuota + ":g1"}},
+				{uuid: "c"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := outcomes(Select(testCandidates(test.students...), test.rules))
+
+			if len(got) != len(test.want) {
+				t.Fatalf("got %d results, want %d", len(got), len(test.want))
+			}
+			for i, want := range test.want {
+				if want.reasons == nil {
+					want.reasons = []string{}
+				}
+				if got[i].uuid != want.uuid || !slices.Equal(got[i].reasons, want.reasons) {
+					t.Errorf("result %d = %v, want %v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestQuotaFits(t *testing.T) {
+	tests := []struct {
+		name  string
+		quota quota
+		rank  int
+		want  bool
+	}{
+		{name: "empty", quota: quota{limit: 1}, rank: 1, want: true},
+		{name: "room left", quota: quota{limit: 2, used: 1, lastRank: 1}, rank: 2, want: true},
+		{name: "full", quota: quota{limit: 1, used: 1, lastRank: 1}, rank: 2, want: false},
+		{name: "tied with the last taken", quota: quota{limit: 1, used: 1, lastRank: 2}, rank: 2, want: true},
+		{name: "overfilled by a tie", quota: quota{limit: 1, used: 2, lastRank: 2}, rank: 2, want: true},
+		{name: "after an overfilling tie", quota: quota{limit: 1, used: 2, lastRank: 2}, rank: 4, want: false},
+		{name: "zero limit", quota: quota{}, rank: 0, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.quota.fits(test.rank); got != test.want {
+				t.Errorf("fits(%d) = %t, want %t", test.rank, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLeadingCategory(t *testing.T) {
+	achievement := func(uuid, name string, points float64) models.AchievementPoints {
+		return models.AchievementPoints{CategoryUUID: uuid, CategoryName: name, PointAmount: points}
+	}
+
+	tests := []struct {
+		name         string
+		achievements []models.AchievementPoints
+		want         string
+	}{
+		{name: "no achievements", want: ""},
+		{
+			name:         "most points",
+			achievements: []models.AchievementPoints{achievement("science", "Наука", 5), achievement("sport", "Спорт", 7)},
+			want:         "sport",
+		},
+		{
+			name: "points summed per category",
+			achievements: []models.AchievementPoints{
+				achievement("science", "Наука", 5),
+				achievement("sport", "Спорт", 3),
+				achievement("sport", "Спорт", 3),
+			},
+			want: "sport",
+		},
+		{
+			name:         "equal points ordered by name",
+			achievements: []models.AchievementPoints{achievement("a", "Спорт", 5), achievement("b", "Наука", 5)},
+			want:         "b",
+		},
+		{
+			name:         "equal names ordered by uuid",
+			achievements: []models.AchievementPoints{achievement("b", "Наука", 5), achievement("a", "Наука", 5)},
+			want:         "a",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := LeadingCategory(test.achievements); got != test.want {
+				t.Errorf("LeadingCategory() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnitPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		unit    string
+		parents map[string]string
+		want    []string
+	}{
+		{name: "no unit", want: []string{}},
+		{name: "top unit", unit: "g1", want: []string{"g1"}},
+		{
+			name:    "nested unit",
+			unit:    "g1",
+			parents: map[string]string{"g1": "faculty", "faculty": "institute", "g2": "faculty"},
+			want:    []string{"g1", "faculty", "institute"},
+		},
+		{
+			name:    "cycle",
+			unit:    "a",
+			parents: map[string]string{"a": "b", "b": "a"},
+			want:    []string{"a", "b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := unitPath(test.unit, test.parents); !slices.Equal(got, test.want) {
+				t.Errorf("unitPath(%q) = %v, want %v", test.unit, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name     string
+		students []testCandidate
+		rules    models.SelectionRules
+		want     string
+	}{
+		{
+			name:     "selected",
+			students: []testCandidate{{uuid: "a", position: 1, points: []float64{10}}},
+			rules:    models.SelectionRules{WinnerCount: 1},
+			want:     "Выбран: все условия выполнены, квоты не исчерпаны",
+		},
+		{
+			name:     "one reason",
+			students: []testCandidate{{uuid: "a", position: 1, points: []float64{10}, unapproved: true}},
+			rules:    models.SelectionRules{WinnerCount: 1, RequireApproved: true},
+			want:     "Не выбран: студент не подтверждён",
+		},
+		{
+			name:     "every reason",
+			students: []testCandidate{{uuid: "a", position: 1, points: []float64{2}, unapproved: true, unverified: true}},
+			rules:    models.SelectionRules{WinnerCount: 1, MinPoints: 2.5, RequireApproved: true, RequireVerified: true},
+			want:     "Не выбран: студент не подтверждён; не все достижения проверены; баллов меньше минимума 2.5",
+		},
+		{
+			name: "full quotas",
+			students: []testCandidate{
+				{uuid: "a", position: 1, points: []float64{10}, unit: "g1"},
+				{uuid: "b", position: 2, points: []float64{8}, unit: "g1"},
+			},
+			rules: models.SelectionRules{
+				WinnerCount:    1,
+				UnitQuotas:     map[string]int{"g1": 1},
+				CategoryQuotas: map[string]int{"science": 1},
+			},
+			want: "Не выбран: все 1 мест заняты; квота подразделения (1) исчерпана; квота категории (1) исчерпана",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := Select(testCandidates(test.students...), test.rules)
+			if got := Explain(results[len(results)-1]); got != test.want {
+				t.Errorf("Explain() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,19 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/audit"
 	"github.com/igntnk/scholarship_point_system/controllers/requests"
 	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/authorization"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
 	"github.com/igntnk/scholarship_point_system/errors/validation"
 	"github.com/igntnk/scholarship_point_system/repository"
 	"github.com/igntnk/scholarship_point_system/service/models"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
 )
 
 type AchievementService interface {
@@ -17,22 +25,157 @@ type AchievementService interface {
 	CreateAchievement(ctx context.Context, userUUID string, achievement requests.UpsertAchievement) (string, error)
 	UpdateAchievement(ctx context.Context, achievement requests.UpsertAchievement) error
 	RemoveAchievement(ctx context.Context, uuid string) error
-	ApproveAchievement(ctx context.Context, uuid string) error
-	DeclineAchievement(ctx context.Context, uuid string) error
+	ApproveAchievement(ctx context.Context, uuid, comment string) error
+	DeclineAchievement(ctx context.Context, uuid, comment string) error
+	ResubmitAchievement(ctx context.Context, uuid, comment string) error
+	ReviewAchievements(ctx context.Context, items []requests.BatchReviewItem) ([]responses.BatchReviewResult, error)
+	GetAchievementQueue(ctx context.Context, filter models.AchievementQueueFilter, limit, offset int) ([]responses.QueueAchievement, []responses.AchievementStatusCount, int, error)
+	ClaimAchievement(ctx context.Context, uuid string) (responses.AchievementClaim, error)
+	ReleaseAchievementClaim(ctx context.Context, uuid string) error
+}
+
+const (
+	achievementStatusUnapproved = "unapproved"
+	achievementStatusApproved   = "approved"
+	achievementStatusDeclined   = "declined"
+	achievementStatusUsed       = "used"
+	achievementStatusRemoved    = "removed"
+)
+
+// errNoEvidence is returned for an achievement with neither a link nor an
+// uploaded file.
+var errNoEvidence = errors.New("Необходимо указать ссылку или прикрепить файл")
+
+// achievementActor is the role in which the current user changes an achievement status.
+type achievementActor int
+
+const (
+	achievementActorOwner achievementActor = iota
+	achievementActorReviewer
+	achievementActorSystem
+)
+
+// achievementTransitions lists every allowed status change and who may perform it.
+// Statuses without outgoing transitions (used, removed) are terminal.
+var achievementTransitions = map[string]map[string][]achievementActor{
+	achievementStatusUnapproved: {
+		achievementStatusApproved: {achievementActorReviewer},
+		achievementStatusDeclined: {achievementActorReviewer},
+		achievementStatusRemoved:  {achievementActorOwner, achievementActorReviewer},
+	},
+	achievementStatusApproved: {
+		achievementStatusUsed:     {achievementActorSystem},
+		achievementStatusDeclined: {achievementActorReviewer},
+		achievementStatusRemoved:  {achievementActorReviewer},
+	},
+	achievementStatusDeclined: {
+		achievementStatusUnapproved: {achievementActorOwner},
+		achievementStatusRemoved:    {achievementActorOwner, achievementActorReviewer},
+	},
+	achievementStatusUsed:    {},
+	achievementStatusRemoved: {},
+}
+
+func canTransitAchievement(from, to string, actor achievementActor) bool {
+	for _, allowed := range achievementTransitions[from][to] {
+		if allowed == actor {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkAchievementTransition(from, to string, actor achievementActor) error {
+	if !canTransitAchievement(from, to, actor) {
+		return errors.Join(conflict.InvalidTransitionErr, fmt.Errorf("Переход достижения из статуса %s в %s недоступен", from, to))
+	}
+
+	return nil
+}
+
+// isAchievementEditable reports whether the achievement content may still be changed.
+func isAchievementEditable(status string) bool {
+	return status == achievementStatusUnapproved || status == achievementStatusDeclined
+}
+
+// achievementActorFor treats the author of the achievement as its owner and a
+// user with the review permission as a reviewer. Anyone else may not change
+// the achievement.
+func (s *achievementService) achievementActorFor(ctx context.Context, achievement models.SimpleAchievement) (achievementActor, error) {
+	if audit.MetaFromContext(ctx).ActorUUID == achievement.UserUUID {
+		return achievementActorOwner, nil
+	}
+
+	if err := s.checkReviewer(ctx, achievement); err != nil {
+		return 0, err
+	}
+
+	return achievementActorReviewer, nil
+}
+
+// checkReviewer lets users with the review permission decide on achievements
+// of other users; nobody reviews their own.
+func (s *achievementService) checkReviewer(ctx context.Context, achievement models.SimpleAchievement) error {
+	if audit.MetaFromContext(ctx).ActorUUID == achievement.UserUUID {
+		return errors.Join(authorization.HasNoPermissionErr, errors.New("Нельзя проверять собственное достижение"))
+	}
+
+	reviewer, err := isAchievementReviewer(ctx, s.permissionService)
+	if err != nil {
+		return err
+	}
+	if !reviewer {
+		return errors.Join(authorization.HasNoPermissionErr, errors.New("Нет прав на изменение чужого достижения"))
+	}
+
+	return nil
+}
+
+const (
+	reviewDecisionApprove = "approve"
+	reviewDecisionDecline = "decline"
+
+	maxBatchReviewItems = 100
+
+	// queueStatusAll disables the status filter of the moderation queue.
+	queueStatusAll = "all"
+)
+
+type achievementStatusState struct {
+	Status string `json:"status"`
 }
 
 type achievementService struct {
-	achievementRepo repository.AchievementRepository
-	userRepo        repository.UserRepository
+	achievementRepo   repository.AchievementRepository
+	userRepo          repository.UserRepository
+	attachmentRepo    repository.AttachmentRepository
+	periodRepo        repository.PeriodRepository
+	permissionService PermissionService
+	scoringService    ScoringService
+	claimDuration     time.Duration
+	duplicateCheck    models.DuplicateCheck
 }
 
 func NewAchievementService(
 	r repository.AchievementRepository,
 	u repository.UserRepository,
+	a repository.AttachmentRepository,
+	p repository.PeriodRepository,
+	permissionService PermissionService,
+	scoringService ScoringService,
+	claimDuration time.Duration,
+	duplicateCheck models.DuplicateCheck,
 ) AchievementService {
 	return &achievementService{
-		achievementRepo: r,
-		userRepo:        u,
+		achievementRepo:   r,
+		userRepo:          u,
+		attachmentRepo:    a,
+		periodRepo:        p,
+		permissionService: permissionService,
+		scoringService:    scoringService,
+		claimDuration:     claimDuration,
+		duplicateCheck:    duplicateCheck,
 	}
 }
 
@@ -50,6 +193,11 @@ func (s *achievementService) GetUserAchievements(ctx context.Context, uuid strin
 		return nil, err
 	}
 
+	scores, err := s.scoreAchievements(ctx, modelAchievements)
+	if err != nil {
+		return nil, err
+	}
+
 	response := make([]responses.SimpleAchievement, len(modelAchievements))
 	for i, achievement := range modelAchievements {
 		response[i] = responses.SimpleAchievement{
@@ -58,7 +206,7 @@ func (s *achievementService) GetUserAchievements(ctx context.Context, uuid strin
 			Status:         achievement.Status,
 			CategoryName:   achievement.CategoryName,
 			CategoryUUID:   achievement.CategoryUUID,
-			PointAmount:    achievement.PointAmount,
+			PointAmount:    float32(scores[achievement.UUID]),
 			AttachmentLink: achievement.AttachmentLink,
 		}
 	}
@@ -80,6 +228,11 @@ func (s *achievementService) GetUserAchievementsWithPagination(ctx context.Conte
 		return nil, 0, err
 	}
 
+	scores, err := s.scoreAchievements(ctx, modelAchievements)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	response := make([]responses.SimpleAchievement, len(modelAchievements))
 	for i, achievement := range modelAchievements {
 		response[i] = responses.SimpleAchievement{
@@ -87,7 +240,7 @@ func (s *achievementService) GetUserAchievementsWithPagination(ctx context.Conte
 			Comment:        achievement.Comment,
 			Status:         achievement.Status,
 			CategoryName:   achievement.CategoryName,
-			PointAmount:    achievement.PointAmount,
+			PointAmount:    float32(scores[achievement.UUID]),
 			AttachmentLink: achievement.AttachmentLink,
 		}
 	}
@@ -96,22 +249,249 @@ func (s *achievementService) GetUserAchievementsWithPagination(ctx context.Conte
 }
 
 func (s *achievementService) GetAchievementByUUID(ctx context.Context, uuid string) (responses.FullAchievement, error) {
-	return s.achievementRepo.GetAchievementByUUID(ctx, uuid)
+	achievement, err := s.achievementRepo.GetAchievementByUUID(ctx, uuid)
+	if err != nil {
+		return responses.FullAchievement{}, err
+	}
+
+	reviews, err := s.achievementRepo.GetAchievementReviews(ctx, uuid)
+	if err != nil {
+		return responses.FullAchievement{}, err
+	}
+
+	achievement.History = make([]responses.AchievementReview, len(reviews))
+	for i, review := range reviews {
+		achievement.History[i] = responses.AchievementReview{
+			UUID:         review.UUID,
+			ReviewerUUID: review.ReviewerUUID,
+			ReviewerName: strings.TrimSpace(strings.Join([]string{
+				review.ReviewerSecondName, review.ReviewerName, review.ReviewerPatronymic,
+			}, " ")),
+			FromStatus: review.FromStatus,
+			ToStatus:   review.ToStatus,
+			Comment:    review.Comment,
+			CreatedAt:  review.CreatedAt,
+		}
+	}
+
+	scores, err := s.scoringService.ScoreAchievements(ctx, models.ScoringFilter{AchievementUUIDs: []string{uuid}})
+	if err != nil {
+		return responses.FullAchievement{}, err
+	}
+	achievement.PointAmount = float32(scores[uuid])
+
+	// The warnings name achievements of other students, so only reviewers see them.
+	reviewer, err := isAchievementReviewer(ctx, s.permissionService)
+	if err != nil {
+		return responses.FullAchievement{}, err
+	}
+	if reviewer {
+		achievement.EvidenceWarnings, err = s.getEvidenceWarnings(ctx, uuid)
+		if err != nil {
+			return responses.FullAchievement{}, err
+		}
+	}
+
+	period, err := s.periodRepo.GetAchievementPeriod(ctx, uuid)
+	if err != nil && !errors.Is(err, validation.NoDataFoundErr) {
+		return responses.FullAchievement{}, err
+	}
+	achievement.PeriodUUID = period.UUID
+	achievement.PeriodName = period.Name
+
+	return achievement, nil
+}
+
+// scoreAchievements returns the points of the listed achievements by uuid.
+func (s *achievementService) scoreAchievements(ctx context.Context, achievements []models.SimpleAchievement) (map[string]float64, error) {
+	achievementUUIDs := make([]string, len(achievements))
+	for i, achievement := range achievements {
+		achievementUUIDs[i] = achievement.UUID
+	}
+
+	return s.scoringService.ScoreAchievements(ctx, models.ScoringFilter{AchievementUUIDs: achievementUUIDs})
+}
+
+// getEvidenceWarnings reports files and links of the achievement that are
+// also used by other, not removed, achievements of any user.
+func (s *achievementService) getEvidenceWarnings(ctx context.Context, uuid string) ([]responses.EvidenceWarning, error) {
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	fileDuplicates, err := s.attachmentRepo.GetDuplicateAttachmentEvidence(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	linkDuplicates, err := s.achievementRepo.GetDuplicateLinkEvidence(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := make([]responses.EvidenceWarning, 0, len(fileDuplicates)+len(linkDuplicates))
+	for _, duplicate := range append(fileDuplicates, linkDuplicates...) {
+		warnings = append(warnings, responses.EvidenceWarning{
+			Kind:            duplicate.Kind,
+			Evidence:        duplicate.Evidence,
+			DuplicateOf:     duplicate.DuplicateOf,
+			AchievementUUID: duplicate.AchievementUUID,
+			UserUUID:        duplicate.UserUUID,
+			SameUser:        duplicate.UserUUID == achievement.UserUUID,
+		})
+	}
+
+	return warnings, nil
 }
 
 func (s *achievementService) CreateAchievement(ctx context.Context, userUUID string, a requests.UpsertAchievement) (string, error) {
-	if a.AttachmentLink == "" {
-		return "", errors.Join(validation.WrongInputErr, errors.New("Пустая ссылка для вложения"))
+	if strings.TrimSpace(a.AttachmentLink) == "" && !a.HasAttachments {
+		return "", errors.Join(validation.WrongInputErr, errNoEvidence)
+	}
+
+	if err := s.checkSubmissionPeriod(ctx, a.AchievementDate); err != nil {
+		return "", err
 	}
 
-	return s.achievementRepo.CreateAchievement(ctx, userUUID, a)
+	possibleDuplicateOf, err := s.findDuplicate(ctx, userUUID, a)
+	if err != nil {
+		return "", err
+	}
+
+	return s.achievementRepo.CreateAchievement(ctx, userUUID, a, possibleDuplicateOf)
 }
 
-func (s *achievementService) UpdateAchievement(ctx context.Context, a requests.UpsertAchievement) error {
-	if a.AttachmentLink == "" {
-		return errors.Join(validation.WrongInputErr, errors.New("Пустая ссылка для вложения"))
+// checkSubmissionPeriod rejects achievements dated inside a period that no
+// longer accepts submissions. Dates outside of any period are not restricted.
+func (s *achievementService) checkSubmissionPeriod(ctx context.Context, achievementDate string) error {
+	date, hasDate, err := parseAchievementDate(achievementDate)
+	if err != nil {
+		return err
+	}
+	if !hasDate {
+		date = time.Now()
+	}
+
+	period, err := s.periodRepo.GetPeriodByDate(ctx, date)
+	if err != nil {
+		if errors.Is(err, validation.NoDataFoundErr) {
+			return nil
+		}
+		return err
+	}
+
+	if !isPeriodAcceptingAchievements(period, time.Now()) {
+		return errors.Join(conflict.PeriodClosedErr, fmt.Errorf("Период %q не принимает новые достижения", period.Name))
+	}
+
+	return nil
+}
+
+// findDuplicate compares the new achievement with earlier not removed
+// achievements of the user in the same category. A submission with the same
+// subcategory values and the same date is rejected. One with a similar comment
+// and a date inside the configured window is accepted, and the uuid of the
+// similar achievement is returned so reviewers can compare them.
+func (s *achievementService) findDuplicate(ctx context.Context, userUUID string, a requests.UpsertAchievement) (string, error) {
+	if s.duplicateCheck.Disabled || a.CategoryUUID == "" {
+		return "", nil
+	}
+
+	date, hasDate, err := parseAchievementDate(a.AchievementDate)
+	if err != nil {
+		return "", err
+	}
+
+	candidates, err := s.achievementRepo.GetDuplicateCandidates(ctx, userUUID, a.CategoryUUID)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]string, len(a.Subcategories))
+	for i, sub := range a.Subcategories {
+		values[i] = sub.UUID + ":" + sub.SelectedValue
+	}
+	slices.Sort(values)
+
+	possibleDuplicateOf := ""
+	for _, candidate := range candidates {
+		candidateValues := slices.Clone(candidate.SubcategoryValues)
+		slices.Sort(candidateValues)
+
+		candidateHasDate := !candidate.AchievementDate.IsZero()
+		if hasDate && candidateHasDate && date.Equal(candidate.AchievementDate) && slices.Equal(values, candidateValues) {
+			return "", errors.Join(validation.RecordAlreadyExistsErr, errors.New("Такое достижение уже добавлено"))
+		}
+
+		if possibleDuplicateOf != "" {
+			continue
+		}
+
+		inWindow := !hasDate || !candidateHasDate ||
+			absDuration(date.Sub(candidate.AchievementDate)) <= s.duplicateCheck.DateWindow
+		if inWindow && commentSimilarity(a.Comment, candidate.Comment) >= s.duplicateCheck.CommentSimilarity {
+			possibleDuplicateOf = candidate.UUID
+		}
+	}
+
+	return possibleDuplicateOf, nil
+}
+
+func parseAchievementDate(value string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		date, err = time.Parse(time.DateOnly, value)
+	}
+	if err != nil {
+		return time.Time{}, false, errors.Join(err, validation.WrongInputErr, errors.New("Неверный формат даты достижения"))
+	}
+
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), true, nil
+}
+
+// commentSimilarity is the Jaccard index of the lowercased word sets of two comments.
+func commentSimilarity(a, b string) float64 {
+	wordsA, wordsB := commentWords(a), commentWords(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	common := 0
+	for word := range wordsA {
+		if _, ok := wordsB[word]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(wordsA)+len(wordsB)-common)
+}
+
+func commentWords(comment string) map[string]struct{} {
+	words := map[string]struct{}{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(comment), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = struct{}{}
+	}
+
+	return words
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
 	}
 
+	return d
+}
+
+func (s *achievementService) UpdateAchievement(ctx context.Context, a requests.UpsertAchievement) error {
 	if len(a.CategoryUUID) == 0 {
 		return errors.Join(validation.WrongInputErr, errors.New("Нет категории у достижения"))
 	}
@@ -121,6 +501,24 @@ func (s *achievementService) UpdateAchievement(ctx context.Context, a requests.U
 		return err
 	}
 
+	if !isAchievementEditable(dbAchievement.StatusCode) {
+		return errors.Join(conflict.InvalidTransitionErr, errors.New("Редактировать можно только непроверенное или отклоненное достижение"))
+	}
+
+	if strings.TrimSpace(a.AttachmentLink) == "" {
+		attachments, err := s.attachmentRepo.CountAttachments(ctx, a.UUID)
+		if err != nil {
+			return err
+		}
+		if attachments == 0 {
+			return errors.Join(validation.WrongInputErr, errNoEvidence)
+		}
+	}
+
+	if err = s.checkSubmissionPeriod(ctx, a.AchievementDate); err != nil {
+		return err
+	}
+
 	dbCats, err := s.achievementRepo.GetAchievementCategories(ctx, dbAchievement.UUID)
 	if err != nil {
 		return err
@@ -149,35 +547,342 @@ func (s *achievementService) UpdateAchievement(ctx context.Context, a requests.U
 			UUID:           a.UUID,
 			AttachmentLink: a.AttachmentLink,
 			Comment:        a.Comment,
+			AttachmentDate: a.AchievementDate,
 		})
 	}
 
-	return s.achievementRepo.UpdateAchievementFull(ctx, a)
+	if dbAchievement.StatusCode != achievementStatusUnapproved {
+		actor, err := s.achievementActorFor(ctx, dbAchievement)
+		if err != nil {
+			return err
+		}
+
+		if err = checkAchievementTransition(dbAchievement.StatusCode, achievementStatusUnapproved, actor); err != nil {
+			return err
+		}
+	}
+
+	return s.achievementRepo.UpdateAchievementFull(ctx, a, dbAchievement.StatusCode, newAchievementReview(ctx, ""))
 }
 
 func (s *achievementService) RemoveAchievement(ctx context.Context, uuid string) error {
-	_, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	actor, err := s.achievementActorFor(ctx, achievement)
 	if err != nil {
 		return err
 	}
 
-	return s.achievementRepo.MakeAchievementRemoved(ctx, uuid)
+	if err = checkAchievementTransition(achievement.StatusCode, achievementStatusRemoved, actor); err != nil {
+		return err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionAchievementRemove, audit.EntityAchievement, uuid,
+		achievementStatusState{Status: achievement.StatusCode},
+		achievementStatusState{Status: achievementStatusRemoved},
+	)
+
+	return s.achievementRepo.MakeAchievementRemoved(ctx, uuid, achievement.StatusCode, newAchievementReview(ctx, ""), event)
 }
 
-func (s *achievementService) ApproveAchievement(ctx context.Context, uuid string) error {
-	_, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
+func (s *achievementService) ApproveAchievement(ctx context.Context, uuid, comment string) error {
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
 	if err != nil {
 		return err
 	}
 
-	return s.achievementRepo.MakeAchievementApproved(ctx, uuid)
+	if err = s.checkReviewer(ctx, achievement); err != nil {
+		return err
+	}
+
+	err = checkAchievementTransition(achievement.StatusCode, achievementStatusApproved, achievementActorReviewer)
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkAchievementClaim(ctx, uuid); err != nil {
+		return err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionAchievementApprove, audit.EntityAchievement, uuid,
+		achievementStatusState{Status: achievement.StatusCode},
+		achievementStatusState{Status: achievementStatusApproved},
+	)
+
+	return s.achievementRepo.MakeAchievementApproved(ctx, uuid, achievement.StatusCode, newAchievementReview(ctx, comment), event)
 }
 
-func (s *achievementService) DeclineAchievement(ctx context.Context, uuid string) error {
-	_, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
+func (s *achievementService) DeclineAchievement(ctx context.Context, uuid, comment string) error {
+	if strings.TrimSpace(comment) == "" {
+		return errors.Join(validation.WrongInputErr, errors.New("Необходимо указать причину отклонения"))
+	}
+
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
 	if err != nil {
 		return err
 	}
 
-	return s.achievementRepo.MakeAchievementDeclined(ctx, uuid)
+	if err = s.checkReviewer(ctx, achievement); err != nil {
+		return err
+	}
+
+	err = checkAchievementTransition(achievement.StatusCode, achievementStatusDeclined, achievementActorReviewer)
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkAchievementClaim(ctx, uuid); err != nil {
+		return err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionAchievementDecline, audit.EntityAchievement, uuid,
+		achievementStatusState{Status: achievement.StatusCode},
+		achievementStatusState{Status: achievementStatusDeclined},
+	)
+
+	return s.achievementRepo.MakeAchievementDeclined(ctx, uuid, achievement.StatusCode, newAchievementReview(ctx, comment), event)
+}
+
+// ResubmitAchievement returns a declined achievement of the current user back to review.
+func (s *achievementService) ResubmitAchievement(ctx context.Context, uuid, comment string) error {
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	if audit.MetaFromContext(ctx).ActorUUID != achievement.UserUUID {
+		return errors.Join(authorization.HasNoPermissionErr, errors.New("Повторно отправить можно только свое достижение"))
+	}
+
+	err = checkAchievementTransition(achievement.StatusCode, achievementStatusUnapproved, achievementActorOwner)
+	if err != nil {
+		return err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionAchievementResubmit, audit.EntityAchievement, uuid,
+		achievementStatusState{Status: achievement.StatusCode},
+		achievementStatusState{Status: achievementStatusUnapproved},
+	)
+
+	return s.achievementRepo.MakeAchievementUnapproved(ctx, uuid, achievement.StatusCode, newAchievementReview(ctx, comment), event)
+}
+
+// ReviewAchievements applies every decision separately, so one invalid item
+// does not roll back the decisions already made for the others. Items are
+// claimed on the way, so those reviewed by someone else are reported as failed.
+func (s *achievementService) ReviewAchievements(ctx context.Context, items []requests.BatchReviewItem) ([]responses.BatchReviewResult, error) {
+	if len(items) == 0 {
+		return nil, errors.Join(validation.WrongInputErr, errors.New("Пустой список достижений"))
+	}
+
+	if len(items) > maxBatchReviewItems {
+		return nil, errors.Join(validation.WrongInputErr, fmt.Errorf("За один раз можно проверить не более %d достижений", maxBatchReviewItems))
+	}
+
+	results := make([]responses.BatchReviewResult, len(items))
+	for i, item := range items {
+		err := s.reviewAchievement(ctx, item)
+
+		results[i] = responses.BatchReviewResult{
+			UUID:     item.UUID,
+			Decision: item.Decision,
+			Success:  err == nil,
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+
+	return results, nil
+}
+
+// reviewAchievement checks the decision before claiming the achievement, and
+// gives the claim back if the decision still fails, so a refused item is not
+// left locked for the other reviewers until the lease expires.
+func (s *achievementService) reviewAchievement(ctx context.Context, item requests.BatchReviewItem) error {
+	var to string
+	switch item.Decision {
+	case reviewDecisionApprove:
+		to = achievementStatusApproved
+	case reviewDecisionDecline:
+		to = achievementStatusDeclined
+		if strings.TrimSpace(item.Comment) == "" {
+			return errors.Join(validation.WrongInputErr, errors.New("Необходимо указать причину отклонения"))
+		}
+	default:
+		return errors.Join(validation.WrongInputErr, fmt.Errorf("Неизвестное решение %q", item.Decision))
+	}
+
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, item.UUID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkReviewer(ctx, achievement); err != nil {
+		return err
+	}
+
+	if err = checkAchievementTransition(achievement.StatusCode, to, achievementActorReviewer); err != nil {
+		return err
+	}
+
+	if _, err = s.ClaimAchievement(ctx, item.UUID); err != nil {
+		return err
+	}
+
+	if to == achievementStatusApproved {
+		err = s.ApproveAchievement(ctx, item.UUID, item.Comment)
+	} else {
+		err = s.DeclineAchievement(ctx, item.UUID, item.Comment)
+	}
+	if err != nil {
+		if releaseErr := s.ReleaseAchievementClaim(ctx, item.UUID); releaseErr != nil && !errors.Is(releaseErr, conflict.NotClaimedErr) {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetAchievementQueue lists achievements of all users oldest-first, only
+// unapproved ones unless another status is requested.
+func (s *achievementService) GetAchievementQueue(
+	ctx context.Context,
+	filter models.AchievementQueueFilter,
+	limit, offset int,
+) ([]responses.QueueAchievement, []responses.AchievementStatusCount, int, error) {
+	if limit < 0 || offset < 0 {
+		return nil, nil, 0, errors.Join(validation.WrongInputErr, errors.New("Неверные параметры пагинации"))
+	}
+
+	switch filter.Status {
+	case "":
+		filter.Status = achievementStatusUnapproved
+	case queueStatusAll:
+		filter.Status = ""
+	default:
+		if _, ok := achievementTransitions[filter.Status]; !ok {
+			return nil, nil, 0, errors.Join(validation.WrongInputErr, fmt.Errorf("Неизвестный статус %q", filter.Status))
+		}
+	}
+
+	modelAchievements, totalRecords, err := s.achievementRepo.GetAchievementQueue(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	modelCounts, err := s.achievementRepo.CountAchievementQueueByStatus(ctx, filter)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	achievements := make([]responses.QueueAchievement, len(modelAchievements))
+	for i, a := range modelAchievements {
+		achievements[i] = responses.QueueAchievement{
+			UUID:                a.UUID,
+			Comment:             a.Comment,
+			AttachmentLink:      a.AttachmentLink,
+			UserUUID:            a.UserUUID,
+			UserFullName:        strings.TrimSpace(strings.Join([]string{a.UserSecondName, a.UserName, a.UserPatronymic}, " ")),
+			CategoryUUID:        a.CategoryUUID,
+			CategoryName:        a.CategoryName,
+			Status:              a.Status,
+			StatusCode:          a.StatusCode,
+			AchievementDate:     a.AchievementDate,
+			CreatedAt:           a.CreatedAt,
+			PossibleDuplicateOf: a.PossibleDuplicateOf,
+		}
+		if a.Claim != nil {
+			claim := newAchievementClaimResponse(*a.Claim)
+			achievements[i].Claim = &claim
+		}
+	}
+
+	counts := make([]responses.AchievementStatusCount, len(modelCounts))
+	for i, count := range modelCounts {
+		counts[i] = responses.AchievementStatusCount{
+			Status:     count.Status,
+			StatusCode: count.StatusCode,
+			Total:      count.Total,
+		}
+	}
+
+	return achievements, counts, totalRecords, nil
+}
+
+// ClaimAchievement reserves the achievement for the current reviewer for the
+// configured lease, repeated claims by the same reviewer prolong it.
+func (s *achievementService) ClaimAchievement(ctx context.Context, uuid string) (responses.AchievementClaim, error) {
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, uuid)
+	if err != nil {
+		return responses.AchievementClaim{}, err
+	}
+
+	if !canTransitAchievement(achievement.StatusCode, achievementStatusApproved, achievementActorReviewer) &&
+		!canTransitAchievement(achievement.StatusCode, achievementStatusDeclined, achievementActorReviewer) {
+		return responses.AchievementClaim{}, errors.Join(conflict.InvalidTransitionErr, errors.New("Достижение не ожидает проверки"))
+	}
+
+	reviewerUUID := audit.MetaFromContext(ctx).ActorUUID
+	if reviewerUUID == "" {
+		return responses.AchievementClaim{}, authorization.UnauthorizedErr
+	}
+
+	if err = s.achievementRepo.ClaimAchievement(ctx, uuid, reviewerUUID, s.claimDuration); err != nil {
+		return responses.AchievementClaim{}, err
+	}
+
+	claim, err := s.achievementRepo.GetActiveAchievementClaim(ctx, uuid)
+	if err != nil {
+		return responses.AchievementClaim{}, err
+	}
+
+	return newAchievementClaimResponse(claim), nil
+}
+
+func (s *achievementService) ReleaseAchievementClaim(ctx context.Context, uuid string) error {
+	return s.achievementRepo.ReleaseAchievementClaim(ctx, uuid, audit.MetaFromContext(ctx).ActorUUID)
+}
+
+// checkAchievementClaim tells early who holds the claim of the achievement.
+// The repository consumes the claim of the current reviewer together with the
+// decision, so a claim that expires or changes hands after this check still
+// fails the decision with conflict.NotClaimedErr.
+func (s *achievementService) checkAchievementClaim(ctx context.Context, uuid string) error {
+	claim, err := s.achievementRepo.GetActiveAchievementClaim(ctx, uuid)
+	if err != nil {
+		if errors.Is(err, validation.NoDataFoundErr) {
+			return errors.Join(conflict.NotClaimedErr, errors.New("Сначала возьмите достижение на проверку"))
+		}
+		return err
+	}
+
+	if claim.ReviewerUUID != audit.MetaFromContext(ctx).ActorUUID {
+		return errors.Join(conflict.AlreadyClaimedErr, fmt.Errorf("Достижение проверяет %s %s", claim.ReviewerSecondName, claim.ReviewerName))
+	}
+
+	return nil
+}
+
+func newAchievementClaimResponse(claim models.AchievementClaim) responses.AchievementClaim {
+	return responses.AchievementClaim{
+		AchievementUUID: claim.AchievementUUID,
+		ReviewerUUID:    claim.ReviewerUUID,
+		ReviewerName: strings.TrimSpace(strings.Join([]string{
+			claim.ReviewerSecondName, claim.ReviewerName, claim.ReviewerPatronymic,
+		}, " ")),
+		ClaimedAt: claim.ClaimedAt,
+		ExpiresAt: claim.ExpiresAt,
+	}
+}
+
+func newAchievementReview(ctx context.Context, comment string) models.AchievementReview {
+	return models.AchievementReview{
+		ReviewerUUID: audit.MetaFromContext(ctx).ActorUUID,
+		Comment:      strings.TrimSpace(comment),
+	}
 }
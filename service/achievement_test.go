@@ -0,0 +1,567 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/errors/authorization"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/jwk"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	testOwnerUUID     = "00000000-0000-0000-0000-000000000001"
+	testReviewerUUID  = "00000000-0000-0000-0000-000000000002"
+	testStrangerUUID  = "00000000-0000-0000-0000-000000000003"
+	testColleagueUUID = "00000000-0000-0000-0000-000000000004"
+)
+
+// TestAchievementTransitions checks every pair of statuses for every actor
+// against the allowed transitions, listed here once more by hand.
+func TestAchievementTransitions(t *testing.T) {
+	owner, reviewer, system := achievementActorOwner, achievementActorReviewer, achievementActorSystem
+
+	type transition struct {
+		from, to string
+		actor    achievementActor
+	}
+	allowed := map[transition]bool{
+		{achievementStatusUnapproved, achievementStatusApproved, reviewer}: true,
+		{achievementStatusUnapproved, achievementStatusDeclined, reviewer}: true,
+		{achievementStatusUnapproved, achievementStatusRemoved, owner}:     true,
+		{achievementStatusUnapproved, achievementStatusRemoved, reviewer}:  true,
+		{achievementStatusApproved, achievementStatusUsed, system}:         true,
+		{achievementStatusApproved, achievementStatusDeclined, reviewer}:   true,
+		{achievementStatusApproved, achievementStatusRemoved, reviewer}:    true,
+		{achievementStatusDeclined, achievementStatusUnapproved, owner}:    true,
+		{achievementStatusDeclined, achievementStatusRemoved, owner}:       true,
+		{achievementStatusDeclined, achievementStatusRemoved, reviewer}:    true,
+	}
+
+	statuses := []string{
+		achievementStatusUnapproved, achievementStatusApproved, achievementStatusDeclined,
+		achievementStatusUsed, achievementStatusRemoved, "unknown",
+	}
+	for status := range achievementTransitions {
+		if !slices.Contains(statuses, status) {
+			t.Fatalf("status %s is missing from the test", status)
+		}
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			for _, actor := range []achievementActor{owner, reviewer, system} {
+				want := allowed[transition{from, to, actor}]
+
+				if got := canTransitAchievement(from, to, actor); got != want {
+					t.Errorf("canTransitAchievement(%s, %s, %d) = %v, want %v", from, to, actor, got, want)
+				}
+				if got := slices.Contains(achievementTransitions[from][to], actor); got != want {
+					t.Errorf("achievementTransitions[%s][%s] has %d = %v, want %v", from, to, actor, got, want)
+				}
+
+				err := checkAchievementTransition(from, to, actor)
+				if want && err != nil {
+					t.Errorf("checkAchievementTransition(%s, %s, %d) = %v, want nil", from, to, actor, err)
+				}
+				if !want && !errors.Is(err, conflict.InvalidTransitionErr) {
+					t.Errorf("checkAchievementTransition(%s, %s, %d) = %v, want InvalidTransitionErr", from, to, actor, err)
+				}
+			}
+		}
+	}
+}
+
+// fakePermissions grants the review permission to the listed users.
+type fakePermissions struct {
+	PermissionService
+
+	reviewers []string
+}
+
+func (p fakePermissions) CheckUserHasPermission(_ context.Context, userUUID string, url string) (bool, error) {
+	return url == reviewAchievementResource && slices.Contains(p.reviewers, userUUID), nil
+}
+
+var testPermissions = fakePermissions{reviewers: []string{testReviewerUUID, testColleagueUUID}}
+
+// fakeAchievementRepo keeps achievements and review claims in memory and
+// records the status changes. A reviewer decision consumes the claim of the
+// reviewer like the repository does. Methods the tests do not need panic
+// through the nil embedded interface.
+type fakeAchievementRepo struct {
+	repository.AchievementRepository
+
+	achievements map[string]models.SimpleAchievement
+	claims       map[string]string
+	changeErr    error
+	changes      []fakeStatusChange
+
+	// beforeChange runs between the checks of the service and the change,
+	// where a concurrent request or the clock may interfere.
+	beforeChange func()
+}
+
+type fakeStatusChange struct {
+	from, to string
+}
+
+// newFakeAchievementRepo stores the achievements, each claimed by the test
+// reviewer.
+func newFakeAchievementRepo(achievements ...models.SimpleAchievement) *fakeAchievementRepo {
+	r := &fakeAchievementRepo{
+		achievements: make(map[string]models.SimpleAchievement),
+		claims:       make(map[string]string),
+	}
+	for _, a := range achievements {
+		r.achievements[a.UUID] = a
+		r.claims[a.UUID] = testReviewerUUID
+	}
+	return r
+}
+
+func (r *fakeAchievementRepo) GetSimpleUserAchievementByUUID(_ context.Context, uuid string) (models.SimpleAchievement, error) {
+	a, ok := r.achievements[uuid]
+	if !ok {
+		return models.SimpleAchievement{}, validation.NoDataFoundErr
+	}
+	return a, nil
+}
+
+func (r *fakeAchievementRepo) change(uuid, from, to string, claimed bool, review models.AchievementReview) error {
+	if r.beforeChange != nil {
+		r.beforeChange()
+	}
+	if r.changeErr != nil {
+		return r.changeErr
+	}
+	if claimed {
+		if r.claims[uuid] != review.ReviewerUUID {
+			return conflict.NotClaimedErr
+		}
+	}
+	delete(r.claims, uuid)
+
+	a := r.achievements[uuid]
+	if a.StatusCode != from {
+		return conflict.InvalidTransitionErr
+	}
+	a.StatusCode = to
+	r.achievements[uuid] = a
+
+	r.changes = append(r.changes, fakeStatusChange{from: from, to: to})
+	return nil
+}
+
+func (r *fakeAchievementRepo) MakeAchievementApproved(_ context.Context, uuid, from string, review models.AchievementReview, _ models.AuditEvent) error {
+	return r.change(uuid, from, achievementStatusApproved, true, review)
+}
+
+func (r *fakeAchievementRepo) MakeAchievementDeclined(_ context.Context, uuid, from string, review models.AchievementReview, _ models.AuditEvent) error {
+	return r.change(uuid, from, achievementStatusDeclined, true, review)
+}
+
+func (r *fakeAchievementRepo) MakeAchievementRemoved(_ context.Context, uuid, from string, review models.AchievementReview, _ models.AuditEvent) error {
+	return r.change(uuid, from, achievementStatusRemoved, false, review)
+}
+
+func (r *fakeAchievementRepo) MakeAchievementUnapproved(_ context.Context, uuid, from string, review models.AchievementReview, _ models.AuditEvent) error {
+	return r.change(uuid, from, achievementStatusUnapproved, false, review)
+}
+
+func (r *fakeAchievementRepo) ClaimAchievement(_ context.Context, uuid, reviewerUUID string, _ time.Duration) error {
+	if holder, ok := r.claims[uuid]; ok && holder != reviewerUUID {
+		return conflict.AlreadyClaimedErr
+	}
+	r.claims[uuid] = reviewerUUID
+	return nil
+}
+
+func (r *fakeAchievementRepo) ReleaseAchievementClaim(_ context.Context, uuid, reviewerUUID string) error {
+	if r.claims[uuid] != reviewerUUID {
+		return conflict.NotClaimedErr
+	}
+	delete(r.claims, uuid)
+	return nil
+}
+
+func (r *fakeAchievementRepo) GetActiveAchievementClaim(_ context.Context, uuid string) (models.AchievementClaim, error) {
+	holder, ok := r.claims[uuid]
+	if !ok {
+		return models.AchievementClaim{}, validation.NoDataFoundErr
+	}
+	return models.AchievementClaim{AchievementUUID: uuid, ReviewerUUID: holder}, nil
+}
+
+func actorContext(userUUID string) context.Context {
+	return jwk.WithClaims(context.Background(), jwk.SPSAccessClaims{User: jwk.User{UUID: userUUID}})
+}
+
+func TestAchievementStatusChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		actor   string
+		run     func(s AchievementService, ctx context.Context) error
+		wantTo  string
+		wantErr error
+	}{
+		{
+			name:   "reviewer approves unapproved",
+			status: achievementStatusUnapproved,
+			actor:  testReviewerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.ApproveAchievement(ctx, "a", "")
+			},
+			wantTo: achievementStatusApproved,
+		},
+		{
+			name:   "reviewer declines approved",
+			status: achievementStatusApproved,
+			actor:  testReviewerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.DeclineAchievement(ctx, "a", "Нет подтверждения")
+			},
+			wantTo: achievementStatusDeclined,
+		},
+		{
+			name:   "approving declined is refused",
+			status: achievementStatusDeclined,
+			actor:  testReviewerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.ApproveAchievement(ctx, "a", "")
+			},
+			wantErr: conflict.InvalidTransitionErr,
+		},
+		{
+			name:   "used is terminal",
+			status: achievementStatusUsed,
+			actor:  testReviewerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.RemoveAchievement(ctx, "a")
+			},
+			wantErr: conflict.InvalidTransitionErr,
+		},
+		{
+			name:   "owner resubmits declined",
+			status: achievementStatusDeclined,
+			actor:  testOwnerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.ResubmitAchievement(ctx, "a", "")
+			},
+			wantTo: achievementStatusUnapproved,
+		},
+		{
+			name:   "owner removes unapproved",
+			status: achievementStatusUnapproved,
+			actor:  testOwnerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.RemoveAchievement(ctx, "a")
+			},
+			wantTo: achievementStatusRemoved,
+		},
+		{
+			name:   "owner cannot remove approved",
+			status: achievementStatusApproved,
+			actor:  testOwnerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.RemoveAchievement(ctx, "a")
+			},
+			wantErr: conflict.InvalidTransitionErr,
+		},
+		{
+			name:   "another student cannot remove approved",
+			status: achievementStatusApproved,
+			actor:  testStrangerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.RemoveAchievement(ctx, "a")
+			},
+			wantErr: authorization.HasNoPermissionErr,
+		},
+		{
+			name:   "another student cannot remove unapproved",
+			status: achievementStatusUnapproved,
+			actor:  testStrangerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.RemoveAchievement(ctx, "a")
+			},
+			wantErr: authorization.HasNoPermissionErr,
+		},
+		{
+			name:   "another student cannot approve",
+			status: achievementStatusUnapproved,
+			actor:  testStrangerUUID,
+			run: func(s AchievementService, ctx context.Context) error {
+				return s.ApproveAchievement(ctx, "a", "")
+			},
+			wantErr: authorization.HasNoPermissionErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAchievementRepo(models.SimpleAchievement{
+				UUID:       "a",
+				StatusCode: tt.status,
+				UserUUID:   testOwnerUUID,
+			})
+			s := NewAchievementService(repo, nil, nil, nil, testPermissions, nil, 0, models.DuplicateCheck{})
+
+			err := tt.run(s, actorContext(tt.actor))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if len(repo.changes) != 0 {
+					t.Fatalf("status changed to %v on a refused transition", repo.changes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+
+			want := []fakeStatusChange{{from: tt.status, to: tt.wantTo}}
+			if len(repo.changes) != 1 || repo.changes[0] != want[0] {
+				t.Fatalf("changes = %v, want %v", repo.changes, want)
+			}
+		})
+	}
+}
+
+// TestAchievementConcurrentDecision covers the decision that loses the race:
+// the repository no longer finds the achievement in the checked status.
+func TestAchievementConcurrentDecision(t *testing.T) {
+	repo := newFakeAchievementRepo(models.SimpleAchievement{UUID: "a", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID})
+	repo.changeErr = conflict.InvalidTransitionErr
+	s := NewAchievementService(repo, nil, nil, nil, testPermissions, nil, 0, models.DuplicateCheck{})
+
+	err := s.DeclineAchievement(actorContext(testReviewerUUID), "a", "Дубликат")
+	if !errors.Is(err, conflict.InvalidTransitionErr) {
+		t.Fatalf("err = %v, want InvalidTransitionErr", err)
+	}
+}
+
+// TestAchievementLostClaim covers a claim that expired or changed hands
+// between the check and the decision: the decision must not go through on the
+// claim it checked.
+func TestAchievementLostClaim(t *testing.T) {
+	tests := []struct {
+		name      string
+		lose      func(claims map[string]string)
+		wantClaim string
+	}{
+		{
+			name: "expired",
+			lose: func(claims map[string]string) {
+				delete(claims, "a")
+			},
+		},
+		{
+			name: "taken over",
+			lose: func(claims map[string]string) {
+				claims["a"] = testColleagueUUID
+			},
+			wantClaim: testColleagueUUID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAchievementRepo(models.SimpleAchievement{UUID: "a", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID})
+			repo.beforeChange = func() { tt.lose(repo.claims) }
+			s := NewAchievementService(repo, nil, nil, nil, testPermissions, nil, 0, models.DuplicateCheck{})
+
+			err := s.ApproveAchievement(actorContext(testReviewerUUID), "a", "")
+			if !errors.Is(err, conflict.NotClaimedErr) {
+				t.Fatalf("err = %v, want NotClaimedErr", err)
+			}
+			if len(repo.changes) != 0 {
+				t.Fatalf("status changed to %v without the claim", repo.changes)
+			}
+			if repo.claims["a"] != tt.wantClaim {
+				t.Fatalf("claim is held by %q, want %q", repo.claims["a"], tt.wantClaim)
+			}
+		})
+	}
+}
+
+func TestAchievementClaimedByAnother(t *testing.T) {
+	repo := newFakeAchievementRepo(models.SimpleAchievement{UUID: "a", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID})
+	s := NewAchievementService(repo, nil, nil, nil, testPermissions, nil, 0, models.DuplicateCheck{})
+
+	err := s.ApproveAchievement(actorContext(testColleagueUUID), "a", "")
+	if !errors.Is(err, conflict.AlreadyClaimedErr) {
+		t.Fatalf("err = %v, want AlreadyClaimedErr", err)
+	}
+	if len(repo.changes) != 0 {
+		t.Fatalf("status changed to %v without the claim", repo.changes)
+	}
+}
+
+func TestCreateAchievementRequiresEvidence(t *testing.T) {
+	s := NewAchievementService(nil, nil, nil, nil, nil, nil, 0, models.DuplicateCheck{})
+
+	_, err := s.CreateAchievement(actorContext(testOwnerUUID), testOwnerUUID, requests.UpsertAchievement{
+		AttachmentLink: "  ",
+		CategoryUUID:   "c",
+	})
+	if !errors.Is(err, validation.WrongInputErr) || !errors.Is(err, errNoEvidence) {
+		t.Fatalf("err = %v, want errNoEvidence", err)
+	}
+}
+
+// TestAchievementSelfReview covers a reviewer deciding on their own
+// achievement.
+func TestAchievementSelfReview(t *testing.T) {
+	for _, decide := range []func(s AchievementService, ctx context.Context) error{
+		func(s AchievementService, ctx context.Context) error { return s.ApproveAchievement(ctx, "a", "") },
+		func(s AchievementService, ctx context.Context) error { return s.DeclineAchievement(ctx, "a", "Нет") },
+	} {
+		repo := newFakeAchievementRepo(models.SimpleAchievement{UUID: "a", StatusCode: achievementStatusUnapproved, UserUUID: testReviewerUUID})
+		s := NewAchievementService(repo, nil, nil, nil, testPermissions, nil, 0, models.DuplicateCheck{})
+
+		if err := decide(s, actorContext(testReviewerUUID)); !errors.Is(err, authorization.HasNoPermissionErr) {
+			t.Fatalf("err = %v, want HasNoPermissionErr", err)
+		}
+		if len(repo.changes) != 0 {
+			t.Fatalf("status changed to %v on a self-review", repo.changes)
+		}
+	}
+}
+
+func TestReviewAchievements(t *testing.T) {
+	repo := newFakeAchievementRepo(
+		models.SimpleAchievement{UUID: "approve", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID},
+		models.SimpleAchievement{UUID: "decline", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID},
+		models.SimpleAchievement{UUID: "approved", StatusCode: achievementStatusApproved, UserUUID: testOwnerUUID},
+		models.SimpleAchievement{UUID: "taken", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID},
+		models.SimpleAchievement{UUID: "no-comment", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID},
+		models.SimpleAchievement{UUID: "own", StatusCode: achievementStatusUnapproved, UserUUID: testReviewerUUID},
+	)
+	repo.claims = map[string]string{"taken": testColleagueUUID}
+	s := NewAchievementService(repo, nil, nil, nil, testPermissions, nil, 0, models.DuplicateCheck{})
+
+	items := []requests.BatchReviewItem{
+		{UUID: "approve", Decision: reviewDecisionApprove},
+		{UUID: "decline", Decision: reviewDecisionDecline, Comment: "Дубликат"},
+		{UUID: "approved", Decision: reviewDecisionApprove},
+		{UUID: "taken", Decision: reviewDecisionApprove},
+		{UUID: "no-comment", Decision: reviewDecisionDecline},
+		{UUID: "own", Decision: reviewDecisionApprove},
+		{UUID: "approve", Decision: "maybe"},
+		{UUID: "missing", Decision: reviewDecisionApprove},
+	}
+	wantSuccess := []bool{true, true, false, false, false, false, false, false}
+
+	results, err := s.ReviewAchievements(actorContext(testReviewerUUID), items)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(results) != len(items) {
+		t.Fatalf("got %d results, want %d", len(results), len(items))
+	}
+	for i, result := range results {
+		if result.UUID != items[i].UUID || result.Decision != items[i].Decision {
+			t.Errorf("results[%d] = %s %s, want %s %s", i, result.UUID, result.Decision, items[i].UUID, items[i].Decision)
+		}
+		if result.Success != wantSuccess[i] {
+			t.Errorf("results[%d].Success = %v, want %v (%s)", i, result.Success, wantSuccess[i], result.Error)
+		}
+		if result.Success == (result.Error != "") {
+			t.Errorf("results[%d] success %v with error %q", i, result.Success, result.Error)
+		}
+	}
+
+	wantChanges := []fakeStatusChange{
+		{from: achievementStatusUnapproved, to: achievementStatusApproved},
+		{from: achievementStatusUnapproved, to: achievementStatusDeclined},
+	}
+	if !slices.Equal(repo.changes, wantChanges) {
+		t.Fatalf("changes = %v, want %v", repo.changes, wantChanges)
+	}
+
+	// Refused items are never left claimed by the reviewer.
+	wantClaims := map[string]string{"taken": testColleagueUUID}
+	if !maps.Equal(repo.claims, wantClaims) {
+		t.Fatalf("claims = %v, want %v", repo.claims, wantClaims)
+	}
+}
+
+// TestReviewAchievementsReleasesClaim covers a decision that fails after the
+// achievement was claimed for it.
+func TestReviewAchievementsReleasesClaim(t *testing.T) {
+	repo := newFakeAchievementRepo(models.SimpleAchievement{UUID: "a", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID})
+	repo.claims = map[string]string{}
+	repo.changeErr = conflict.InvalidTransitionErr
+	s := NewAchievementService(repo, nil, nil, nil, testPermissions, nil, 0, models.DuplicateCheck{})
+
+	results, err := s.ReviewAchievements(actorContext(testReviewerUUID), []requests.BatchReviewItem{
+		{UUID: "a", Decision: reviewDecisionApprove},
+	})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if results[0].Success {
+		t.Fatal("the failed decision is reported as a success")
+	}
+	if holder, ok := repo.claims["a"]; ok {
+		t.Fatalf("the achievement is left claimed by %s", holder)
+	}
+}
+
+func TestReviewAchievementsLimit(t *testing.T) {
+	repo := newFakeAchievementRepo()
+	s := NewAchievementService(repo, nil, nil, nil, testPermissions, nil, 0, models.DuplicateCheck{})
+	ctx := actorContext(testReviewerUUID)
+
+	items := make([]requests.BatchReviewItem, maxBatchReviewItems+1)
+	for i := range items {
+		uuid := fmt.Sprintf("a%d", i)
+		items[i] = requests.BatchReviewItem{UUID: uuid, Decision: reviewDecisionApprove}
+		repo.achievements[uuid] = models.SimpleAchievement{UUID: uuid, StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID}
+	}
+
+	if _, err := s.ReviewAchievements(ctx, nil); !errors.Is(err, validation.WrongInputErr) {
+		t.Fatalf("empty batch: err = %v, want WrongInputErr", err)
+	}
+
+	if _, err := s.ReviewAchievements(ctx, items); !errors.Is(err, validation.WrongInputErr) {
+		t.Fatalf("%d items: err = %v, want WrongInputErr", len(items), err)
+	}
+	if len(repo.changes) != 0 {
+		t.Fatalf("an oversized batch changed %d achievements", len(repo.changes))
+	}
+
+	results, err := s.ReviewAchievements(ctx, items[:maxBatchReviewItems])
+	if err != nil {
+		t.Fatalf("%d items: err = %v", maxBatchReviewItems, err)
+	}
+	for i, result := range results {
+		if !result.Success {
+			t.Fatalf("results[%d] failed: %s", i, result.Error)
+		}
+	}
+	if len(repo.changes) != maxBatchReviewItems {
+		t.Fatalf("changed %d achievements, want %d", len(repo.changes), maxBatchReviewItems)
+	}
+}
+
+func TestAchievementQueuePagination(t *testing.T) {
+	s := NewAchievementService(nil, nil, nil, nil, nil, nil, 0, models.DuplicateCheck{})
+
+	for _, page := range [][2]int{{-1, 0}, {10, -1}} {
+		_, _, _, err := s.GetAchievementQueue(context.Background(), models.AchievementQueueFilter{}, page[0], page[1])
+		if !errors.Is(err, validation.WrongInputErr) {
+			t.Fatalf("limit %d offset %d: err = %v, want WrongInputErr", page[0], page[1], err)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/igntnk/scholarship_point_system/audit"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/authorization"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/jwk"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/igntnk/scholarship_point_system/storage"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// reviewAchievementResource is the permission reviewers already need to
+	// approve achievements, it also lets them read attachments of other users.
+	reviewAchievementResource = "PUT - /achievement/approve/:var"
+
+	sniffLength = 512
+)
+
+type AttachmentService interface {
+	UploadAttachment(ctx context.Context, achievementUUID string, upload requests.UploadAttachment) (responses.Attachment, error)
+	GetAttachments(ctx context.Context, achievementUUID string) ([]responses.Attachment, error)
+	OpenAttachment(ctx context.Context, achievementUUID, attachmentUUID string) (responses.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, achievementUUID, attachmentUUID string) error
+	BackfillEvidenceFingerprints(ctx context.Context) (models.FingerprintBackfill, error)
+}
+
+type attachmentService struct {
+	attachmentRepo    repository.AttachmentRepository
+	achievementRepo   repository.AchievementRepository
+	permissionService PermissionService
+	store             storage.BlobStore
+	limits            models.AttachmentLimits
+}
+
+func NewAttachmentService(
+	attachmentRepo repository.AttachmentRepository,
+	achievementRepo repository.AchievementRepository,
+	permissionService PermissionService,
+	store storage.BlobStore,
+	limits models.AttachmentLimits,
+) AttachmentService {
+	return &attachmentService{
+		attachmentRepo:    attachmentRepo,
+		achievementRepo:   achievementRepo,
+		permissionService: permissionService,
+		store:             store,
+		limits:            limits,
+	}
+}
+
+func (s *attachmentService) UploadAttachment(ctx context.Context, achievementUUID string, upload requests.UploadAttachment) (responses.Attachment, error) {
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, achievementUUID)
+	if err != nil {
+		return responses.Attachment{}, err
+	}
+
+	if !canChangeAchievement(ctx, achievement) {
+		return responses.Attachment{}, errors.Join(authorization.HasNoPermissionErr, errors.New("Прикреплять файлы можно только к своему достижению"))
+	}
+
+	if !isAchievementEditable(achievement.StatusCode) {
+		return responses.Attachment{}, errors.Join(conflict.InvalidTransitionErr, errors.New("Достижение уже проверено"))
+	}
+
+	if upload.Size <= 0 {
+		return responses.Attachment{}, errors.Join(validation.WrongInputErr, errors.New("Пустой файл"))
+	}
+
+	if upload.Size > s.limits.MaxFileSize {
+		return responses.Attachment{}, errors.Join(validation.WrongInputErr, fmt.Errorf("Размер файла превышает %d байт", s.limits.MaxFileSize))
+	}
+
+	count, err := s.attachmentRepo.CountAttachments(ctx, achievementUUID)
+	if err != nil {
+		return responses.Attachment{}, err
+	}
+
+	if count >= s.limits.MaxAttachments {
+		return responses.Attachment{}, errors.Join(validation.WrongInputErr, fmt.Errorf("К достижению можно прикрепить не более %d файлов", s.limits.MaxAttachments))
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return responses.Attachment{}, errors.Join(err, unexpected.InternalErr)
+	}
+	head = head[:n]
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !slices.Contains(s.limits.AllowedContentTypes, contentType) {
+		return responses.Attachment{}, errors.Join(validation.WrongInputErr, fmt.Errorf("Недопустимый тип файла %s", contentType))
+	}
+
+	attachment := models.Attachment{
+		UUID:            uuid.NewString(),
+		AchievementUUID: achievementUUID,
+		FileName:        sanitizeFileName(upload.FileName),
+		ContentType:     contentType,
+		Size:            upload.Size,
+		UploadedBy:      audit.MetaFromContext(ctx).ActorUUID,
+		CreatedAt:       time.Now().Format(time.RFC3339),
+	}
+	attachment.StorageKey = "achievements/" + achievementUUID + "/" + attachment.UUID
+
+	hash := sha256.New()
+	content := io.TeeReader(io.LimitReader(io.MultiReader(bytes.NewReader(head), upload.Content), upload.Size), hash)
+	if err = s.store.Put(ctx, attachment.StorageKey, content, upload.Size, contentType); err != nil {
+		return responses.Attachment{}, errors.Join(err, unexpected.InternalErr)
+	}
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if err = s.attachmentRepo.CreateAttachment(ctx, attachment); err != nil {
+		// The insert often fails because the request was canceled; the blob
+		// is removed regardless so no file is left without its row.
+		if deleteErr := s.store.Delete(context.WithoutCancel(ctx), attachment.StorageKey); deleteErr != nil {
+			return responses.Attachment{}, errors.Join(err, fmt.Errorf("Не удалось удалить файл %s: %w", attachment.StorageKey, deleteErr))
+		}
+		return responses.Attachment{}, err
+	}
+
+	return newAttachmentResponse(attachment), nil
+}
+
+func (s *attachmentService) GetAttachments(ctx context.Context, achievementUUID string) ([]responses.Attachment, error) {
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, achievementUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.checkCanView(ctx, achievement); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.GetAttachments(ctx, achievementUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]responses.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		resp[i] = newAttachmentResponse(attachment)
+	}
+
+	return resp, nil
+}
+
+func (s *attachmentService) OpenAttachment(ctx context.Context, achievementUUID, attachmentUUID string) (responses.Attachment, io.ReadCloser, error) {
+	achievement, attachment, err := s.getAttachment(ctx, achievementUUID, attachmentUUID)
+	if err != nil {
+		return responses.Attachment{}, nil, err
+	}
+
+	if err = s.checkCanView(ctx, achievement); err != nil {
+		return responses.Attachment{}, nil, err
+	}
+
+	content, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return responses.Attachment{}, nil, validation.NoDataFoundErr
+		}
+		return responses.Attachment{}, nil, errors.Join(err, unexpected.InternalErr)
+	}
+
+	return newAttachmentResponse(attachment), content, nil
+}
+
+func (s *attachmentService) DeleteAttachment(ctx context.Context, achievementUUID, attachmentUUID string) error {
+	achievement, attachment, err := s.getAttachment(ctx, achievementUUID, attachmentUUID)
+	if err != nil {
+		return err
+	}
+
+	if !canChangeAchievement(ctx, achievement) {
+		return errors.Join(authorization.HasNoPermissionErr, errors.New("Удалять файлы можно только у своего достижения"))
+	}
+
+	if !isAchievementEditable(achievement.StatusCode) {
+		return errors.Join(conflict.InvalidTransitionErr, errors.New("Достижение уже проверено"))
+	}
+
+	if strings.TrimSpace(achievement.AttachmentLink) == "" {
+		count, err := s.attachmentRepo.CountAttachments(ctx, achievementUUID)
+		if err != nil {
+			return err
+		}
+		if count <= 1 {
+			return errors.Join(validation.WrongInputErr, errNoEvidence)
+		}
+	}
+
+	if err = s.attachmentRepo.DeleteAttachment(ctx, attachment.UUID); err != nil {
+		return err
+	}
+
+	if err = s.store.Delete(ctx, attachment.StorageKey); err != nil {
+		return errors.Join(err, unexpected.InternalErr)
+	}
+
+	return nil
+}
+
+// BackfillEvidenceFingerprints hashes files and normalizes links stored before
+// duplicate detection existed. Rows that already have a fingerprint are skipped,
+// so running it on every start is cheap. A missing or unreadable blob does not
+// stop the others; the attachment is reported as skipped.
+func (s *attachmentService) BackfillEvidenceFingerprints(ctx context.Context) (models.FingerprintBackfill, error) {
+	var backfill models.FingerprintBackfill
+
+	updated, err := s.achievementRepo.FillMissingLinkFingerprints(ctx)
+	if err != nil {
+		return backfill, err
+	}
+	backfill.Updated = updated
+
+	attachments, err := s.attachmentRepo.GetAttachmentsWithoutHash(ctx)
+	if err != nil {
+		return backfill, err
+	}
+
+	for _, attachment := range attachments {
+		if err = ctx.Err(); err != nil {
+			return backfill, err
+		}
+
+		hash, err := s.hashBlob(ctx, attachment.StorageKey)
+		if err != nil {
+			backfill.Skipped = append(backfill.Skipped, models.SkippedAttachment{Attachment: attachment, Err: err})
+			continue
+		}
+
+		if err = s.attachmentRepo.SetAttachmentHash(ctx, attachment.UUID, hash); err != nil {
+			return backfill, err
+		}
+		backfill.Updated++
+	}
+
+	return backfill, nil
+}
+
+func (s *attachmentService) hashBlob(ctx context.Context, key string) (string, error) {
+	content, err := s.store.Get(ctx, key)
+	if err != nil {
+		return "", errors.Join(err, unexpected.InternalErr)
+	}
+	defer content.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, content); err != nil {
+		return "", errors.Join(err, unexpected.InternalErr)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (s *attachmentService) getAttachment(ctx context.Context, achievementUUID, attachmentUUID string) (models.SimpleAchievement, models.Attachment, error) {
+	achievement, err := s.achievementRepo.GetSimpleUserAchievementByUUID(ctx, achievementUUID)
+	if err != nil {
+		return models.SimpleAchievement{}, models.Attachment{}, err
+	}
+
+	attachment, err := s.attachmentRepo.GetAttachment(ctx, attachmentUUID)
+	if err != nil {
+		return models.SimpleAchievement{}, models.Attachment{}, err
+	}
+
+	if attachment.AchievementUUID != achievement.UUID {
+		return models.SimpleAchievement{}, models.Attachment{}, validation.NoDataFoundErr
+	}
+
+	return achievement, attachment, nil
+}
+
+// checkCanView lets the owner, admins and reviewers read the evidence.
+func (s *attachmentService) checkCanView(ctx context.Context, achievement models.SimpleAchievement) error {
+	if canChangeAchievement(ctx, achievement) {
+		return nil
+	}
+
+	ok, err := s.permissionService.CheckUserHasPermission(ctx, audit.MetaFromContext(ctx).ActorUUID, reviewAchievementResource)
+	if err != nil || !ok {
+		return errors.Join(authorization.HasNoPermissionErr, errors.New("Нет доступа к вложениям этого достижения"))
+	}
+
+	return nil
+}
+
+// isAchievementReviewer reports whether the current user may review
+// achievements of other users.
+func isAchievementReviewer(ctx context.Context, permissionService PermissionService) (bool, error) {
+	claims := jwk.ClaimsFromContext(ctx)
+	if claims == nil {
+		return false, nil
+	}
+
+	if claims.IsAdmin {
+		return true, nil
+	}
+
+	return permissionService.CheckUserHasPermission(ctx, claims.User.UUID, reviewAchievementResource)
+}
+
+func canChangeAchievement(ctx context.Context, achievement models.SimpleAchievement) bool {
+	claims := jwk.ClaimsFromContext(ctx)
+	if claims == nil {
+		return false
+	}
+
+	return claims.IsAdmin || claims.User.UUID == achievement.UserUUID
+}
+
+func sanitizeFileName(name string) string {
+	name = name[strings.LastIndexAny(name, `/\`)+1:]
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "" {
+		return "file"
+	}
+
+	return name
+}
+
+func newAttachmentResponse(attachment models.Attachment) responses.Attachment {
+	return responses.Attachment{
+		UUID:            attachment.UUID,
+		AchievementUUID: attachment.AchievementUUID,
+		FileName:        attachment.FileName,
+		ContentType:     attachment.ContentType,
+		Size:            attachment.Size,
+		UploadedBy:      attachment.UploadedBy,
+		SHA256:          attachment.SHA256,
+		CreatedAt:       attachment.CreatedAt,
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/igntnk/scholarship_point_system/storage"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// fakeBlobStore keeps blobs in memory. Like a real store it refuses to work
+// with a canceled context. Reading a blob listed in unreadable fails midway.
+type fakeBlobStore struct {
+	blobs      map[string][]byte
+	unreadable map[string]error
+}
+
+func (s *fakeBlobStore) Put(ctx context.Context, key string, r io.Reader, _ int64, _ string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.blobs[key] = content
+	return nil
+}
+
+func (s *fakeBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	content, ok := s.blobs[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	if err, ok := s.unreadable[key]; ok {
+		return io.NopCloser(iotest.ErrReader(err)), nil
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (s *fakeBlobStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delete(s.blobs, key)
+	return nil
+}
+
+// fakeAttachmentRepo stores attachment rows in memory. createErr fails the
+// insert after cancel is called, as a request canceled mid-transaction does.
+type fakeAttachmentRepo struct {
+	repository.AttachmentRepository
+
+	attachments map[string]models.Attachment
+	createErr   error
+	cancel      context.CancelFunc
+}
+
+func (r *fakeAttachmentRepo) GetAttachmentsWithoutHash(context.Context) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	for _, attachment := range r.attachments {
+		if attachment.SHA256 == "" {
+			attachments = append(attachments, attachment)
+		}
+	}
+	slices.SortFunc(attachments, func(a, b models.Attachment) int { return strings.Compare(a.UUID, b.UUID) })
+	return attachments, nil
+}
+
+func (r *fakeAttachmentRepo) SetAttachmentHash(_ context.Context, uuid, hash string) error {
+	attachment := r.attachments[uuid]
+	attachment.SHA256 = hash
+	r.attachments[uuid] = attachment
+	return nil
+}
+
+func (r *fakeAttachmentRepo) CountAttachments(context.Context, string) (int, error) {
+	return len(r.attachments), nil
+}
+
+func (r *fakeAttachmentRepo) CreateAttachment(_ context.Context, attachment models.Attachment) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.attachments[attachment.UUID] = attachment
+	return nil
+}
+
+func newTestAttachmentService(attachments *fakeAttachmentRepo, store *fakeBlobStore) AttachmentService {
+	achievements := newFakeAchievementRepo(models.SimpleAchievement{UUID: "a", StatusCode: achievementStatusUnapproved, UserUUID: testOwnerUUID})
+	return NewAttachmentService(attachments, achievements, testPermissions, store, models.AttachmentLimits{
+		MaxFileSize:         1 << 10,
+		MaxAttachments:      5,
+		AllowedContentTypes: []string{"text/plain"},
+	})
+}
+
+func testUpload() requests.UploadAttachment {
+	content := "Диплом победителя олимпиады"
+	return requests.UploadAttachment{FileName: "diploma.txt", Size: int64(len(content)), Content: bytes.NewReader([]byte(content))}
+}
+
+func TestUploadAttachment(t *testing.T) {
+	store := &fakeBlobStore{blobs: map[string][]byte{}}
+	attachments := &fakeAttachmentRepo{attachments: map[string]models.Attachment{}}
+	s := newTestAttachmentService(attachments, store)
+
+	attachment, err := s.UploadAttachment(actorContext(testOwnerUUID), "a", testUpload())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	stored, ok := attachments.attachments[attachment.UUID]
+	if !ok {
+		t.Fatal("the attachment row is not created")
+	}
+	if keys := slices.Collect(maps.Keys(store.blobs)); !slices.Equal(keys, []string{stored.StorageKey}) {
+		t.Fatalf("blobs = %v, want %s", keys, stored.StorageKey)
+	}
+}
+
+// TestUploadAttachmentCleanup covers a failed insert after the blob was
+// stored: the blob must not outlive it, even when the request is canceled.
+func TestUploadAttachmentCleanup(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel bool
+	}{
+		{name: "insert fails"},
+		{name: "request canceled", cancel: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(actorContext(testOwnerUUID))
+			defer cancel()
+
+			store := &fakeBlobStore{blobs: map[string][]byte{}}
+			attachments := &fakeAttachmentRepo{attachments: map[string]models.Attachment{}, createErr: unexpected.RequestErr}
+			if tt.cancel {
+				attachments.cancel = cancel
+			}
+			s := newTestAttachmentService(attachments, store)
+
+			_, err := s.UploadAttachment(ctx, "a", testUpload())
+			if !errors.Is(err, unexpected.RequestErr) {
+				t.Fatalf("err = %v, want RequestErr", err)
+			}
+			if len(store.blobs) != 0 {
+				t.Fatalf("blobs %v are left without attachment rows", slices.Collect(maps.Keys(store.blobs)))
+			}
+		})
+	}
+}
+
+// TestBackfillEvidenceFingerprints covers blobs lost or broken in the store:
+// they are skipped and the other attachments still get their fingerprints.
+func TestBackfillEvidenceFingerprints(t *testing.T) {
+	readErr := errors.New("disk read error")
+	store := &fakeBlobStore{
+		blobs: map[string][]byte{
+			"achievements/a/1": []byte("first"),
+			"achievements/a/3": []byte("broken"),
+			"achievements/a/4": []byte("last"),
+		},
+		unreadable: map[string]error{"achievements/a/3": readErr},
+	}
+	attachments := &fakeAttachmentRepo{attachments: map[string]models.Attachment{}}
+	for _, uuid := range []string{"1", "2", "3", "4"} {
+		attachments.attachments[uuid] = models.Attachment{UUID: uuid, AchievementUUID: "a", StorageKey: "achievements/a/" + uuid}
+	}
+	achievements := &fakeFingerprintRepo{filled: 2}
+	s := NewAttachmentService(attachments, achievements, testPermissions, store, models.AttachmentLimits{})
+
+	backfill, err := s.BackfillEvidenceFingerprints(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	if backfill.Updated != 4 {
+		t.Errorf("updated = %d, want 2 links and 2 files", backfill.Updated)
+	}
+
+	var skipped []string
+	for _, item := range backfill.Skipped {
+		skipped = append(skipped, item.Attachment.UUID)
+	}
+	if !slices.Equal(skipped, []string{"2", "3"}) {
+		t.Fatalf("skipped = %v, want 2 and 3", skipped)
+	}
+	if !errors.Is(backfill.Skipped[1].Err, readErr) {
+		t.Errorf("skipped 3 with %v, want the read error", backfill.Skipped[1].Err)
+	}
+
+	for uuid, attachment := range attachments.attachments {
+		if wantHash := uuid == "1" || uuid == "4"; wantHash != (attachment.SHA256 != "") {
+			t.Errorf("attachment %s has fingerprint %q", uuid, attachment.SHA256)
+		}
+	}
+}
+
+// fakeFingerprintRepo reports how many link fingerprints it filled.
+type fakeFingerprintRepo struct {
+	repository.AchievementRepository
+
+	filled int
+}
+
+func (r *fakeFingerprintRepo) FillMissingLinkFingerprints(context.Context) (int, error) {
+	return r.filled, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/igntnk/scholarship_point_system/audit"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+)
+
+type AuditService interface {
+	ListAuditEvents(ctx context.Context, filter models.AuditFilter, limit, offset int) ([]responses.AuditEvent, int, error)
+}
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *auditService) ListAuditEvents(ctx context.Context, filter models.AuditFilter, limit, offset int) ([]responses.AuditEvent, int, error) {
+	if limit < 0 || offset < 0 {
+		return nil, 0, errors.Join(validation.WrongInputErr, errors.New("Неверные параметры пагинации"))
+	}
+
+	modelEvents, totalRecords, err := s.auditRepo.ListAuditEvents(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp := make([]responses.AuditEvent, len(modelEvents))
+	for i, event := range modelEvents {
+		resp[i] = responses.AuditEvent{
+			UUID:       event.UUID,
+			ActorUUID:  event.ActorUUID,
+			Action:     event.Action,
+			EntityType: event.EntityType,
+			EntityID:   event.EntityID,
+			IP:         event.IP,
+			RequestID:  event.RequestID,
+			CreatedAt:  event.CreatedAt,
+		}
+		if before, ok := event.Before.(json.RawMessage); ok {
+			resp[i].Before = before
+		}
+		if after, ok := event.After.(json.RawMessage); ok {
+			resp[i].After = after
+		}
+	}
+
+	return resp, totalRecords, nil
+}
+
+func newAuditEvent(ctx context.Context, action, entityType, entityID string, before, after any) models.AuditEvent {
+	meta := audit.MetaFromContext(ctx)
+
+	return models.AuditEvent{
+		ActorUUID:  meta.ActorUUID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     before,
+		After:      after,
+		IP:         meta.IP,
+		RequestID:  meta.RequestID,
+	}
+}
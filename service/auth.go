@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/igntnk/scholarship_point_system/controllers/requests"
@@ -18,7 +19,9 @@ import (
 )
 
 type AuthService interface {
-	ActualizeAdmin(ctx context.Context, email, password string) (string, error)
+	// CreateAdmin creates the user of a new admin. An existing user is only
+	// returned when the request allows promoting it, promoted is then true.
+	CreateAdmin(ctx context.Context, admin requests.CreateAdmin) (userUUID string, promoted bool, err error)
 	ChangePassword(ctx context.Context, uuid, password string) error
 	SignIn(ctx context.Context, email, password string) (string, string, error)
 	SignUp(ctx context.Context, user requests.CreateUser) (string, string, string, error)
@@ -58,58 +61,63 @@ func NewAuthService(
 	}
 }
 
-func (s *authService) ActualizeAdmin(ctx context.Context, email, password string) (string, error) {
-	email = strings.TrimSpace(email)
-	password = strings.TrimSpace(password)
+func (s *authService) CreateAdmin(ctx context.Context, admin requests.CreateAdmin) (string, bool, error) {
+	email := strings.TrimSpace(admin.Email)
+	password := strings.TrimSpace(admin.Password)
 
 	if len(email) == 0 {
-		return "", authorization.HasNoEmailErr
-	}
-
-	if len(password) == 0 {
-		return "", authorization.HasNoPasswordErr
+		return "", false, authorization.HasNoEmailErr
 	}
 
 	user, err := s.userRepo.GetUserWithCredentialsByEmail(ctx, email)
-	if err != nil {
-		if !errors.Is(err, validation.NoDataFoundErr) && !errors.Is(err, pgx.ErrNoRows) {
-			return "", err
+	if err == nil {
+		if !admin.Promote {
+			return "", false, errors.Join(validation.RecordAlreadyExistsErr,
+				fmt.Errorf("Пользователь %s уже существует, для назначения его администратором укажите --promote", email))
 		}
+		return user.UUID, true, nil
+	}
+	if !errors.Is(err, authorization.WrongPasswordErr) && !errors.Is(err, pgx.ErrNoRows) {
+		return "", false, err
+	}
 
-		if err = s.passwordManager.ValidatePassword(password); err != nil {
-			return "", err
-		}
+	if len(password) == 0 {
+		return "", false, authorization.HasNoPasswordErr
+	}
 
-		hashedPassword, salt, err := s.passwordManager.HashPassword(password)
-		if err != nil {
-			return "", err
-		}
+	if err = s.passwordManager.ValidatePassword(password); err != nil {
+		return "", false, err
+	}
 
-		userUUID, err := s.userRepo.CreateUser(ctx, models.UserWithCredentials{
-			Name:            "Администратор",
-			SecondName:      "Главный",
-			GradeBookNumber: "0-0",
-			Email:           email,
-			HashedPassword:  hashedPassword,
-			Salt:            salt,
-		})
-		if err != nil {
-			return "", err
-		}
+	hashedPassword, salt, err := s.passwordManager.HashPassword(password)
+	if err != nil {
+		return "", false, err
+	}
 
-		return userUUID, nil
+	name := strings.TrimSpace(admin.Name)
+	if name == "" {
+		name = "Администратор"
 	}
 
-	userUUID := user.UUID
-	if err = s.passwordManager.CompareHashAndPassword(user.HashedPassword, user.Salt, password); err == nil {
-		return userUUID, nil
+	secondName := strings.TrimSpace(admin.SecondName)
+	if secondName == "" {
+		secondName = "Главный"
 	}
 
-	if err = s.ChangePassword(ctx, userUUID, password); err != nil {
-		return "", err
+	userUUID, err := s.userRepo.CreateUser(ctx, models.UserWithCredentials{
+		Name:            name,
+		SecondName:      secondName,
+		Patronymic:      strings.TrimSpace(admin.Patronymic),
+		GradeBookNumber: "0-0",
+		Email:           email,
+		HashedPassword:  hashedPassword,
+		Salt:            salt,
+	})
+	if err != nil {
+		return "", false, err
 	}
 
-	return userUUID, nil
+	return userUUID, false, nil
 }
 
 func (s *authService) ChangePassword(ctx context.Context, uuid, password string) error {
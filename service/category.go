@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"github.com/igntnk/scholarship_point_system/audit"
 	"github.com/igntnk/scholarship_point_system/controllers/requests"
 	"github.com/igntnk/scholarship_point_system/controllers/responses"
 	"github.com/igntnk/scholarship_point_system/db"
@@ -43,11 +44,16 @@ func (s categoryService) CreateCategory(
 	err error,
 ) {
 
+	rules, err := parseCategoryScoringRules(category.Rules, category.ParentUuid != "")
+	if err != nil {
+		return "", err
+	}
+
 	if category.ParentUuid != "" {
 		return s.categoryRepo.CreateSubCategory(ctx, category.Name, category.ParentUuid, category.Values)
 	}
 
-	return s.categoryRepo.CreateCategory(ctx, category.Name, category.Points)
+	return s.categoryRepo.CreateCategory(ctx, category.Name, category.Points, rules)
 }
 
 func (s categoryService) GetCategoryByUuid(
@@ -74,11 +80,23 @@ func (s categoryService) GetCategoryByUuid(
 	if err != nil {
 		return models.Category{}, errors.Join(err, parsing.OutputDataErr)
 	}
-	return models.Category{
+
+	category = models.Category{
 		UUID:   dbCategory.Uuid.String(),
 		Name:   dbCategory.Name,
 		Points: float32(dbFloat.Float64),
-	}, nil
+	}
+
+	if !dbCategory.ParentCategory.Valid {
+		rules, err := repository.CategoryScoringRulesFromDB(dbCategory.MaxPoints, dbCategory.MaxAchievements,
+			dbCategory.Multiplier, dbCategory.DiminishingFactor)
+		if err != nil {
+			return models.Category{}, err
+		}
+		category.Rules = &rules
+	}
+
+	return category, nil
 }
 
 func (s categoryService) GetParentCategoriesWithPagination(ctx context.Context, limit, offset int) ([]models.Category, int, error) {
@@ -101,11 +119,17 @@ func (s categoryService) GetParentCategoriesWithPagination(ctx context.Context,
 		if err != nil {
 			return nil, 0, errors.Join(err, parsing.OutputDataErr)
 		}
+		rules, err := repository.CategoryScoringRulesFromDB(dbCategory.MaxPoints, dbCategory.MaxAchievements,
+			dbCategory.Multiplier, dbCategory.DiminishingFactor)
+		if err != nil {
+			return nil, 0, err
+		}
 		categories[i] = models.Category{
 			UUID:                dbCategory.Uuid.String(),
 			Name:                dbCategory.Name,
 			Points:              float32(dbFloat.Float64),
 			SubcategoriesAmount: int(dbCategory.SubAmount),
+			Rules:               &rules,
 		}
 	}
 
@@ -127,11 +151,17 @@ func (s categoryService) GetParentCategories(ctx context.Context) ([]models.Cate
 		if err != nil {
 			return nil, errors.Join(err, parsing.OutputDataErr)
 		}
+		rules, err := repository.CategoryScoringRulesFromDB(dbCategory.MaxPoints, dbCategory.MaxAchievements,
+			dbCategory.Multiplier, dbCategory.DiminishingFactor)
+		if err != nil {
+			return nil, err
+		}
 		categories[i] = models.Category{
 			UUID:                dbCategory.Uuid.String(),
 			Name:                dbCategory.Name,
 			Points:              float32(dbFloat.Float64),
 			SubcategoriesAmount: int(dbCategory.SubAmount),
+			Rules:               &rules,
 		}
 	}
 
@@ -182,22 +212,51 @@ func (s categoryService) DeleteCategory(ctx context.Context, uuid string) error
 }
 
 func (s categoryService) UpdateCategory(ctx context.Context, category requests.UpdateCategory) error {
-	pgUUid := pgtype.UUID{}
-	if err := pgUUid.Scan(category.UUID); err != nil {
-		return errors.Join(err, parsing.InputDataErr)
+	before, err := s.GetCategoryByUuid(ctx, category.UUID)
+	if err != nil {
+		return err
 	}
 
-	err := s.categoryRepo.CheckCategoryExistsByUUID(ctx, pgUUid)
+	rules, err := parseCategoryScoringRules(category.Rules, len(category.Values) != 0)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return validation.NoDataFoundErr
-		}
 		return err
 	}
 
 	if len(category.Values) != 0 {
-		return s.categoryRepo.UpdateSubCategory(ctx, category.UUID, category.Name, category.Values)
+		before.Values, err = s.categoryRepo.GetCategoryValues(ctx, category.UUID)
+		if err != nil {
+			return err
+		}
+
+		event := newAuditEvent(ctx, audit.ActionCategoryUpdate, audit.EntityCategory, category.UUID, before, category)
+		return s.categoryRepo.UpdateSubCategory(ctx, category.UUID, category.Name, category.Values, event)
+	}
+
+	event := newAuditEvent(ctx, audit.ActionCategoryUpdate, audit.EntityCategory, category.UUID, before, category)
+	return s.categoryRepo.UpdateCategory(ctx, category.UUID, category.Name, category.Points, rules, event)
+}
+
+// parseCategoryScoringRules checks the rating rules of a category. Only root
+// categories have rules, a subcategory only adds the points of its values.
+func parseCategoryScoringRules(req requests.ScoringRules, subcategory bool) (models.CategoryScoringRules, error) {
+	rules := models.CategoryScoringRules{
+		MaxPoints:         req.MaxPoints,
+		MaxAchievements:   req.MaxAchievements,
+		Multiplier:        req.Multiplier,
+		DiminishingFactor: req.DiminishingFactor,
+	}
+
+	if subcategory && rules != (models.CategoryScoringRules{}) {
+		return rules, errors.Join(validation.WrongInputErr, errors.New("Правила начисления задаются только для корневой категории"))
+	}
+
+	if rules.MaxPoints < 0 || rules.MaxAchievements < 0 || rules.Multiplier < 0 {
+		return rules, errors.Join(validation.WrongInputErr, errors.New("Ограничения и множитель не могут быть отрицательными"))
+	}
+
+	if rules.DiminishingFactor < 0 || rules.DiminishingFactor > 1 {
+		return rules, errors.Join(validation.WrongInputErr, errors.New("Коэффициент убывания должен быть от 0 до 1"))
 	}
 
-	return s.categoryRepo.UpdateCategory(ctx, category.UUID, category.Name, category.Points)
+	return rules, nil
 }
@@ -1,14 +1,17 @@
 package models
 
+import "time"
+
 type SimpleAchievement struct {
-	UUID           string  `json:"uuid"`
-	AttachmentLink string  `json:"attachment_link"`
-	Status         string  `json:"status"`
-	CategoryName   string  `json:"category_name"`
-	CategoryUUID   string  `json:"category_uuid"`
-	AttachmentDate string  `json:"attachment_date,omitempty"`
-	PointAmount    float32 `json:"point_amount"`
-	Comment        string  `json:"comment"`
+	UUID           string `json:"uuid"`
+	AttachmentLink string `json:"attachment_link"`
+	Status         string `json:"status"`
+	StatusCode     string `json:"status_code"`
+	UserUUID       string `json:"user_uuid"`
+	CategoryName   string `json:"category_name"`
+	CategoryUUID   string `json:"category_uuid"`
+	AttachmentDate string `json:"attachment_date,omitempty"`
+	Comment        string `json:"comment"`
 }
 
 type Achievement struct {
@@ -19,3 +22,75 @@ type Achievement struct {
 	Comment        string
 	PointAmount    float32
 }
+
+type AchievementReview struct {
+	UUID               string
+	ReviewerUUID       string
+	ReviewerName       string
+	ReviewerSecondName string
+	ReviewerPatronymic string
+	FromStatus         string
+	ToStatus           string
+	ToStatusCode       string
+	Comment            string
+	CreatedAt          string
+}
+
+type AchievementQueueFilter struct {
+	Status       string
+	CategoryUUID string
+	CreatedFrom  string
+	CreatedTo    string
+	// GroupUUID is the org unit of kind group the students belong to.
+	GroupUUID string
+}
+
+type QueueAchievement struct {
+	UUID                string
+	Comment             string
+	AttachmentLink      string
+	UserUUID            string
+	UserName            string
+	UserSecondName      string
+	UserPatronymic      string
+	CategoryUUID        string
+	CategoryName        string
+	Status              string
+	StatusCode          string
+	AchievementDate     string
+	CreatedAt           string
+	PossibleDuplicateOf string
+	Claim               *AchievementClaim
+}
+
+type AchievementStatusCount struct {
+	Status     string
+	StatusCode string
+	Total      int
+}
+
+type AchievementClaim struct {
+	AchievementUUID    string
+	ReviewerUUID       string
+	ReviewerName       string
+	ReviewerSecondName string
+	ReviewerPatronymic string
+	ClaimedAt          string
+	ExpiresAt          string
+}
+
+// DuplicateCandidate is an earlier achievement of the same user in the same
+// category. SubcategoryValues are "<subcategory uuid>:<value name>" pairs.
+type DuplicateCandidate struct {
+	UUID              string
+	Comment           string
+	AchievementDate   time.Time
+	SubcategoryValues []string
+}
+
+// DuplicateCheck configures duplicate detection on achievement creation.
+type DuplicateCheck struct {
+	Disabled          bool
+	DateWindow        time.Duration
+	CommentSimilarity float64
+}
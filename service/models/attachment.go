@@ -0,0 +1,49 @@
+package models
+
+type Attachment struct {
+	UUID            string
+	AchievementUUID string
+	FileName        string
+	ContentType     string
+	Size            int64
+	StorageKey      string
+	UploadedBy      string
+	SHA256          string
+	CreatedAt       string
+}
+
+// AttachmentLimits restricts what students may upload as evidence.
+type AttachmentLimits struct {
+	MaxFileSize         int64
+	MaxAttachments      int
+	AllowedContentTypes []string
+}
+
+// FingerprintBackfill is the outcome of fingerprinting the evidence stored
+// before duplicate detection existed.
+type FingerprintBackfill struct {
+	Updated int
+	// Skipped are attachments whose blobs are missing or unreadable; they
+	// stay without a fingerprint until the next attempt.
+	Skipped []SkippedAttachment
+}
+
+type SkippedAttachment struct {
+	Attachment Attachment
+	Err        error
+}
+
+const (
+	EvidenceKindFile = "file"
+	EvidenceKindLink = "link"
+)
+
+// DuplicateEvidence is a file or link of an achievement that is also
+// attached to another, not removed, achievement.
+type DuplicateEvidence struct {
+	Kind            string
+	Evidence        string
+	DuplicateOf     string
+	AchievementUUID string
+	UserUUID        string
+}
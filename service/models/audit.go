@@ -0,0 +1,23 @@
+package models
+
+type AuditEvent struct {
+	UUID       string
+	ActorUUID  string
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+	IP         string
+	RequestID  string
+	CreatedAt  string
+}
+
+type AuditFilter struct {
+	ActorUUID   string
+	Action      string
+	EntityType  string
+	EntityID    string
+	CreatedFrom string
+	CreatedTo   string
+}
@@ -6,6 +6,18 @@ type Category struct {
 	Points              float32          `json:"points"`
 	SubcategoriesAmount int              `json:"subcategories_amount"`
 	Values              []CategoryValues `json:"values,omitempty"`
+	// Rules are set for root categories only.
+	Rules *CategoryScoringRules `json:"rules,omitempty"`
+}
+
+// CategoryScoringRules limit the points a student gets from one root category
+// in the rating. Zero values mean no limit, a multiplier of one and no
+// diminishing returns.
+type CategoryScoringRules struct {
+	MaxPoints         float64 `json:"max_points"`
+	MaxAchievements   int     `json:"max_achievements"`
+	Multiplier        float64 `json:"multiplier"`
+	DiminishingFactor float64 `json:"diminishing_factor"`
 }
 
 type CategoryValues struct {
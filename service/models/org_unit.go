@@ -0,0 +1,25 @@
+package models
+
+const (
+	OrgUnitKindFaculty    = "faculty"
+	OrgUnitKindDepartment = "department"
+	OrgUnitKindCourse     = "course"
+	OrgUnitKindGroup      = "group"
+)
+
+// OrgUnitKinds are ordered from the top of the hierarchy down. A unit only
+// contains units of the kinds after its own.
+var OrgUnitKinds = []string{OrgUnitKindFaculty, OrgUnitKindDepartment, OrgUnitKindCourse, OrgUnitKindGroup}
+
+// OrgUnit is a faculty, department, course or study group. WinnerQuota limits
+// the winners among the students of the unit and its nested units, zero leaves
+// them limited by the winner count only.
+type OrgUnit struct {
+	UUID        string
+	Name        string
+	Kind        string
+	ParentUUID  string
+	WinnerQuota int
+	UserAmount  int
+	CreatedAt   string
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	PeriodStatusOpen     = "open"
+	PeriodStatusClosed   = "closed"
+	PeriodStatusArchived = "archived"
+)
+
+// Period is an academic period (semester) that scopes achievements and the
+// rating. An achievement belongs to the period containing its achievement date.
+type Period struct {
+	UUID               string
+	Name               string
+	StartDate          time.Time
+	EndDate            time.Time
+	SubmissionDeadline time.Time
+	Status             string
+	WinnerCount        int
+	CreatedAt          string
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	RatingSnapshotReasonCloseOut = "close_out"
+	RatingSnapshotReasonManual   = "manual"
+)
+
+// AchievementPoints is the score of a single achievement together with the
+// student it belongs to. PointAmount is filled by the scoring service from
+// ScoreInput.
+type AchievementPoints struct {
+	AchievementUUID string
+	UserUUID        string
+	UserName        string
+	UserSecondName  string
+	UserPatronymic  string
+	GradebookNumber string
+	UserStatus      string
+	UserOrgUnitUUID string
+	CategoryUUID    string
+	CategoryName    string
+	SubmittedAt     time.Time
+	PointAmount     float64
+	ScoreInput      AchievementScoreInput
+}
+
+type RatingSnapshot struct {
+	UUID        string
+	PeriodUUID  string
+	PeriodName  string
+	Reason      string
+	CreatedBy   string
+	RuleVersion string
+	// WinnerRules describe how the winners were picked; they are hashed into
+	// the rule version together with the scoring rules.
+	WinnerRules string
+	CreatedAt   string
+	EntryAmount int
+	Entries     []RatingSnapshotEntry
+}
+
+type RatingSnapshotEntry struct {
+	UserUUID          string
+	FullName          string
+	GradebookNumber   string
+	Position          int
+	PointAmount       float64
+	AchievementAmount int
+	IsWinner          bool
+	// Achievements are the achievements counted for the student.
+	Achievements []AchievementPoints
+}
+
+type RatingSnapshotFilter struct {
+	PeriodUUID string
+}
+
+// PeriodCloseOut is the outcome of finalizing a period: the frozen ranking
+// and the achievements of the winners that become used.
+type PeriodCloseOut struct {
+	Period           Period
+	Snapshot         RatingSnapshot
+	UsedAchievements []string
+}
+
+const (
+	RatingSortPoints       = "points"
+	RatingSortName         = "name"
+	RatingSortAchievements = "achievements"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// RatingFilter selects and orders students of the live rating.
+type RatingFilter struct {
+	Search string
+	// Valid keeps only confirmed students.
+	Valid bool
+	// Winners keeps only the students of WinnerUUIDs, the winners picked by
+	// the selection rules.
+	Winners     bool
+	WinnerUUIDs []string
+	// Scores are the points of the counted achievements by uuid as computed
+	// by the scoring service. Other achievements are not counted. Without
+	// scores the stored all-time student scores are used.
+	Scores map[string]float64
+	// OrgUnitUUIDs ranks only the students of these units, nil selects all.
+	OrgUnitUUIDs []string
+	Ranking      RankingRules
+	SortBy       string
+	SortOrder    string
+	Limit        int
+	Offset       int
+}
+
+const (
+	// RankModeCompetition gives tied students the same rank and skips the
+	// following ranks (1, 2, 2, 4).
+	RankModeCompetition = "competition"
+	// RankModeDense gives tied students the same rank without gaps (1, 2, 2, 3).
+	RankModeDense = "dense"
+
+	// TieBreakerAchievements ranks the student with more achievements higher.
+	TieBreakerAchievements = "achievements"
+	// TieBreakerEarliestSubmission ranks the student whose last counted
+	// achievement was submitted earlier higher.
+	TieBreakerEarliestSubmission = "earliest_submission"
+)
+
+// RankingRules decide how students with equal points are ranked.
+type RankingRules struct {
+	Mode        string
+	TieBreakers []string
+}
+
+// WinnersList is the list of winners the committee signs. Header replaces the
+// configured report header when set.
+type WinnersList struct {
+	Header      string
+	PeriodName  string
+	StartDate   time.Time
+	EndDate     time.Time
+	WinnerCount int
+	GeneratedAt time.Time
+	Appendix    bool
+	Winners     []RatingSnapshotEntry
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	AchievementStatusUnapproved = "unapproved"
+	AchievementStatusApproved   = "approved"
+	AchievementStatusDeclined   = "declined"
+	AchievementStatusUsed       = "used"
+	AchievementStatusRemoved    = "removed"
+)
+
+// How an achievement takes part in the rating of its student.
+const (
+	ScoreStateCounted  = "counted"
+	ScoreStatePending  = "pending"
+	ScoreStateExcluded = "excluded"
+)
+
+// Why an achievement is excluded from the rating.
+const (
+	ScoreExclusionDeclined         = "declined"
+	ScoreExclusionRemoved          = "removed"
+	ScoreExclusionCategoryInactive = "category_inactive"
+	ScoreExclusionCategoryLimit    = "category_limit"
+)
+
+// LiveRatingStatuses are the achievement statuses counted by the live rating
+// and the stored student scores. Achievements still waiting for review are
+// counted as well.
+var LiveRatingStatuses = []string{AchievementStatusUnapproved, AchievementStatusApproved, AchievementStatusUsed}
+
+// WinnerStatuses are the achievement statuses winners are picked on, wherever
+// they are named: only reviewed and accepted achievements count.
+var WinnerStatuses = []string{AchievementStatusApproved, AchievementStatusUsed}
+
+// AchievementScoreInput holds everything the scoring rules look at for one
+// achievement: its root category and the subcategory values selected for it.
+type AchievementScoreInput struct {
+	AchievementUUID string
+	UserUUID        string
+	Status          string
+	AchievementDate time.Time
+	SubmittedAt     time.Time
+	CategoryUUID    string
+	CategoryName    string
+	CategoryPoints  float64
+	CategoryActive  bool
+	CategoryRules   CategoryScoringRules
+	Values          []ScoreValueInput
+}
+
+// ScoreValueInput is a subcategory value selected for an achievement. Counted
+// is false when the value, its subcategory or the link of the subcategory to
+// the root category of the achievement is no longer valid.
+type ScoreValueInput struct {
+	ValueUUID       string
+	ValueName       string
+	SubcategoryUUID string
+	SubcategoryName string
+	Points          float64
+	Counted         bool
+}
+
+// ScoringFilter selects the achievements to score. Empty fields do not
+// restrict the selection.
+type ScoringFilter struct {
+	AchievementUUIDs []string
+	UserUUIDs        []string
+	Statuses         []string
+	StartDate        time.Time
+	EndDate          time.Time
+}
+
+// StudentScore is the stored live rating total of a student.
+type StudentScore struct {
+	UserUUID           string
+	PointAmount        float64
+	AchievementAmount  int
+	AchievementProofed bool
+	LastSubmittedAt    time.Time
+}
+
+// ScoreBreakdown shows what the live rating total of a student consists of.
+// Pending achievements are waiting for review and are counted in the total
+// until they are declined.
+type ScoreBreakdown struct {
+	UserUUID    string
+	PointAmount float64
+	Categories  []CategoryScoreBreakdown
+}
+
+type CategoryScoreBreakdown struct {
+	CategoryUUID string
+	CategoryName string
+	PointAmount  float64
+	Achievements []AchievementScoreBreakdown
+}
+
+// AchievementScoreBreakdown holds the points of an achievement before the
+// category rules and the points it brings to the rating after them.
+type AchievementScoreBreakdown struct {
+	AchievementUUID string
+	Status          string
+	AchievementDate time.Time
+	State           string
+	ExclusionReason string
+	BasePoints      float64
+	PointAmount     float64
+	Values          []ScoreValueInput
+}
+
+// CategoryScores are the rating points of every student per root category.
+// Points are keyed by user uuid and then by category uuid.
+type CategoryScores struct {
+	Categories []ScoredCategory
+	Points     map[string]map[string]float64
+}
+
+type ScoredCategory struct {
+	UUID string
+	Name string
+}
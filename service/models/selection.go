@@ -0,0 +1,56 @@
+package models
+
+// Reasons a student is not selected as a winner.
+const (
+	SelectionReasonNotApproved   = "not_approved"
+	SelectionReasonUnverified    = "unverified_achievements"
+	SelectionReasonMinPoints     = "below_min_points"
+	SelectionReasonWinnerQuota   = "winner_quota"
+	SelectionReasonUnitQuota     = "unit_quota"
+	SelectionReasonCategoryQuota = "category_quota"
+)
+
+// SelectionRules decide who of the ranked students win. Zero values do not
+// restrict the selection, except for WinnerCount which always does.
+type SelectionRules struct {
+	WinnerCount int
+	// MinPoints is the least number of points a winner must have.
+	MinPoints float64
+	// RequireApproved only selects confirmed students.
+	RequireApproved bool
+	// RequireVerified only selects students whose counted achievements have
+	// all been reviewed.
+	RequireVerified bool
+	// UnitQuotas limit the winners of org units by uuid. A student counts
+	// towards the quota of the unit and of every unit above it.
+	UnitQuotas map[string]int
+	// UnitParents holds the parent of every org unit by uuid.
+	UnitParents map[string]string
+	// CategoryQuotas limit the winners by their leading category, the root
+	// category that brings them the most points.
+	CategoryQuotas map[string]int
+}
+
+// SelectionCandidate is a ranked student with what the rules look at.
+type SelectionCandidate struct {
+	Entry       RatingSnapshotEntry
+	OrgUnitUUID string
+	Approved    bool
+	Verified    bool
+}
+
+type SelectionReason struct {
+	Code string
+	// UUID is the org unit or the category of a quota reason.
+	UUID   string
+	Detail string
+}
+
+// SelectionResult explains the decision on one student. A student that is not
+// selected has at least one reason.
+type SelectionResult struct {
+	Candidate           SelectionCandidate
+	LeadingCategoryUUID string
+	Selected            bool
+	Reasons             []SelectionReason
+}
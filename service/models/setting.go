@@ -0,0 +1,20 @@
+package models
+
+// Setting is a stored setting value. Settings without one have their default.
+type Setting struct {
+	Key       string
+	Value     string
+	UpdatedAt string
+	UpdatedBy string
+}
+
+// SettingChange is an entry of the change history of a setting. The values
+// are in the stored form.
+type SettingChange struct {
+	UUID      string
+	Key       string
+	OldValue  string
+	NewValue  string
+	ChangedBy string
+	ChangedAt string
+}
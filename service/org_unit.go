@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/audit"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"slices"
+	"strings"
+)
+
+type OrgUnitService interface {
+	CreateOrgUnit(ctx context.Context, req requests.UpsertOrgUnit) (string, error)
+	GetOrgUnit(ctx context.Context, uuid string) (responses.OrgUnit, error)
+	ListOrgUnits(ctx context.Context) ([]responses.OrgUnit, error)
+	UpdateOrgUnit(ctx context.Context, req requests.UpsertOrgUnit) error
+	DeleteOrgUnit(ctx context.Context, uuid string) error
+	AssignUsers(ctx context.Context, uuid string, req requests.OrgUnitUsers) (responses.OrgUnitUsers, error)
+	UnassignUsers(ctx context.Context, uuid string, req requests.OrgUnitUsers) (responses.OrgUnitUsers, error)
+}
+
+type orgUnitService struct {
+	orgUnitRepo repository.OrgUnitRepository
+}
+
+func NewOrgUnitService(orgUnitRepo repository.OrgUnitRepository) OrgUnitService {
+	return &orgUnitService{
+		orgUnitRepo: orgUnitRepo,
+	}
+}
+
+func (s *orgUnitService) CreateOrgUnit(ctx context.Context, req requests.UpsertOrgUnit) (string, error) {
+	req.UUID = ""
+
+	unit, err := s.parseOrgUnit(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionOrgUnitCreate, audit.EntityOrgUnit, "", nil, newOrgUnitResponse(unit))
+
+	return s.orgUnitRepo.CreateOrgUnit(ctx, unit, event)
+}
+
+func (s *orgUnitService) GetOrgUnit(ctx context.Context, uuid string) (responses.OrgUnit, error) {
+	unit, err := s.orgUnitRepo.GetOrgUnitByUUID(ctx, uuid)
+	if err != nil {
+		return responses.OrgUnit{}, err
+	}
+
+	return newOrgUnitResponse(unit), nil
+}
+
+func (s *orgUnitService) ListOrgUnits(ctx context.Context) ([]responses.OrgUnit, error) {
+	units, err := s.orgUnitRepo.ListOrgUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]responses.OrgUnit, len(units))
+	for i, unit := range units {
+		resp[i] = newOrgUnitResponse(unit)
+	}
+
+	return resp, nil
+}
+
+func (s *orgUnitService) UpdateOrgUnit(ctx context.Context, req requests.UpsertOrgUnit) error {
+	before, err := s.orgUnitRepo.GetOrgUnitByUUID(ctx, req.UUID)
+	if err != nil {
+		return err
+	}
+
+	unit, err := s.parseOrgUnit(ctx, req)
+	if err != nil {
+		return err
+	}
+	unit.UserAmount = before.UserAmount
+
+	event := newAuditEvent(ctx, audit.ActionOrgUnitUpdate, audit.EntityOrgUnit, unit.UUID,
+		newOrgUnitResponse(before),
+		newOrgUnitResponse(unit),
+	)
+
+	return s.orgUnitRepo.UpdateOrgUnit(ctx, unit, event)
+}
+
+func (s *orgUnitService) DeleteOrgUnit(ctx context.Context, uuid string) error {
+	before, err := s.orgUnitRepo.GetOrgUnitByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionOrgUnitDelete, audit.EntityOrgUnit, uuid, newOrgUnitResponse(before), nil)
+
+	return s.orgUnitRepo.DeleteOrgUnit(ctx, uuid, event)
+}
+
+// AssignUsers moves the students into the unit, out of the unit they were in.
+func (s *orgUnitService) AssignUsers(ctx context.Context, uuid string, req requests.OrgUnitUsers) (responses.OrgUnitUsers, error) {
+	if _, err := s.orgUnitRepo.GetOrgUnitByUUID(ctx, uuid); err != nil {
+		return responses.OrgUnitUsers{}, err
+	}
+
+	if len(req.UserUUIDs) == 0 {
+		return responses.OrgUnitUsers{}, errors.Join(validation.WrongInputErr, errors.New("Не указаны студенты"))
+	}
+
+	event := newAuditEvent(ctx, audit.ActionOrgUnitAssign, audit.EntityOrgUnit, uuid, nil, req)
+
+	changed, err := s.orgUnitRepo.AssignUsers(ctx, uuid, req.UserUUIDs, event)
+	if err != nil {
+		return responses.OrgUnitUsers{}, err
+	}
+
+	return responses.OrgUnitUsers{UserAmount: changed}, nil
+}
+
+// UnassignUsers leaves the students of the unit without one. Students of other
+// units are not touched.
+func (s *orgUnitService) UnassignUsers(ctx context.Context, uuid string, req requests.OrgUnitUsers) (responses.OrgUnitUsers, error) {
+	if _, err := s.orgUnitRepo.GetOrgUnitByUUID(ctx, uuid); err != nil {
+		return responses.OrgUnitUsers{}, err
+	}
+
+	if len(req.UserUUIDs) == 0 {
+		return responses.OrgUnitUsers{}, errors.Join(validation.WrongInputErr, errors.New("Не указаны студенты"))
+	}
+
+	event := newAuditEvent(ctx, audit.ActionOrgUnitUnassign, audit.EntityOrgUnit, uuid, req, nil)
+
+	changed, err := s.orgUnitRepo.UnassignUsers(ctx, uuid, req.UserUUIDs, event)
+	if err != nil {
+		return responses.OrgUnitUsers{}, err
+	}
+
+	return responses.OrgUnitUsers{UserAmount: changed}, nil
+}
+
+// parseOrgUnit checks the unit against the hierarchy: the parent must exist
+// and be of an upper kind, the nested units of a lower one, and a unit can not
+// be moved into itself.
+func (s *orgUnitService) parseOrgUnit(ctx context.Context, req requests.UpsertOrgUnit) (models.OrgUnit, error) {
+	unit := models.OrgUnit{
+		UUID:        req.UUID,
+		Name:        strings.TrimSpace(req.Name),
+		Kind:        req.Kind,
+		ParentUUID:  req.ParentUUID,
+		WinnerQuota: req.WinnerQuota,
+	}
+
+	if unit.Name == "" {
+		return models.OrgUnit{}, errors.Join(validation.WrongInputErr, errors.New("Не указано название подразделения"))
+	}
+
+	level := slices.Index(models.OrgUnitKinds, unit.Kind)
+	if level < 0 {
+		return models.OrgUnit{}, errors.Join(validation.WrongInputErr, fmt.Errorf("Неизвестный тип подразделения %q", unit.Kind))
+	}
+
+	if unit.WinnerQuota < 0 {
+		return models.OrgUnit{}, errors.Join(validation.WrongInputErr, errors.New("Квота победителей не может быть отрицательной"))
+	}
+
+	if unit.ParentUUID == "" && unit.UUID == "" {
+		return unit, nil
+	}
+
+	units, err := s.orgUnitRepo.ListOrgUnits(ctx)
+	if err != nil {
+		return models.OrgUnit{}, err
+	}
+
+	byUUID := make(map[string]models.OrgUnit, len(units))
+	for _, u := range units {
+		byUUID[u.UUID] = u
+	}
+
+	if unit.ParentUUID != "" {
+		parent, ok := byUUID[unit.ParentUUID]
+		if !ok {
+			return models.OrgUnit{}, errors.Join(validation.WrongInputErr, errors.New("Родительское подразделение не найдено"))
+		}
+
+		if slices.Index(models.OrgUnitKinds, parent.Kind) >= level {
+			return models.OrgUnit{}, errors.Join(validation.WrongInputErr,
+				fmt.Errorf("Подразделение типа %q не может входить в подразделение типа %q", unit.Kind, parent.Kind))
+		}
+
+		for ancestor := parent; ; ancestor = byUUID[ancestor.ParentUUID] {
+			if unit.UUID != "" && ancestor.UUID == unit.UUID {
+				return models.OrgUnit{}, errors.Join(validation.WrongInputErr, errors.New("Подразделение не может входить само в себя"))
+			}
+			if ancestor.ParentUUID == "" {
+				break
+			}
+		}
+	}
+
+	for _, child := range units {
+		if unit.UUID != "" && child.ParentUUID == unit.UUID && slices.Index(models.OrgUnitKinds, child.Kind) <= level {
+			return models.OrgUnit{}, errors.Join(validation.WrongInputErr,
+				fmt.Errorf("Подразделение типа %q не может содержать подразделение типа %q", unit.Kind, child.Kind))
+		}
+	}
+
+	return unit, nil
+}
+
+func newOrgUnitResponse(unit models.OrgUnit) responses.OrgUnit {
+	return responses.OrgUnit{
+		UUID:        unit.UUID,
+		Name:        unit.Name,
+		Kind:        unit.Kind,
+		ParentUUID:  unit.ParentUUID,
+		WinnerQuota: unit.WinnerQuota,
+		UserAmount:  unit.UserAmount,
+		CreatedAt:   unit.CreatedAt,
+	}
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/audit"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"strings"
+	"time"
+)
+
+type PeriodService interface {
+	CreatePeriod(ctx context.Context, period requests.UpsertPeriod) (string, error)
+	GetPeriod(ctx context.Context, uuid string) (responses.Period, error)
+	ListPeriods(ctx context.Context, status string, limit, offset int) ([]responses.Period, int, error)
+	UpdatePeriod(ctx context.Context, period requests.UpsertPeriod) error
+	DeletePeriod(ctx context.Context, uuid string) error
+	PreviewPeriodCloseOut(ctx context.Context, uuid string) (responses.PeriodCloseOut, error)
+	ClosePeriod(ctx context.Context, uuid string) (responses.PeriodCloseOut, error)
+}
+
+type periodStatusState struct {
+	Status string `json:"status"`
+}
+
+type periodService struct {
+	periodRepo     repository.PeriodRepository
+	orgUnitRepo    repository.OrgUnitRepository
+	settingService SettingService
+	scoringService ScoringService
+	rankingRules   models.RankingRules
+}
+
+func NewPeriodService(
+	periodRepo repository.PeriodRepository,
+	orgUnitRepo repository.OrgUnitRepository,
+	settingService SettingService,
+	scoringService ScoringService,
+	rankingRules models.RankingRules,
+) PeriodService {
+	return &periodService{
+		periodRepo:     periodRepo,
+		orgUnitRepo:    orgUnitRepo,
+		settingService: settingService,
+		scoringService: scoringService,
+		rankingRules:   rankingRules,
+	}
+}
+
+func (s *periodService) CreatePeriod(ctx context.Context, req requests.UpsertPeriod) (string, error) {
+	req.UUID = ""
+	if req.Status == "" {
+		req.Status = models.PeriodStatusOpen
+	}
+
+	period, err := parsePeriod(req)
+	if err != nil {
+		return "", err
+	}
+
+	if err = s.checkPeriodOverlap(ctx, period); err != nil {
+		return "", err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionPeriodCreate, audit.EntityPeriod, "", nil, newPeriodResponse(period))
+
+	return s.periodRepo.CreatePeriod(ctx, period, event)
+}
+
+func (s *periodService) GetPeriod(ctx context.Context, uuid string) (responses.Period, error) {
+	period, err := s.periodRepo.GetPeriodByUUID(ctx, uuid)
+	if err != nil {
+		return responses.Period{}, err
+	}
+
+	return newPeriodResponse(period), nil
+}
+
+func (s *periodService) ListPeriods(ctx context.Context, status string, limit, offset int) ([]responses.Period, int, error) {
+	if status != "" && !isPeriodStatus(status) {
+		return nil, 0, errors.Join(validation.WrongInputErr, fmt.Errorf("Неизвестный статус периода %q", status))
+	}
+
+	periods, totalRecords, err := s.periodRepo.ListPeriods(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp := make([]responses.Period, len(periods))
+	for i, period := range periods {
+		resp[i] = newPeriodResponse(period)
+	}
+
+	return resp, totalRecords, nil
+}
+
+func (s *periodService) UpdatePeriod(ctx context.Context, req requests.UpsertPeriod) error {
+	before, err := s.periodRepo.GetPeriodByUUID(ctx, req.UUID)
+	if err != nil {
+		return err
+	}
+
+	if before.Status == models.PeriodStatusArchived {
+		return errors.Join(conflict.InvalidTransitionErr, errors.New("Архивный период нельзя изменить"))
+	}
+
+	if req.Status == "" {
+		req.Status = before.Status
+	}
+
+	period, err := parsePeriod(req)
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkPeriodOverlap(ctx, period); err != nil {
+		return err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionPeriodUpdate, audit.EntityPeriod, period.UUID,
+		newPeriodResponse(before),
+		newPeriodResponse(period),
+	)
+
+	return s.periodRepo.UpdatePeriod(ctx, period, event)
+}
+
+func (s *periodService) DeletePeriod(ctx context.Context, uuid string) error {
+	before, err := s.periodRepo.GetPeriodByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	if before.Status == models.PeriodStatusArchived {
+		return errors.Join(conflict.InvalidTransitionErr, errors.New("Архивный период нельзя удалить"))
+	}
+
+	event := newAuditEvent(ctx, audit.ActionPeriodDelete, audit.EntityPeriod, uuid, newPeriodResponse(before), nil)
+
+	return s.periodRepo.DeletePeriod(ctx, uuid, event)
+}
+
+// PreviewPeriodCloseOut computes the close-out result without storing anything.
+func (s *periodService) PreviewPeriodCloseOut(ctx context.Context, uuid string) (responses.PeriodCloseOut, error) {
+	period, err := s.periodRepo.GetPeriodByUUID(ctx, uuid)
+	if err != nil {
+		return responses.PeriodCloseOut{}, err
+	}
+
+	rules, err := newSelectionRules(ctx, s.orgUnitRepo, s.settingService)
+	if err != nil {
+		return responses.PeriodCloseOut{}, err
+	}
+
+	points, err := winnerPoints(ctx, s.periodRepo, period)
+	if err != nil {
+		return responses.PeriodCloseOut{}, err
+	}
+
+	closeOut, err := s.newPeriodCloseOut(ctx, period, points, rules)
+	if err != nil {
+		return responses.PeriodCloseOut{}, err
+	}
+
+	resp := newPeriodCloseOutResponse(closeOut)
+	resp.DryRun = true
+	return resp, nil
+}
+
+// ClosePeriod finalizes the period: the ranking of the achievements winners are
+// picked on is frozen into a snapshot, the approved achievements of the winners
+// become used so they are not counted again and the period is archived.
+func (s *periodService) ClosePeriod(ctx context.Context, uuid string) (responses.PeriodCloseOut, error) {
+	before, err := s.periodRepo.GetPeriodByUUID(ctx, uuid)
+	if err != nil {
+		return responses.PeriodCloseOut{}, err
+	}
+
+	rules, err := newSelectionRules(ctx, s.orgUnitRepo, s.settingService)
+	if err != nil {
+		return responses.PeriodCloseOut{}, err
+	}
+
+	event := newAuditEvent(ctx, audit.ActionPeriodClose, audit.EntityPeriod, uuid,
+		periodStatusState{Status: before.Status},
+		periodStatusState{Status: models.PeriodStatusArchived},
+	)
+
+	closeOut, err := s.periodRepo.ClosePeriod(
+		ctx,
+		uuid,
+		func(period models.Period, points []models.AchievementPoints) (models.PeriodCloseOut, error) {
+			return s.newPeriodCloseOut(ctx, period, points, rules)
+		},
+		newAchievementReview(ctx, "Учтено при закрытии периода"),
+		event,
+	)
+	if err != nil {
+		return responses.PeriodCloseOut{}, err
+	}
+
+	return newPeriodCloseOutResponse(closeOut), nil
+}
+
+// newPeriodCloseOut selects the winners of the period within its winner count
+// and the quotas of the rules.
+func (s *periodService) newPeriodCloseOut(
+	ctx context.Context,
+	period models.Period,
+	points []models.AchievementPoints,
+	rules models.SelectionRules,
+) (models.PeriodCloseOut, error) {
+	if period.Status == models.PeriodStatusArchived {
+		return models.PeriodCloseOut{}, errors.Join(conflict.InvalidTransitionErr, errors.New("Период уже закрыт"))
+	}
+
+	rules.WinnerCount = period.WinnerCount
+	entries, _ := selectWinners(s.scoringService.ScorePoints(points), rules, s.rankingRules)
+
+	closeOut := models.PeriodCloseOut{
+		Period: period,
+		Snapshot: models.RatingSnapshot{
+			PeriodUUID:  period.UUID,
+			PeriodName:  period.Name,
+			Reason:      models.RatingSnapshotReasonCloseOut,
+			CreatedBy:   audit.MetaFromContext(ctx).ActorUUID,
+			WinnerRules: newWinnerRules(rules, s.rankingRules),
+			Entries:     entries,
+		},
+	}
+	closeOut.Period.Status = models.PeriodStatusArchived
+
+	for _, entry := range closeOut.Snapshot.Entries {
+		if !entry.IsWinner {
+			continue
+		}
+		for _, achievement := range entry.Achievements {
+			// Achievements used by an earlier close-out count, but are
+			// used already.
+			if achievement.ScoreInput.Status == models.AchievementStatusApproved {
+				closeOut.UsedAchievements = append(closeOut.UsedAchievements, achievement.AchievementUUID)
+			}
+		}
+	}
+
+	return closeOut, nil
+}
+
+func newPeriodCloseOutResponse(closeOut models.PeriodCloseOut) responses.PeriodCloseOut {
+	resp := responses.PeriodCloseOut{
+		Period:                newPeriodResponse(closeOut.Period),
+		SnapshotUUID:          closeOut.Snapshot.UUID,
+		Ranking:               make([]responses.RatingEntry, len(closeOut.Snapshot.Entries)),
+		UsedAchievementAmount: len(closeOut.UsedAchievements),
+	}
+	for i, entry := range closeOut.Snapshot.Entries {
+		resp.Ranking[i] = newRatingEntryResponse(entry)
+		if entry.IsWinner {
+			resp.WinnerAmount++
+		}
+	}
+
+	return resp
+}
+
+// checkPeriodOverlap keeps periods disjoint so that every achievement date
+// belongs to at most one period.
+func (s *periodService) checkPeriodOverlap(ctx context.Context, period models.Period) error {
+	overlapping, err := s.periodRepo.GetOverlappingPeriods(ctx, period)
+	if err != nil {
+		return err
+	}
+
+	if len(overlapping) > 0 {
+		return errors.Join(validation.RecordAlreadyExistsErr, fmt.Errorf("Период пересекается с периодом %q", overlapping[0].Name))
+	}
+
+	return nil
+}
+
+func parsePeriod(req requests.UpsertPeriod) (models.Period, error) {
+	period := models.Period{
+		UUID:        req.UUID,
+		Name:        strings.TrimSpace(req.Name),
+		Status:      req.Status,
+		WinnerCount: req.WinnerCount,
+	}
+
+	if period.Name == "" {
+		return models.Period{}, errors.Join(validation.WrongInputErr, errors.New("Не указано название периода"))
+	}
+
+	if !isPeriodStatus(period.Status) {
+		return models.Period{}, errors.Join(validation.WrongInputErr, fmt.Errorf("Неизвестный статус периода %q", period.Status))
+	}
+
+	// Archiving stores the close-out result, only ClosePeriod can do it.
+	if period.Status == models.PeriodStatusArchived {
+		return models.Period{}, errors.Join(conflict.InvalidTransitionErr, errors.New("Период архивируется только при закрытии"))
+	}
+
+	if period.WinnerCount <= 0 {
+		return models.Period{}, errors.Join(validation.WrongInputErr, errors.New("Количество победителей должно быть больше нуля"))
+	}
+
+	var err error
+	if period.StartDate, err = parsePeriodDate(req.StartDate); err != nil {
+		return models.Period{}, err
+	}
+
+	if period.EndDate, err = parsePeriodDate(req.EndDate); err != nil {
+		return models.Period{}, err
+	}
+
+	if period.SubmissionDeadline, err = parsePeriodDate(req.SubmissionDeadline); err != nil {
+		return models.Period{}, err
+	}
+
+	if period.EndDate.Before(period.StartDate) {
+		return models.Period{}, errors.Join(validation.WrongInputErr, errors.New("Период заканчивается раньше, чем начинается"))
+	}
+
+	if period.SubmissionDeadline.Before(period.StartDate) {
+		return models.Period{}, errors.Join(validation.WrongInputErr, errors.New("Срок подачи достижений не может быть раньше начала периода"))
+	}
+
+	return period, nil
+}
+
+func parsePeriodDate(value string) (time.Time, error) {
+	date, ok, err := parseAchievementDate(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ok {
+		return time.Time{}, errors.Join(validation.WrongInputErr, errors.New("Не указаны даты периода"))
+	}
+
+	return date, nil
+}
+
+func isPeriodStatus(status string) bool {
+	switch status {
+	case models.PeriodStatusOpen, models.PeriodStatusClosed, models.PeriodStatusArchived:
+		return true
+	}
+
+	return false
+}
+
+// isPeriodAcceptingAchievements reports whether students may still submit
+// achievements dated inside the period.
+func isPeriodAcceptingAchievements(period models.Period, now time.Time) bool {
+	if period.Status != models.PeriodStatusOpen {
+		return false
+	}
+
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	return !today.After(period.SubmissionDeadline)
+}
+
+func newPeriodResponse(period models.Period) responses.Period {
+	return responses.Period{
+		UUID:               period.UUID,
+		Name:               period.Name,
+		StartDate:          period.StartDate.Format(time.DateOnly),
+		EndDate:            period.EndDate.Format(time.DateOnly),
+		SubmissionDeadline: period.SubmissionDeadline.Format(time.DateOnly),
+		Status:             period.Status,
+		WinnerCount:        period.WinnerCount,
+		CreatedAt:          period.CreatedAt,
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/errors/conflict"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"testing"
+	"time"
+)
+
+// fakePeriodRepo serves one period and records the updates.
+type fakePeriodRepo struct {
+	repository.PeriodRepository
+
+	period  models.Period
+	updated []models.Period
+}
+
+func (r *fakePeriodRepo) GetPeriodByUUID(context.Context, string) (models.Period, error) {
+	return r.period, nil
+}
+
+func (r *fakePeriodRepo) GetOverlappingPeriods(context.Context, models.Period) ([]models.Period, error) {
+	return nil, nil
+}
+
+func (r *fakePeriodRepo) UpdatePeriod(_ context.Context, period models.Period, _ models.AuditEvent) error {
+	r.updated = append(r.updated, period)
+	return nil
+}
+
+func (r *fakePeriodRepo) CreatePeriod(context.Context, models.Period, models.AuditEvent) (string, error) {
+	return "new", nil
+}
+
+// TestUpsertPeriodStatus covers the statuses a period may be given directly:
+// archiving is left to the close-out.
+func TestUpsertPeriodStatus(t *testing.T) {
+	repo := &fakePeriodRepo{period: models.Period{
+		UUID:               "p",
+		Name:               "Весна 2026",
+		StartDate:          time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:            time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+		SubmissionDeadline: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC),
+		Status:             models.PeriodStatusOpen,
+		WinnerCount:        3,
+	}}
+	s := NewPeriodService(repo, nil, nil, nil, models.RankingRules{})
+
+	req := func(status string) requests.UpsertPeriod {
+		return requests.UpsertPeriod{
+			UUID:               "p",
+			Name:               "Весна 2026",
+			StartDate:          "2026-02-01",
+			EndDate:            "2026-06-30",
+			SubmissionDeadline: "2026-06-15",
+			Status:             status,
+			WinnerCount:        3,
+		}
+	}
+
+	if err := s.UpdatePeriod(context.Background(), req(models.PeriodStatusArchived)); !errors.Is(err, conflict.InvalidTransitionErr) {
+		t.Fatalf("archive by update: err = %v, want InvalidTransitionErr", err)
+	}
+	if _, err := s.CreatePeriod(context.Background(), req(models.PeriodStatusArchived)); !errors.Is(err, conflict.InvalidTransitionErr) {
+		t.Fatalf("create archived: err = %v, want InvalidTransitionErr", err)
+	}
+	if len(repo.updated) != 0 {
+		t.Fatalf("period updated to %v", repo.updated)
+	}
+
+	if err := s.UpdatePeriod(context.Background(), req(models.PeriodStatusClosed)); err != nil {
+		t.Fatalf("close by update: err = %v", err)
+	}
+	if len(repo.updated) != 1 || repo.updated[0].Status != models.PeriodStatusClosed {
+		t.Fatalf("updated = %v, want the closed period", repo.updated)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"github.com/igntnk/scholarship_point_system/audit"
 	"github.com/igntnk/scholarship_point_system/controllers/requests"
 	"github.com/igntnk/scholarship_point_system/controllers/responses"
 	"github.com/igntnk/scholarship_point_system/errors/unexpected"
@@ -25,7 +26,9 @@ const (
 type PermissionService interface {
 	CheckUserHasPermission(ctx context.Context, userUUID string, url string) (ok bool, err error)
 	ActualizeResources(ctx context.Context, resources map[string]struct{}) error
-	ActualizeAdminGroupAndRole(ctx context.Context, adminUUID, groupName, roleName string) error
+	ActualizeAdminGroupAndRole(ctx context.Context, groupName, roleName string) error
+	AddUserToRole(ctx context.Context, userUUID, roleName string) error
+	GetRoleMembersByName(ctx context.Context, roleName string) ([]responses.RoleMember, error)
 	GetResourceList(ctx context.Context) ([]responses.Resource, error)
 	CreateRole(ctx context.Context, role requests.Role) (string, error)
 	CreateGroup(ctx context.Context, group requests.Group) (string, error)
@@ -89,7 +92,7 @@ func (s *permissionService) ActualizeResources(ctx context.Context, resources ma
 	return s.permissionRepo.RemoveAndCreateResources(ctx, createResources, removeResources)
 }
 
-func (s *permissionService) ActualizeAdminGroupAndRole(ctx context.Context, adminUUID, groupName, roleName string) error {
+func (s *permissionService) ActualizeAdminGroupAndRole(ctx context.Context, groupName, roleName string) error {
 
 	fileData, err := os.ReadFile(CacheAdminGroupFile)
 	if err != nil {
@@ -107,11 +110,6 @@ func (s *permissionService) ActualizeAdminGroupAndRole(ctx context.Context, admi
 	if oldCfg.RoleName == "" {
 		roleUUID, err = s.permissionRepo.CreateRole(ctx, models.Role{
 			Name: roleName,
-			Members: []models.RoleMember{
-				{
-					UUID: adminUUID,
-				},
-			},
 		})
 		if err != nil {
 			return err
@@ -128,22 +126,11 @@ func (s *permissionService) ActualizeAdminGroupAndRole(ctx context.Context, admi
 
 		roleUUID = role.UUID
 
-		members := make([]requests.RoleMember, 0, len(role.Members)+1)
-		hasAdmin := false
-		for _, member := range role.Members {
-			if member.UUID == adminUUID {
-				hasAdmin = true
-			}
-
-			members = append(members, requests.RoleMember{
+		members := make([]requests.RoleMember, len(role.Members))
+		for i, member := range role.Members {
+			members[i] = requests.RoleMember{
 				UUID: member.UUID,
-			})
-		}
-
-		if !hasAdmin {
-			members = append(members, requests.RoleMember{
-				UUID: adminUUID,
-			})
+			}
 		}
 
 		reqRole := requests.Role{
@@ -157,7 +144,7 @@ func (s *permissionService) ActualizeAdminGroupAndRole(ctx context.Context, admi
 			return err
 		}
 
-		oldCfg.RoleName = roleUUID
+		oldCfg.RoleName = roleName
 	}
 
 	if oldCfg.GroupName == "" {
@@ -226,6 +213,44 @@ func (s *permissionService) ActualizeAdminGroupAndRole(ctx context.Context, admi
 	return nil
 }
 
+func (s *permissionService) AddUserToRole(ctx context.Context, userUUID, roleName string) error {
+	role, err := s.permissionRepo.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range role.Members {
+		if member.UUID == userUUID {
+			return nil
+		}
+	}
+
+	return s.permissionRepo.UpdateRoleWithMembers(ctx, models.UpdateRole{
+		UUID:              role.UUID,
+		Name:              role.Name,
+		CreateMemberUUIDs: []string{userUUID},
+	})
+}
+
+func (s *permissionService) GetRoleMembersByName(ctx context.Context, roleName string) ([]responses.RoleMember, error) {
+	role, err := s.permissionRepo.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]responses.RoleMember, len(role.Members))
+	for i, member := range role.Members {
+		members[i] = responses.RoleMember{
+			UUID:       member.UUID,
+			Name:       member.Name,
+			SecondName: member.SecondName,
+			Patronymic: member.Patronymic,
+		}
+	}
+
+	return members, nil
+}
+
 func (s *permissionService) GetResourceList(ctx context.Context) ([]responses.Resource, error) {
 	modelResources, err := s.permissionRepo.GetResourceList(ctx)
 	if err != nil {
@@ -383,7 +408,9 @@ func (s *permissionService) UpdateGroupWithRolesAndResources(ctx context.Context
 		DeleteResourceUUIDs: removeResourceUUIDs,
 	}
 
-	return s.permissionRepo.UpdateGroupWithRolesAndResources(ctx, updateGroup)
+	event := newAuditEvent(ctx, audit.ActionGroupUpdate, audit.EntityGroup, group.UUID, dbGroup, updateGroup)
+
+	return s.permissionRepo.UpdateGroupWithRolesAndResources(ctx, updateGroup, event)
 }
 
 func (s *permissionService) DeleteRole(ctx context.Context, uuid string) error {
@@ -2,26 +2,86 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/audit"
 	"github.com/igntnk/scholarship_point_system/controllers/requests"
 	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/export"
+	"github.com/igntnk/scholarship_point_system/report"
 	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/selection"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/igntnk/scholarship_point_system/settings"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"slices"
 	"strings"
+	"time"
+)
+
+const (
+	// userStatusApproved is the only user status that may win a scholarship.
+	userStatusApproved = "approved"
 )
 
 type RatingService interface {
 	GetRating(context.Context, requests.GetRating) (users []responses.User, totalRecords int, err error)
+	ExportRating(ctx context.Context, req requests.GetRating, format string, w io.Writer) error
+	WinnersReport(ctx context.Context, req requests.WinnersReport, w io.Writer) error
 	GetShortInfo(ctx context.Context, UserUUID string) (users responses.RatingShortInfo, err error)
+	GetBreakdown(ctx context.Context, userUUID string) (responses.RatingBreakdown, error)
+	CreateSnapshot(ctx context.Context, req requests.CreateRatingSnapshot) (responses.RatingSnapshot, error)
+	ListSnapshots(ctx context.Context, filter models.RatingSnapshotFilter, limit, offset int) ([]responses.RatingSnapshot, int, error)
+	GetSnapshot(ctx context.Context, uuid string) (responses.RatingSnapshot, error)
+	DiffSnapshots(ctx context.Context, fromUUID, toUUID string) (responses.RatingSnapshotDiff, error)
+	SimulateRating(ctx context.Context, req requests.SimulateRating) (responses.RatingSimulation, error)
+	SelectWinners(ctx context.Context, req requests.SelectWinners) (responses.WinnerSelection, error)
 }
 
+const (
+	snapshotChangeAdded   = "added"
+	snapshotChangeRemoved = "removed"
+	snapshotChangeChanged = "changed"
+)
+
 type ratingService struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	periodRepo     repository.PeriodRepository
+	snapshotRepo   repository.RatingSnapshotRepository
+	orgUnitRepo    repository.OrgUnitRepository
+	categoryRepo   repository.CategoryRepository
+	settingService SettingService
+	scoringService ScoringService
+	reportRenderer report.Renderer
+	rankingRules   models.RankingRules
 }
 
 func NewRatingService(
 	userRepo repository.UserRepository,
+	periodRepo repository.PeriodRepository,
+	snapshotRepo repository.RatingSnapshotRepository,
+	orgUnitRepo repository.OrgUnitRepository,
+	categoryRepo repository.CategoryRepository,
+	settingService SettingService,
+	scoringService ScoringService,
+	reportRenderer report.Renderer,
+	rankingRules models.RankingRules,
 ) RatingService {
 	return &ratingService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		periodRepo:     periodRepo,
+		snapshotRepo:   snapshotRepo,
+		orgUnitRepo:    orgUnitRepo,
+		categoryRepo:   categoryRepo,
+		settingService: settingService,
+		scoringService: scoringService,
+		reportRenderer: reportRenderer,
+		rankingRules:   rankingRules,
 	}
 }
 
@@ -33,16 +93,21 @@ func (s *ratingService) GetRating(
 	totalRecords int,
 	err error,
 ) {
-	searchWords := strings.Split(req.SearchString, " ")
+	filter, period, err := s.newRatingFilter(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	modUsers, totalRecords, err := s.userRepo.GetRating(
-		ctx,
-		searchWords,
-		req.Valid,
-		req.Winners,
-		req.Limit,
-		req.Offset,
-	)
+	// The all-time rating is read from the stored student scores, a period
+	// is scored on the fly.
+	if period.UUID != "" {
+		filter.Scores, err = s.scoringService.ScoreRating(ctx, ratingScoringFilter(period))
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	modUsers, totalRecords, err := s.userRepo.GetRating(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -50,6 +115,1040 @@ func (s *ratingService) GetRating(
 	return modUsers, totalRecords, nil
 }
 
+// ExportRating writes the whole rating selected by the search, valid, winners
+// and period filters as a spreadsheet, with a points column per root category.
+// Pagination and sorting of the request are ignored, students are listed by
+// rank.
+func (s *ratingService) ExportRating(ctx context.Context, req requests.GetRating, format string, w io.Writer) error {
+	req.SortBy, req.SortOrder, req.Limit, req.Offset = "", "", 0, 0
+
+	filter, period, err := s.newRatingFilter(ctx, req)
+	if err != nil {
+		return err
+	}
+	// Without a known sort column the rating is ordered by rank.
+	filter.SortBy = ""
+
+	if period.UUID != "" {
+		filter.Scores, err = s.scoringService.ScoreRating(ctx, ratingScoringFilter(period))
+		if err != nil {
+			return err
+		}
+	}
+
+	categoryScores, err := s.scoringService.ScoreCategories(ctx, ratingScoringFilter(period))
+	if err != nil {
+		return err
+	}
+
+	table, err := export.NewTableWriter(format, w, "Рейтинг")
+	if err != nil {
+		return errors.Join(validation.WrongInputErr, err)
+	}
+
+	columns := []string{
+		"Место", "Фамилия", "Имя", "Отчество", "Номер зачётной книжки", "Баллы", "Достижений",
+		"Студент подтверждён", "Все достижения проверены",
+	}
+	for _, category := range categoryScores.Categories {
+		columns = append(columns, category.Name)
+	}
+
+	if err = table.WriteHeader(columns...); err != nil {
+		return errors.Join(err, unexpected.InternalErr)
+	}
+
+	err = s.userRepo.ForEachRatingUser(ctx, filter, func(user responses.User, _ int) error {
+		row := []any{
+			user.Rank, user.SecondName, user.Name, user.Patronymic, user.GradebookNumber, user.PointsAmount,
+			user.AchievementAmount, exportFlag(user.Valid), exportFlag(user.AllAchievementVerified),
+		}
+		for _, category := range categoryScores.Categories {
+			row = append(row, categoryScores.Points[user.UUID][category.UUID])
+		}
+
+		if err := table.WriteRow(row...); err != nil {
+			return errors.Join(err, unexpected.InternalErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = table.Close(); err != nil {
+		return errors.Join(err, unexpected.InternalErr)
+	}
+
+	return nil
+}
+
+// WinnersReport renders the PDF list of winners of a period, or of all time,
+// ranked on approved and used achievements the same way snapshots are. A
+// closed period is rendered from its close-out snapshot.
+func (s *ratingService) WinnersReport(ctx context.Context, req requests.WinnersReport, w io.Writer) error {
+	var period models.Period
+	if req.PeriodUUID != "" {
+		var err error
+		if period, err = s.periodRepo.GetPeriodByUUID(ctx, req.PeriodUUID); err != nil {
+			return err
+		}
+	} else {
+		winnerCount, err := s.settingService.Int(ctx, settings.WinnerCount)
+		if err != nil {
+			return err
+		}
+		period.WinnerCount = winnerCount
+	}
+
+	winners, err := s.reportWinners(ctx, period, req.Appendix)
+	if err != nil {
+		return err
+	}
+
+	list := models.WinnersList{
+		Header:      strings.TrimSpace(req.Header),
+		PeriodName:  period.Name,
+		StartDate:   period.StartDate,
+		EndDate:     period.EndDate,
+		WinnerCount: period.WinnerCount,
+		GeneratedAt: time.Now(),
+		Appendix:    req.Appendix,
+		Winners:     winners,
+	}
+
+	if err = s.reportRenderer.RenderWinners(w, list); err != nil {
+		if errors.Is(err, report.ErrFontNotConfigured) {
+			return errors.Join(unexpected.NotConfiguredErr, errors.New("Не задан шрифт отчётов, укажите report.font_path в конфигурации"))
+		}
+		return errors.Join(err, unexpected.InternalErr)
+	}
+
+	return nil
+}
+
+// reportWinners returns the winners of a closed period as they were frozen on
+// close-out, so the report does not change with later category changes. The
+// snapshot keeps the points but not the dates and values of the achievements,
+// those are read for the appendix from the achievements, which close-out made
+// used. Open periods, all time and periods archived without a close-out are
+// ranked now.
+func (s *ratingService) reportWinners(ctx context.Context, period models.Period, appendix bool) ([]models.RatingSnapshotEntry, error) {
+	winners := make([]models.RatingSnapshotEntry, 0)
+
+	if period.Status == models.PeriodStatusArchived {
+		snapshot, err := s.snapshotRepo.GetCloseOutSnapshot(ctx, period.UUID)
+		switch {
+		case err == nil:
+			for _, entry := range snapshot.Entries {
+				if entry.IsWinner {
+					winners = append(winners, entry)
+				}
+			}
+
+			if appendix {
+				if err = s.fillScoreInputs(ctx, period, winners); err != nil {
+					return nil, err
+				}
+			}
+			return winners, nil
+		case !errors.Is(err, validation.NoDataFoundErr):
+			return nil, err
+		}
+	}
+
+	rules, err := newSelectionRules(ctx, s.orgUnitRepo, s.settingService)
+	if err != nil {
+		return nil, err
+	}
+	rules.WinnerCount = period.WinnerCount
+
+	points, err := winnerPoints(ctx, s.periodRepo, period)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, _ := selectWinners(s.scoringService.ScorePoints(points), rules, s.rankingRules)
+	for _, entry := range entries {
+		if entry.IsWinner {
+			winners = append(winners, entry)
+		}
+	}
+
+	return winners, nil
+}
+
+// fillScoreInputs sets the score inputs of the used achievements of the period
+// on the snapshot entries, the points stay as frozen.
+func (s *ratingService) fillScoreInputs(ctx context.Context, period models.Period, entries []models.RatingSnapshotEntry) error {
+	points, err := s.periodRepo.GetPeriodAchievementPoints(ctx, period, []string{achievementStatusUsed})
+	if err != nil {
+		return err
+	}
+
+	inputs := make(map[string]models.AchievementScoreInput, len(points))
+	for _, point := range points {
+		inputs[point.AchievementUUID] = point.ScoreInput
+	}
+
+	for i := range entries {
+		for j := range entries[i].Achievements {
+			achievement := &entries[i].Achievements[j]
+			achievement.ScoreInput = inputs[achievement.AchievementUUID]
+		}
+	}
+
+	return nil
+}
+
+// newRatingFilter checks the rating request and loads the period it asks for.
+// The period is empty for the all-time rating. A rating within an org unit
+// covers its nested units as well. Its winners are the winners of the whole
+// rating who study in the unit, selected within the quotas of every unit.
+func (s *ratingService) newRatingFilter(ctx context.Context, req requests.GetRating) (models.RatingFilter, models.Period, error) {
+	filter := models.RatingFilter{
+		Ranking:   s.rankingRules,
+		Search:    req.SearchString,
+		Valid:     req.Valid,
+		Winners:   req.Winners,
+		SortBy:    req.SortBy,
+		SortOrder: req.SortOrder,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+	}
+
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = models.RatingSortPoints
+	case models.RatingSortPoints, models.RatingSortName, models.RatingSortAchievements:
+	default:
+		return filter, models.Period{}, errors.Join(validation.WrongInputErr, fmt.Errorf("Неизвестная сортировка %q", filter.SortBy))
+	}
+
+	switch filter.SortOrder {
+	case "":
+		filter.SortOrder = models.SortOrderDesc
+		if filter.SortBy == models.RatingSortName {
+			filter.SortOrder = models.SortOrderAsc
+		}
+	case models.SortOrderAsc, models.SortOrderDesc:
+	default:
+		return filter, models.Period{}, errors.Join(validation.WrongInputErr, fmt.Errorf("Неизвестный порядок сортировки %q", filter.SortOrder))
+	}
+
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return filter, models.Period{}, errors.Join(validation.WrongInputErr, errors.New("Неверные параметры пагинации"))
+	}
+
+	var (
+		period models.Period
+		err    error
+	)
+	if req.PeriodUUID != "" {
+		period, err = s.periodRepo.GetPeriodByUUID(ctx, req.PeriodUUID)
+		if err != nil {
+			return filter, models.Period{}, err
+		}
+	} else if filter.Winners {
+		period.WinnerCount, err = s.settingService.Int(ctx, settings.WinnerCount)
+		if err != nil {
+			return filter, models.Period{}, err
+		}
+	}
+
+	if req.OrgUnitUUID != "" {
+		unit, err := s.orgUnitRepo.GetOrgUnitByUUID(ctx, req.OrgUnitUUID)
+		if err != nil {
+			return filter, models.Period{}, err
+		}
+
+		if filter.OrgUnitUUIDs, err = s.orgUnitRepo.GetOrgUnitSubtree(ctx, unit.UUID); err != nil {
+			return filter, models.Period{}, err
+		}
+	}
+
+	if filter.Winners {
+		if filter.WinnerUUIDs, err = s.winnerUUIDs(ctx, period, period.WinnerCount); err != nil {
+			return filter, models.Period{}, err
+		}
+	}
+
+	return filter, period, nil
+}
+
+// ratingScoringFilter selects the achievements of the live rating of the
+// period, or of all time for an empty period.
+func ratingScoringFilter(period models.Period) models.ScoringFilter {
+	return models.ScoringFilter{
+		Statuses:  models.LiveRatingStatuses,
+		StartDate: period.StartDate,
+		EndDate:   period.EndDate,
+	}
+}
+
+func exportFlag(flag bool) string {
+	if flag {
+		return "да"
+	}
+	return "нет"
+}
+
+// GetShortInfo ranks the user within the default rating, the one GetRating
+// returns for an empty request.
 func (s *ratingService) GetShortInfo(ctx context.Context, UserUUID string) (users responses.RatingShortInfo, err error) {
-	return s.userRepo.GetShortInfoRating(ctx, UserUUID)
+	filter, _, err := s.newRatingFilter(ctx, requests.GetRating{})
+	if err != nil {
+		return responses.RatingShortInfo{}, err
+	}
+
+	return s.userRepo.GetShortInfoRating(ctx, UserUUID, filter)
+}
+
+// GetBreakdown lists every achievement of the student by root category with the
+// points it brings to the live rating, or the reason it brings none.
+func (s *ratingService) GetBreakdown(ctx context.Context, userUUID string) (responses.RatingBreakdown, error) {
+	user, err := s.userRepo.GetSimpleUserByUUID(ctx, userUUID)
+	if err != nil {
+		return responses.RatingBreakdown{}, err
+	}
+
+	breakdown, err := s.scoringService.Breakdown(ctx, user.UUID)
+	if err != nil {
+		return responses.RatingBreakdown{}, err
+	}
+
+	resp := responses.RatingBreakdown{
+		UserUUID:        user.UUID,
+		FullName:        strings.TrimSpace(strings.Join([]string{user.SecondName, user.Name, user.Patronymic}, " ")),
+		GradebookNumber: user.GradeBookNumber,
+		PointAmount:     breakdown.PointAmount,
+		Categories:      make([]responses.RatingBreakdownCategory, len(breakdown.Categories)),
+	}
+	for i, category := range breakdown.Categories {
+		respCategory := responses.RatingBreakdownCategory{
+			CategoryUUID: category.CategoryUUID,
+			CategoryName: category.CategoryName,
+			PointAmount:  category.PointAmount,
+			Achievements: make([]responses.RatingBreakdownAchievement, len(category.Achievements)),
+		}
+		for j, achievement := range category.Achievements {
+			respAchievement := responses.RatingBreakdownAchievement{
+				AchievementUUID: achievement.AchievementUUID,
+				Status:          achievement.Status,
+				AchievementDate: achievement.AchievementDate.Format(time.DateOnly),
+				State:           achievement.State,
+				ExclusionReason: achievement.ExclusionReason,
+				BasePoints:      achievement.BasePoints,
+				PointAmount:     achievement.PointAmount,
+				Values:          make([]responses.RatingBreakdownValue, len(achievement.Values)),
+			}
+			for k, value := range achievement.Values {
+				respAchievement.Values[k] = responses.RatingBreakdownValue{
+					ValueUUID:       value.ValueUUID,
+					ValueName:       value.ValueName,
+					SubcategoryUUID: value.SubcategoryUUID,
+					SubcategoryName: value.SubcategoryName,
+					PointAmount:     value.Points,
+					Counted:         value.Counted,
+				}
+			}
+			respCategory.Achievements[j] = respAchievement
+		}
+		resp.Categories[i] = respCategory
+	}
+
+	return resp, nil
+}
+
+// CreateSnapshot freezes the current ranking of approved and used achievements,
+// either of one period or of all time.
+func (s *ratingService) CreateSnapshot(ctx context.Context, req requests.CreateRatingSnapshot) (responses.RatingSnapshot, error) {
+	var period models.Period
+	if req.PeriodUUID != "" {
+		var err error
+		if period, err = s.periodRepo.GetPeriodByUUID(ctx, req.PeriodUUID); err != nil {
+			return responses.RatingSnapshot{}, err
+		}
+	} else {
+		winnerCount, err := s.settingService.Int(ctx, settings.WinnerCount)
+		if err != nil {
+			return responses.RatingSnapshot{}, err
+		}
+		period.WinnerCount = winnerCount
+	}
+
+	rules, err := newSelectionRules(ctx, s.orgUnitRepo, s.settingService)
+	if err != nil {
+		return responses.RatingSnapshot{}, err
+	}
+	rules.WinnerCount = period.WinnerCount
+
+	event := newAuditEvent(ctx, audit.ActionSnapshotCreate, audit.EntitySnapshot, "", nil, nil)
+
+	snapshot, err := s.snapshotRepo.CreateRatingSnapshot(
+		ctx,
+		period,
+		models.WinnerStatuses,
+		func(points []models.AchievementPoints) models.RatingSnapshot {
+			entries, _ := selectWinners(s.scoringService.ScorePoints(points), rules, s.rankingRules)
+			return models.RatingSnapshot{
+				PeriodUUID:  period.UUID,
+				PeriodName:  period.Name,
+				Reason:      models.RatingSnapshotReasonManual,
+				CreatedBy:   audit.MetaFromContext(ctx).ActorUUID,
+				WinnerRules: newWinnerRules(rules, s.rankingRules),
+				Entries:     entries,
+			}
+		},
+		event,
+	)
+	if err != nil {
+		return responses.RatingSnapshot{}, err
+	}
+
+	return newRatingSnapshotResponse(snapshot), nil
+}
+
+func (s *ratingService) ListSnapshots(ctx context.Context, filter models.RatingSnapshotFilter, limit, offset int) ([]responses.RatingSnapshot, int, error) {
+	snapshots, totalRecords, err := s.snapshotRepo.ListRatingSnapshots(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp := make([]responses.RatingSnapshot, len(snapshots))
+	for i, snapshot := range snapshots {
+		resp[i] = newRatingSnapshotResponse(snapshot)
+	}
+
+	return resp, totalRecords, nil
+}
+
+func (s *ratingService) GetSnapshot(ctx context.Context, uuid string) (responses.RatingSnapshot, error) {
+	snapshot, err := s.snapshotRepo.GetRatingSnapshot(ctx, uuid)
+	if err != nil {
+		return responses.RatingSnapshot{}, err
+	}
+
+	return newRatingSnapshotResponse(snapshot), nil
+}
+
+// DiffSnapshots lists the students whose position, points or winner flag
+// differ between two snapshots.
+func (s *ratingService) DiffSnapshots(ctx context.Context, fromUUID, toUUID string) (responses.RatingSnapshotDiff, error) {
+	from, err := s.snapshotRepo.GetRatingSnapshot(ctx, fromUUID)
+	if err != nil {
+		return responses.RatingSnapshotDiff{}, err
+	}
+
+	to, err := s.snapshotRepo.GetRatingSnapshot(ctx, toUUID)
+	if err != nil {
+		return responses.RatingSnapshotDiff{}, err
+	}
+
+	diff := responses.RatingSnapshotDiff{
+		RuleVersionChanged: from.RuleVersion != to.RuleVersion,
+		Changes:            diffRatingEntries(from.Entries, to.Entries),
+	}
+
+	from.Entries, to.Entries = nil, nil
+	diff.From = newRatingSnapshotResponse(from)
+	diff.To = newRatingSnapshotResponse(to)
+
+	return diff, nil
+}
+
+// SimulateRating ranks the reviewed achievements of a period, or of all time,
+// as if the proposed category changes were saved. Nothing is persisted.
+func (s *ratingService) SimulateRating(ctx context.Context, req requests.SimulateRating) (responses.RatingSimulation, error) {
+	var period models.Period
+	if req.PeriodUUID != "" {
+		var err error
+		if period, err = s.periodRepo.GetPeriodByUUID(ctx, req.PeriodUUID); err != nil {
+			return responses.RatingSimulation{}, err
+		}
+	} else {
+		winnerCount, err := s.settingService.Int(ctx, settings.WinnerCount)
+		if err != nil {
+			return responses.RatingSimulation{}, err
+		}
+		period.WinnerCount = winnerCount
+	}
+
+	points, err := winnerPoints(ctx, s.periodRepo, period)
+	if err != nil {
+		return responses.RatingSimulation{}, err
+	}
+
+	if err = s.checkSimulatedCategories(ctx, req.Changes); err != nil {
+		return responses.RatingSimulation{}, err
+	}
+
+	simulatedPoints, err := simulateCategoryChanges(points, req.Changes)
+	if err != nil {
+		return responses.RatingSimulation{}, err
+	}
+
+	rules, err := newSelectionRules(ctx, s.orgUnitRepo, s.settingService)
+	if err != nil {
+		return responses.RatingSimulation{}, err
+	}
+	rules.WinnerCount = period.WinnerCount
+
+	current, _ := selectWinners(s.scoringService.ScorePoints(points), rules, s.rankingRules)
+	simulated, _ := selectWinners(s.scoringService.ScorePoints(simulatedPoints), rules, s.rankingRules)
+
+	resp := responses.RatingSimulation{
+		PeriodUUID:  period.UUID,
+		WinnerCount: period.WinnerCount,
+		Ranking:     make([]responses.RatingEntry, len(simulated)),
+		Changes:     diffRatingEntries(current, simulated),
+		Entered:     make([]responses.RatingSnapshotDiffEntry, 0),
+		DroppedOut:  make([]responses.RatingSnapshotDiffEntry, 0),
+	}
+	for i, entry := range simulated {
+		resp.Ranking[i] = newRatingEntryResponse(entry)
+	}
+	for _, change := range resp.Changes {
+		switch {
+		case change.ToWinner && !change.FromWinner:
+			resp.Entered = append(resp.Entered, change)
+		case change.FromWinner && !change.ToWinner:
+			resp.DroppedOut = append(resp.DroppedOut, change)
+		}
+	}
+
+	return resp, nil
+}
+
+// SelectWinners applies the stored selection rules, changed by the request, to
+// the reviewed achievements of the period, or of all time. Nothing is stored, the result
+// only shows who would win and why the others would not.
+func (s *ratingService) SelectWinners(ctx context.Context, req requests.SelectWinners) (responses.WinnerSelection, error) {
+	rules, err := newSelectionRules(ctx, s.orgUnitRepo, s.settingService)
+	if err != nil {
+		return responses.WinnerSelection{}, err
+	}
+
+	if req.MinPoints != nil {
+		if *req.MinPoints < 0 {
+			return responses.WinnerSelection{}, errors.Join(validation.WrongInputErr, errors.New("Минимум баллов не может быть отрицательным"))
+		}
+		rules.MinPoints = *req.MinPoints
+	}
+	if req.RequireApproved != nil {
+		rules.RequireApproved = *req.RequireApproved
+	}
+	if req.RequireVerified != nil {
+		rules.RequireVerified = *req.RequireVerified
+	}
+
+	var period models.Period
+	if req.PeriodUUID != "" {
+		var err error
+		if period, err = s.periodRepo.GetPeriodByUUID(ctx, req.PeriodUUID); err != nil {
+			return responses.WinnerSelection{}, err
+		}
+	} else {
+		winnerCount, err := s.settingService.Int(ctx, settings.WinnerCount)
+		if err != nil {
+			return responses.WinnerSelection{}, err
+		}
+		period.WinnerCount = winnerCount
+	}
+	rules.WinnerCount = period.WinnerCount
+
+	for unitUUID, quota := range req.UnitQuotas {
+		if _, ok := rules.UnitParents[unitUUID]; !ok {
+			return responses.WinnerSelection{}, errors.Join(validation.NoDataFoundErr, fmt.Errorf("Подразделение %s не найдено", unitUUID))
+		}
+		if quota <= 0 {
+			return responses.WinnerSelection{}, errors.Join(validation.WrongInputErr, fmt.Errorf("Квота подразделения %s должна быть больше нуля", unitUUID))
+		}
+		rules.UnitQuotas[unitUUID] = quota
+	}
+
+	for categoryUUID, quota := range req.CategoryQuotas {
+		if quota <= 0 {
+			return responses.WinnerSelection{}, errors.Join(validation.WrongInputErr, fmt.Errorf("Квота категории %s должна быть больше нуля", categoryUUID))
+		}
+		rules.CategoryQuotas[categoryUUID] = quota
+	}
+
+	points, err := winnerPoints(ctx, s.periodRepo, period)
+	if err != nil {
+		return responses.WinnerSelection{}, err
+	}
+
+	_, results := selectWinners(s.scoringService.ScorePoints(points), rules, s.rankingRules)
+
+	resp := responses.WinnerSelection{
+		PeriodUUID:  period.UUID,
+		WinnerCount: period.WinnerCount,
+		Students:    make([]responses.WinnerSelectionEntry, len(results)),
+	}
+	for i, result := range results {
+		student := responses.WinnerSelectionEntry{
+			Position:            result.Candidate.Entry.Position,
+			UserUUID:            result.Candidate.Entry.UserUUID,
+			FullName:            result.Candidate.Entry.FullName,
+			GradebookNumber:     result.Candidate.Entry.GradebookNumber,
+			OrgUnitUUID:         result.Candidate.OrgUnitUUID,
+			LeadingCategoryUUID: result.LeadingCategoryUUID,
+			PointAmount:         result.Candidate.Entry.PointAmount,
+			Selected:            result.Selected,
+			Reasons:             make([]responses.WinnerSelectionReason, len(result.Reasons)),
+		}
+
+		for j, reason := range result.Reasons {
+			student.Reasons[j] = responses.WinnerSelectionReason{
+				Code:   reason.Code,
+				UUID:   reason.UUID,
+				Detail: reason.Detail,
+			}
+		}
+		student.Explanation = selection.Explain(result)
+
+		if result.Selected {
+			resp.SelectedCount++
+		}
+
+		resp.Students[i] = student
+	}
+
+	return resp, nil
+}
+
+// newSelectionRules returns the rules every ranking that names winners is
+// selected with: the stored conditions and category quotas and the winner
+// quotas of the org units. The caller sets the winner count.
+func newSelectionRules(
+	ctx context.Context,
+	orgUnitRepo repository.OrgUnitRepository,
+	settingService SettingService,
+) (models.SelectionRules, error) {
+	var stored settings.SelectionRules
+	if err := settingService.JSON(ctx, settings.WinnerSelection, &stored); err != nil {
+		return models.SelectionRules{}, err
+	}
+
+	rules := models.SelectionRules{
+		MinPoints:       stored.MinPoints,
+		RequireApproved: stored.RequireApproved,
+		RequireVerified: stored.RequireVerified,
+		UnitQuotas:      map[string]int{},
+		UnitParents:     map[string]string{},
+		CategoryQuotas:  map[string]int{},
+	}
+	for categoryUUID, quota := range stored.CategoryQuotas {
+		rules.CategoryQuotas[categoryUUID] = quota
+	}
+
+	units, err := orgUnitRepo.ListOrgUnits(ctx)
+	if err != nil {
+		return models.SelectionRules{}, err
+	}
+	for _, unit := range units {
+		rules.UnitParents[unit.UUID] = unit.ParentUUID
+		if unit.WinnerQuota > 0 {
+			rules.UnitQuotas[unit.UUID] = unit.WinnerQuota
+		}
+	}
+
+	return rules, nil
+}
+
+// winnerPoints reads the achievement points of the period, or of all time,
+// that winners are picked on. The close-out reads the same statuses inside its
+// transaction.
+func winnerPoints(ctx context.Context, periodRepo repository.PeriodRepository, period models.Period) ([]models.AchievementPoints, error) {
+	return periodRepo.GetPeriodAchievementPoints(ctx, period, models.WinnerStatuses)
+}
+
+// newWinnerRules describes the ranking and the selection rules of a snapshot:
+// the rank mode, the tie-breakers, the winner count, the org unit quotas and
+// the stored winner selection. Maps are encoded with sorted keys, so equal
+// rules always give the same text.
+func newWinnerRules(rules models.SelectionRules, ranking models.RankingRules) string {
+	encoded, _ := json.Marshal(struct {
+		Ranking   models.RankingRules
+		Selection models.SelectionRules
+	}{ranking, rules})
+
+	return string(encoded)
+}
+
+// selectWinners ranks the students and marks the ones the selection rules
+// pick as winners. The results explain the decision on every entry.
+func selectWinners(points []models.AchievementPoints, rules models.SelectionRules, ranking models.RankingRules) ([]models.RatingSnapshotEntry, []models.SelectionResult) {
+	entries := rankAchievementPoints(points, rules.WinnerCount, ranking)
+
+	candidates := make([]models.SelectionCandidate, len(entries))
+	for i, entry := range entries {
+		candidates[i] = models.SelectionCandidate{Entry: entry, Verified: true}
+		for _, achievement := range entry.Achievements {
+			candidates[i].OrgUnitUUID = achievement.UserOrgUnitUUID
+			candidates[i].Approved = achievement.UserStatus == userStatusApproved
+			candidates[i].Verified = candidates[i].Verified && achievement.ScoreInput.Status != models.AchievementStatusUnapproved
+		}
+	}
+
+	results := selection.Select(candidates, rules)
+	for i := range entries {
+		entries[i].IsWinner = results[i].Selected
+		results[i].Candidate.Entry.IsWinner = results[i].Selected
+	}
+
+	return entries, results
+}
+
+// winnerUUIDs returns the students selected as winners of the period, or of
+// all time, the same way close-out selects them.
+func (s *ratingService) winnerUUIDs(ctx context.Context, period models.Period, winnerCount int) ([]string, error) {
+	rules, err := newSelectionRules(ctx, s.orgUnitRepo, s.settingService)
+	if err != nil {
+		return nil, err
+	}
+	rules.WinnerCount = winnerCount
+
+	points, err := winnerPoints(ctx, s.periodRepo, period)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, _ := selectWinners(s.scoringService.ScorePoints(points), rules, s.rankingRules)
+
+	winners := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsWinner {
+			winners = append(winners, entry.UserUUID)
+		}
+	}
+
+	return winners, nil
+}
+
+// simulateCategoryChanges returns a copy of the achievement points with the
+// category changes applied to their score components, the way saving the
+// changes would. A root category change replaces its points and rules. A
+// subcategory change sets the points of the listed values and stops counting
+// the values it does not list, as the update deactivates them.
+func simulateCategoryChanges(points []models.AchievementPoints, changes []requests.UpdateCategory) ([]models.AchievementPoints, error) {
+	type rootChange struct {
+		points float64
+		rules  models.CategoryScoringRules
+	}
+
+	roots := map[string]rootChange{}
+	subcategories := map[string]map[string]float64{}
+	for _, change := range changes {
+		if change.UUID == "" {
+			return nil, errors.Join(validation.WrongInputErr, errors.New("Не указана изменяемая категория"))
+		}
+
+		_, isRoot := roots[change.UUID]
+		_, isSubcategory := subcategories[change.UUID]
+		if isRoot || isSubcategory {
+			return nil, errors.Join(validation.WrongInputErr, fmt.Errorf("Категория %s изменена несколько раз", change.UUID))
+		}
+
+		rules, err := parseCategoryScoringRules(change.Rules, len(change.Values) != 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(change.Values) == 0 {
+			roots[change.UUID] = rootChange{points: float64(change.Points), rules: rules}
+			continue
+		}
+
+		values := make(map[string]float64, len(change.Values))
+		for _, value := range change.Values {
+			values[value.Name] = float64(value.Points)
+		}
+		subcategories[change.UUID] = values
+	}
+
+	simulated := make([]models.AchievementPoints, len(points))
+	for i, point := range points {
+		input := point.ScoreInput
+		if root, ok := roots[input.CategoryUUID]; ok {
+			input.CategoryPoints = root.points
+			input.CategoryRules = root.rules
+		}
+
+		input.Values = slices.Clone(input.Values)
+		for j := range input.Values {
+			value := &input.Values[j]
+			values, ok := subcategories[value.SubcategoryUUID]
+			if !ok {
+				continue
+			}
+
+			valuePoints, ok := values[value.ValueName]
+			if !ok {
+				value.Counted = false
+				continue
+			}
+			value.Points = valuePoints
+		}
+
+		point.ScoreInput = input
+		simulated[i] = point
+	}
+
+	return simulated, nil
+}
+
+// checkSimulatedCategories makes sure every changed category exists and is
+// changed the way its kind allows: points and rules for a root category,
+// values for a subcategory.
+func (s *ratingService) checkSimulatedCategories(ctx context.Context, changes []requests.UpdateCategory) error {
+	for _, change := range changes {
+		if change.UUID == "" {
+			continue
+		}
+
+		pgUUID := pgtype.UUID{}
+		if err := pgUUID.Scan(change.UUID); err != nil {
+			return errors.Join(validation.NoDataFoundErr, fmt.Errorf("Категория %s не найдена", change.UUID))
+		}
+
+		category, err := s.categoryRepo.GetCategoryByUUID(ctx, pgUUID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.Join(validation.NoDataFoundErr, fmt.Errorf("Категория %s не найдена", change.UUID))
+			}
+			return errors.Join(err, unexpected.RequestErr)
+		}
+
+		isSubcategory := category.ParentCategory.Valid
+		if isSubcategory && len(change.Values) == 0 {
+			return errors.Join(validation.WrongInputErr, fmt.Errorf("Для подкатегории %s нужно указать значения", category.Name))
+		}
+		if !isSubcategory && len(change.Values) != 0 {
+			return errors.Join(validation.WrongInputErr, fmt.Errorf("У корневой категории %s нет значений", category.Name))
+		}
+	}
+
+	return nil
+}
+
+// diffRatingEntries lists the students whose position, points or winner flag
+// differ between two rankings.
+func diffRatingEntries(from, to []models.RatingSnapshotEntry) []responses.RatingSnapshotDiffEntry {
+	changes := make([]responses.RatingSnapshotDiffEntry, 0)
+
+	fromEntries := map[string]models.RatingSnapshotEntry{}
+	for _, entry := range from {
+		fromEntries[entry.UserUUID] = entry
+	}
+
+	for _, toEntry := range to {
+		change := responses.RatingSnapshotDiffEntry{
+			UserUUID:        toEntry.UserUUID,
+			FullName:        toEntry.FullName,
+			GradebookNumber: toEntry.GradebookNumber,
+			Change:          snapshotChangeAdded,
+			ToPosition:      toEntry.Position,
+			ToPoints:        toEntry.PointAmount,
+			PointDelta:      toEntry.PointAmount,
+			ToWinner:        toEntry.IsWinner,
+		}
+
+		fromEntry, ok := fromEntries[toEntry.UserUUID]
+		if ok {
+			delete(fromEntries, toEntry.UserUUID)
+			if fromEntry.Position == toEntry.Position && fromEntry.PointAmount == toEntry.PointAmount &&
+				fromEntry.IsWinner == toEntry.IsWinner {
+				continue
+			}
+
+			change.Change = snapshotChangeChanged
+			change.FromPosition = fromEntry.Position
+			change.PositionDelta = fromEntry.Position - toEntry.Position
+			change.FromPoints = fromEntry.PointAmount
+			change.PointDelta = toEntry.PointAmount - fromEntry.PointAmount
+			change.FromWinner = fromEntry.IsWinner
+		}
+
+		changes = append(changes, change)
+	}
+
+	for _, fromEntry := range from {
+		if _, ok := fromEntries[fromEntry.UserUUID]; !ok {
+			continue
+		}
+
+		changes = append(changes, responses.RatingSnapshotDiffEntry{
+			UserUUID:        fromEntry.UserUUID,
+			FullName:        fromEntry.FullName,
+			GradebookNumber: fromEntry.GradebookNumber,
+			Change:          snapshotChangeRemoved,
+			FromPosition:    fromEntry.Position,
+			FromPoints:      fromEntry.PointAmount,
+			PointDelta:      -fromEntry.PointAmount,
+			FromWinner:      fromEntry.IsWinner,
+		})
+	}
+
+	return changes
+}
+
+// rankAchievementPoints sums the achievement points per student and ranks the
+// students the same way the live rating does. Students equal on points and on
+// every tie-breaker share a rank and are listed by name. The confirmed students
+// ranked within winnerCount among confirmed students are marked as winners.
+func rankAchievementPoints(points []models.AchievementPoints, winnerCount int, rules models.RankingRules) []models.RatingSnapshotEntry {
+	entries := make([]models.RatingSnapshotEntry, 0)
+	byUser := map[string]int{}
+	eligible := map[string]bool{}
+	lastSubmitted := map[string]time.Time{}
+	for _, point := range points {
+		i, ok := byUser[point.UserUUID]
+		if !ok {
+			i = len(entries)
+			byUser[point.UserUUID] = i
+			eligible[point.UserUUID] = point.UserStatus == userStatusApproved
+			entries = append(entries, models.RatingSnapshotEntry{
+				UserUUID: point.UserUUID,
+				FullName: strings.TrimSpace(strings.Join([]string{
+					point.UserSecondName, point.UserName, point.UserPatronymic,
+				}, " ")),
+				GradebookNumber: point.GradebookNumber,
+			})
+		}
+
+		entries[i].PointAmount += point.PointAmount
+		entries[i].AchievementAmount++
+		entries[i].Achievements = append(entries[i].Achievements, point)
+		if point.SubmittedAt.After(lastSubmitted[point.UserUUID]) {
+			lastSubmitted[point.UserUUID] = point.SubmittedAt
+		}
+	}
+
+	compareRank := func(a, b models.RatingSnapshotEntry) int {
+		if a.PointAmount != b.PointAmount {
+			if a.PointAmount > b.PointAmount {
+				return -1
+			}
+			return 1
+		}
+
+		for _, tieBreaker := range rules.TieBreakers {
+			switch tieBreaker {
+			case models.TieBreakerAchievements:
+				if a.AchievementAmount != b.AchievementAmount {
+					return b.AchievementAmount - a.AchievementAmount
+				}
+			case models.TieBreakerEarliestSubmission:
+				if c := lastSubmitted[a.UserUUID].Compare(lastSubmitted[b.UserUUID]); c != 0 {
+					return c
+				}
+			}
+		}
+
+		return 0
+	}
+
+	slices.SortStableFunc(entries, func(a, b models.RatingSnapshotEntry) int {
+		if c := compareRank(a, b); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.FullName, b.FullName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GradebookNumber, b.GradebookNumber)
+	})
+
+	var (
+		eligibleRank  int
+		eligibleCount int
+		lastEligible  *models.RatingSnapshotEntry
+	)
+	for i := range entries {
+		entries[i].Position = nextRank(rules.Mode, i, entries[max(i-1, 0)].Position, i > 0 && compareRank(entries[i-1], entries[i]) == 0)
+
+		if !eligible[entries[i].UserUUID] {
+			continue
+		}
+
+		eligibleRank = nextRank(rules.Mode, eligibleCount, eligibleRank, lastEligible != nil && compareRank(*lastEligible, entries[i]) == 0)
+		eligibleCount++
+		lastEligible = &entries[i]
+		entries[i].IsWinner = eligibleRank <= winnerCount
+	}
+
+	return entries
+}
+
+// nextRank returns the rank of the entry at index i (zero based) given the rank
+// of the previous entry and whether both are tied.
+func nextRank(mode string, i, previousRank int, tied bool) int {
+	switch {
+	case tied:
+		return previousRank
+	case mode == models.RankModeDense:
+		return previousRank + 1
+	default:
+		return i + 1
+	}
+}
+
+// ValidateRankingRules reports unknown rank modes and tie-breakers.
+func ValidateRankingRules(rules models.RankingRules) error {
+	if rules.Mode != models.RankModeCompetition && rules.Mode != models.RankModeDense {
+		return fmt.Errorf("unknown rank mode %q", rules.Mode)
+	}
+
+	for _, tieBreaker := range rules.TieBreakers {
+		if tieBreaker != models.TieBreakerAchievements && tieBreaker != models.TieBreakerEarliestSubmission {
+			return fmt.Errorf("unknown tie-breaker %q", tieBreaker)
+		}
+	}
+
+	return nil
+}
+
+func newRatingEntryResponse(entry models.RatingSnapshotEntry) responses.RatingEntry {
+	resp := responses.RatingEntry{
+		Position:          entry.Position,
+		UserUUID:          entry.UserUUID,
+		FullName:          entry.FullName,
+		GradebookNumber:   entry.GradebookNumber,
+		PointAmount:       entry.PointAmount,
+		AchievementAmount: entry.AchievementAmount,
+		IsWinner:          entry.IsWinner,
+		Achievements:      make([]responses.RatingEntryAchievement, len(entry.Achievements)),
+	}
+	for i, achievement := range entry.Achievements {
+		resp.Achievements[i] = responses.RatingEntryAchievement{
+			AchievementUUID: achievement.AchievementUUID,
+			CategoryUUID:    achievement.CategoryUUID,
+			CategoryName:    achievement.CategoryName,
+			PointAmount:     achievement.PointAmount,
+		}
+	}
+
+	return resp
+}
+
+func newRatingSnapshotResponse(snapshot models.RatingSnapshot) responses.RatingSnapshot {
+	resp := responses.RatingSnapshot{
+		UUID:        snapshot.UUID,
+		PeriodUUID:  snapshot.PeriodUUID,
+		PeriodName:  snapshot.PeriodName,
+		Reason:      snapshot.Reason,
+		CreatedBy:   snapshot.CreatedBy,
+		RuleVersion: snapshot.RuleVersion,
+		CreatedAt:   snapshot.CreatedAt,
+		EntryAmount: snapshot.EntryAmount,
+	}
+	if snapshot.Entries != nil {
+		resp.Entries = make([]responses.RatingEntry, len(snapshot.Entries))
+		for i, entry := range snapshot.Entries {
+			resp.Entries[i] = newRatingEntryResponse(entry)
+		}
+	}
+
+	return resp
 }
@@ -0,0 +1,774 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/db"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/report"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/igntnk/scholarship_point_system/settings"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testStudent describes the counted achievements of one student: the points
+// of each, the day of the month it was submitted on and its status, approved
+// unless given.
+type testStudent struct {
+	uuid       string
+	unapproved bool
+	points     []float64
+	days       []int
+	statuses   []string
+}
+
+func testAchievementPoints(students ...testStudent) []models.AchievementPoints {
+	var points []models.AchievementPoints
+	for _, student := range students {
+		status := userStatusApproved
+		if student.unapproved {
+			status = "unapproved"
+		}
+
+		for i, point := range student.points {
+			day := 1
+			if i < len(student.days) {
+				day = student.days[i]
+			}
+			achievementStatus := achievementStatusApproved
+			if i < len(student.statuses) {
+				achievementStatus = student.statuses[i]
+			}
+
+			achievementUUID := student.uuid + "-" + strconv.Itoa(i)
+			points = append(points, models.AchievementPoints{
+				AchievementUUID: achievementUUID,
+				UserUUID:        student.uuid,
+				UserSecondName:  student.uuid,
+				UserStatus:      status,
+				SubmittedAt:     time.Date(2026, time.March, day, 0, 0, 0, 0, time.UTC),
+				PointAmount:     point,
+				ScoreInput: models.AchievementScoreInput{
+					AchievementUUID: achievementUUID,
+					UserUUID:        student.uuid,
+					Status:          achievementStatus,
+				},
+			})
+		}
+	}
+
+	return points
+}
+
+type testRank struct {
+	uuid     string
+	position int
+	winner   bool
+}
+
+func TestRankAchievementPoints(t *testing.T) {
+	competition := models.RankingRules{Mode: models.RankModeCompetition}
+	dense := models.RankingRules{Mode: models.RankModeDense}
+
+	tests := []struct {
+		name        string
+		students    []testStudent
+		winnerCount int
+		rules       models.RankingRules
+		want        []testRank
+	}{
+		{
+			name: "competition skips ranks after a tie",
+			students: []testStudent{
+				{uuid: "c", points: []float64{5}},
+				{uuid: "a", points: []float64{10}},
+				{uuid: "b", points: []float64{4, 6}},
+				{uuid: "d", points: []float64{1}},
+			},
+			winnerCount: 10,
+			rules:       competition,
+			want:        []testRank{{"a", 1, true}, {"b", 1, true}, {"c", 3, true}, {"d", 4, true}},
+		},
+		{
+			name: "dense does not skip ranks after a tie",
+			students: []testStudent{
+				{uuid: "c", points: []float64{5}},
+				{uuid: "a", points: []float64{10}},
+				{uuid: "b", points: []float64{4, 6}},
+				{uuid: "d", points: []float64{1}},
+			},
+			winnerCount: 10,
+			rules:       dense,
+			want:        []testRank{{"a", 1, true}, {"b", 1, true}, {"c", 2, true}, {"d", 3, true}},
+		},
+		{
+			name: "more achievements break the tie",
+			students: []testStudent{
+				{uuid: "a", points: []float64{10}},
+				{uuid: "b", points: []float64{4, 6}},
+			},
+			winnerCount: 1,
+			rules:       models.RankingRules{Mode: models.RankModeCompetition, TieBreakers: []string{models.TieBreakerAchievements}},
+			want:        []testRank{{"b", 1, true}, {"a", 2, false}},
+		},
+		{
+			name: "earlier last submission breaks the tie",
+			students: []testStudent{
+				{uuid: "a", points: []float64{10}, days: []int{20}},
+				{uuid: "b", points: []float64{4, 6}, days: []int{2, 10}},
+			},
+			winnerCount: 1,
+			rules:       models.RankingRules{Mode: models.RankModeCompetition, TieBreakers: []string{models.TieBreakerEarliestSubmission}},
+			want:        []testRank{{"b", 1, true}, {"a", 2, false}},
+		},
+		{
+			name: "tie-breakers apply in order",
+			students: []testStudent{
+				{uuid: "a", points: []float64{10}, days: []int{5}},
+				{uuid: "b", points: []float64{4, 6}, days: []int{2, 10}},
+			},
+			winnerCount: 1,
+			rules: models.RankingRules{Mode: models.RankModeCompetition, TieBreakers: []string{
+				models.TieBreakerEarliestSubmission, models.TieBreakerAchievements,
+			}},
+			want: []testRank{{"a", 1, true}, {"b", 2, false}},
+		},
+		{
+			name: "students equal on every tie-breaker share the rank",
+			students: []testStudent{
+				{uuid: "b", points: []float64{10}, days: []int{5}},
+				{uuid: "a", points: []float64{10}, days: []int{5}},
+				{uuid: "c", points: []float64{9}},
+			},
+			winnerCount: 1,
+			rules: models.RankingRules{Mode: models.RankModeCompetition, TieBreakers: []string{
+				models.TieBreakerAchievements, models.TieBreakerEarliestSubmission,
+			}},
+			want: []testRank{{"a", 1, true}, {"b", 1, true}, {"c", 3, false}},
+		},
+		{
+			name: "competition ties at the cutoff are all winners",
+			students: []testStudent{
+				{uuid: "a", points: []float64{10}},
+				{uuid: "b", points: []float64{5}},
+				{uuid: "c", points: []float64{5}},
+				{uuid: "d", points: []float64{1}},
+			},
+			winnerCount: 2,
+			rules:       competition,
+			want:        []testRank{{"a", 1, true}, {"b", 2, true}, {"c", 2, true}, {"d", 4, false}},
+		},
+		{
+			name: "dense ties at the cutoff are all winners",
+			students: []testStudent{
+				{uuid: "a", points: []float64{10}},
+				{uuid: "b", points: []float64{5}},
+				{uuid: "c", points: []float64{5}},
+				{uuid: "d", points: []float64{1}},
+			},
+			winnerCount: 2,
+			rules:       dense,
+			want:        []testRank{{"a", 1, true}, {"b", 2, true}, {"c", 2, true}, {"d", 3, false}},
+		},
+		{
+			name: "a tie above the cutoff pushes the next student out in competition mode",
+			students: []testStudent{
+				{uuid: "a", points: []float64{10}},
+				{uuid: "b", points: []float64{10}},
+				{uuid: "c", points: []float64{5}},
+			},
+			winnerCount: 2,
+			rules:       competition,
+			want:        []testRank{{"a", 1, true}, {"b", 1, true}, {"c", 3, false}},
+		},
+		{
+			name: "a tie above the cutoff keeps the next student in dense mode",
+			students: []testStudent{
+				{uuid: "a", points: []float64{10}},
+				{uuid: "b", points: []float64{10}},
+				{uuid: "c", points: []float64{5}},
+			},
+			winnerCount: 2,
+			rules:       dense,
+			want:        []testRank{{"a", 1, true}, {"b", 1, true}, {"c", 2, true}},
+		},
+		{
+			name: "unconfirmed students keep their position but take no winner place",
+			students: []testStudent{
+				{uuid: "a", points: []float64{20}, unapproved: true},
+				{uuid: "b", points: []float64{10}},
+				{uuid: "c", points: []float64{10}},
+				{uuid: "d", points: []float64{5}},
+			},
+			winnerCount: 1,
+			rules:       competition,
+			want:        []testRank{{"a", 1, false}, {"b", 2, true}, {"c", 2, true}, {"d", 4, false}},
+		},
+		{
+			name:        "no points",
+			winnerCount: 3,
+			rules:       competition,
+			want:        []testRank{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := rankAchievementPoints(testAchievementPoints(tt.students...), tt.winnerCount, tt.rules)
+
+			if len(entries) != len(tt.want) {
+				t.Fatalf("%d entries, want %d", len(entries), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := testRank{uuid: entries[i].UserUUID, position: entries[i].Position, winner: entries[i].IsWinner}
+				if got != want {
+					t.Errorf("entry %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRankAchievementPointsSums(t *testing.T) {
+	entries := rankAchievementPoints(testAchievementPoints(
+		testStudent{uuid: "a", points: []float64{1.5, 2.25, 3}},
+	), 1, models.RankingRules{Mode: models.RankModeCompetition})
+
+	if len(entries) != 1 {
+		t.Fatalf("%d entries, want 1", len(entries))
+	}
+	if entries[0].PointAmount != 6.75 || entries[0].AchievementAmount != 3 || len(entries[0].Achievements) != 3 {
+		t.Errorf("entry = %+v, want 6.75 points of 3 achievements", entries[0])
+	}
+}
+
+func TestNextRank(t *testing.T) {
+	tests := []struct {
+		mode         string
+		i            int
+		previousRank int
+		tied         bool
+		want         int
+	}{
+		{models.RankModeCompetition, 0, 0, false, 1},
+		{models.RankModeCompetition, 1, 1, true, 1},
+		{models.RankModeCompetition, 2, 1, false, 3},
+		{models.RankModeDense, 0, 0, false, 1},
+		{models.RankModeDense, 1, 1, true, 1},
+		{models.RankModeDense, 2, 1, false, 2},
+		{"", 2, 1, false, 3},
+	}
+
+	for _, tt := range tests {
+		if got := nextRank(tt.mode, tt.i, tt.previousRank, tt.tied); got != tt.want {
+			t.Errorf("nextRank(%q, %d, %d, %v) = %d, want %d", tt.mode, tt.i, tt.previousRank, tt.tied, got, tt.want)
+		}
+	}
+}
+
+// fakeCategoryRepo knows one root category and one subcategory.
+type fakeCategoryRepo struct {
+	repository.CategoryRepository
+}
+
+const (
+	testRootCategoryUUID = "00000000-0000-0000-0000-0000000000a1"
+	testSubcategoryUUID  = "00000000-0000-0000-0000-0000000000a2"
+)
+
+func (fakeCategoryRepo) GetCategoryByUUID(_ context.Context, uuid pgtype.UUID) (db.GetCategoryByUUIDRow, error) {
+	root := pgtype.UUID{}
+	_ = root.Scan(testRootCategoryUUID)
+
+	switch uuid.String() {
+	case testRootCategoryUUID:
+		return db.GetCategoryByUUIDRow{Uuid: uuid, Name: "Наука"}, nil
+	case testSubcategoryUUID:
+		return db.GetCategoryByUUIDRow{Uuid: uuid, Name: "Уровень", ParentCategory: root}, nil
+	default:
+		return db.GetCategoryByUUIDRow{}, pgx.ErrNoRows
+	}
+}
+
+func TestCheckSimulatedCategories(t *testing.T) {
+	values := []requests.CategoryValues{{Name: "Международный", Points: 5}}
+
+	tests := []struct {
+		name    string
+		change  requests.UpdateCategory
+		wantErr error
+	}{
+		{"root category", requests.UpdateCategory{UUID: testRootCategoryUUID, Points: 10}, nil},
+		{"subcategory", requests.UpdateCategory{UUID: testSubcategoryUUID, Values: values}, nil},
+		{"unknown category", requests.UpdateCategory{UUID: "00000000-0000-0000-0000-0000000000ff", Points: 10}, validation.NoDataFoundErr},
+		{"malformed uuid", requests.UpdateCategory{UUID: "not-a-uuid", Points: 10}, validation.NoDataFoundErr},
+		{"values of a root category", requests.UpdateCategory{UUID: testRootCategoryUUID, Values: values}, validation.WrongInputErr},
+		{"points of a subcategory", requests.UpdateCategory{UUID: testSubcategoryUUID, Points: 10}, validation.WrongInputErr},
+	}
+
+	s := &ratingService{categoryRepo: fakeCategoryRepo{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkSimulatedCategories(context.Background(), []requests.UpdateCategory{tt.change})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeReportRepos serve one period with its close-out snapshot and the used
+// achievements of the period.
+type fakeReportRepos struct {
+	repository.PeriodRepository
+	repository.RatingSnapshotRepository
+
+	period   models.Period
+	snapshot models.RatingSnapshot
+	used     []models.AchievementPoints
+}
+
+func (r *fakeReportRepos) GetPeriodByUUID(context.Context, string) (models.Period, error) {
+	return r.period, nil
+}
+
+func (r *fakeReportRepos) GetCloseOutSnapshot(context.Context, string) (models.RatingSnapshot, error) {
+	return r.snapshot, nil
+}
+
+func (r *fakeReportRepos) GetPeriodAchievementPoints(_ context.Context, _ models.Period, statuses []string) ([]models.AchievementPoints, error) {
+	if !slices.Equal(statuses, []string{achievementStatusUsed}) {
+		return nil, errors.New("the closed period must not be ranked again")
+	}
+	return r.used, nil
+}
+
+type fakeRenderer struct {
+	list models.WinnersList
+	err  error
+}
+
+func (r *fakeRenderer) RenderWinners(_ io.Writer, list models.WinnersList) error {
+	r.list = list
+	return r.err
+}
+
+func newClosedPeriodReportRepos() *fakeReportRepos {
+	achievementDate := time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC)
+
+	return &fakeReportRepos{
+		period: models.Period{UUID: "p", Name: "Весна 2026", Status: models.PeriodStatusArchived, WinnerCount: 1},
+		snapshot: models.RatingSnapshot{Entries: []models.RatingSnapshotEntry{
+			{
+				UserUUID:    "a",
+				Position:    1,
+				PointAmount: 7,
+				IsWinner:    true,
+				Achievements: []models.AchievementPoints{
+					{AchievementUUID: "a-0", CategoryName: "Наука", PointAmount: 7},
+				},
+			},
+			{UserUUID: "b", Position: 2, PointAmount: 5},
+		}},
+		used: []models.AchievementPoints{
+			{AchievementUUID: "a-0", PointAmount: 9, ScoreInput: models.AchievementScoreInput{AchievementDate: achievementDate}},
+		},
+	}
+}
+
+func TestWinnersReportClosedPeriod(t *testing.T) {
+	repos := newClosedPeriodReportRepos()
+	renderer := &fakeRenderer{}
+	s := &ratingService{periodRepo: repos, snapshotRepo: repos, reportRenderer: renderer}
+
+	err := s.WinnersReport(context.Background(), requests.WinnersReport{PeriodUUID: "p", Appendix: true}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	winners := renderer.list.Winners
+	if len(winners) != 1 || winners[0].UserUUID != "a" {
+		t.Fatalf("winners = %+v, want the winner of the snapshot", winners)
+	}
+
+	achievement := winners[0].Achievements[0]
+	if achievement.PointAmount != 7 {
+		t.Errorf("points = %v, want 7 as frozen", achievement.PointAmount)
+	}
+	if achievement.ScoreInput.AchievementDate.IsZero() {
+		t.Error("the achievement date of the appendix is not filled")
+	}
+}
+
+func TestWinnersReportWithoutFont(t *testing.T) {
+	repos := newClosedPeriodReportRepos()
+	s := &ratingService{periodRepo: repos, snapshotRepo: repos, reportRenderer: &fakeRenderer{err: report.ErrFontNotConfigured}}
+
+	err := s.WinnersReport(context.Background(), requests.WinnersReport{PeriodUUID: "p"}, io.Discard)
+	if !errors.Is(err, unexpected.NotConfiguredErr) {
+		t.Fatalf("err = %v, want NotConfiguredErr", err)
+	}
+}
+
+// fakeRatingRepos serve one period with the points of its achievements in the
+// requested statuses and the org units.
+type fakeRatingRepos struct {
+	repository.PeriodRepository
+	repository.OrgUnitRepository
+
+	period models.Period
+	points []models.AchievementPoints
+	units  []models.OrgUnit
+}
+
+func (r *fakeRatingRepos) GetPeriodByUUID(context.Context, string) (models.Period, error) {
+	return r.period, nil
+}
+
+func (r *fakeRatingRepos) GetPeriodAchievementPoints(_ context.Context, _ models.Period, statuses []string) ([]models.AchievementPoints, error) {
+	points := make([]models.AchievementPoints, 0, len(r.points))
+	for _, point := range r.points {
+		if slices.Contains(statuses, point.ScoreInput.Status) {
+			points = append(points, point)
+		}
+	}
+	return points, nil
+}
+
+func (r *fakeRatingRepos) ListOrgUnits(context.Context) ([]models.OrgUnit, error) {
+	return r.units, nil
+}
+
+func (r *fakeRatingRepos) GetOrgUnitByUUID(_ context.Context, uuid string) (models.OrgUnit, error) {
+	for _, unit := range r.units {
+		if unit.UUID == uuid {
+			return unit, nil
+		}
+	}
+	return models.OrgUnit{}, validation.NoDataFoundErr
+}
+
+func (r *fakeRatingRepos) GetOrgUnitSubtree(_ context.Context, uuid string) ([]string, error) {
+	return []string{uuid}, nil
+}
+
+// fakeScoringService keeps the points the achievements were loaded with.
+type fakeScoringService struct {
+	ScoringService
+}
+
+func (fakeScoringService) ScorePoints(points []models.AchievementPoints) []models.AchievementPoints {
+	return points
+}
+
+func (fakeScoringService) ScoreRating(context.Context, models.ScoringFilter) (map[string]float64, error) {
+	return map[string]float64{}, nil
+}
+
+// testUnitPoints places students a and b in the unit "u1" and c in "u2".
+func testUnitPoints() []models.AchievementPoints {
+	points := testAchievementPoints(
+		testStudent{uuid: "a", points: []float64{10}},
+		testStudent{uuid: "b", points: []float64{9}},
+		testStudent{uuid: "c", points: []float64{8}},
+	)
+	for i := range points {
+		points[i].UserOrgUnitUUID = "u1"
+		if points[i].UserUUID == "c" {
+			points[i].UserOrgUnitUUID = "u2"
+		}
+	}
+
+	return points
+}
+
+func TestSelectWinnersUnitQuota(t *testing.T) {
+	repos := &fakeRatingRepos{units: []models.OrgUnit{{UUID: "u1", WinnerQuota: 1}, {UUID: "u2"}}}
+
+	rules, err := newSelectionRules(context.Background(), repos, NewSettingService(newFakeSettingRepo(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules.WinnerCount = 2
+
+	entries, results := selectWinners(testUnitPoints(), rules, models.RankingRules{Mode: models.RankModeCompetition})
+
+	want := []testRank{{"a", 1, true}, {"b", 2, false}, {"c", 3, true}}
+	for i, w := range want {
+		got := testRank{uuid: entries[i].UserUUID, position: entries[i].Position, winner: entries[i].IsWinner}
+		if got != w {
+			t.Errorf("entry %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if len(results[1].Reasons) != 1 || results[1].Reasons[0].Code != models.SelectionReasonUnitQuota {
+		t.Errorf("reasons of b = %+v, want the unit quota", results[1].Reasons)
+	}
+}
+
+// TestRatingFilterUnitWinners checks that the winners of a unit without a
+// quota are the winners of the whole rating, not the best of the unit.
+func TestRatingFilterUnitWinners(t *testing.T) {
+	repos := &fakeRatingRepos{
+		period: models.Period{UUID: "p", WinnerCount: 1},
+		points: testUnitPoints(),
+		units:  []models.OrgUnit{{UUID: "u1"}, {UUID: "u2"}},
+	}
+	s := &ratingService{
+		periodRepo:     repos,
+		orgUnitRepo:    repos,
+		settingService: NewSettingService(newFakeSettingRepo(nil)),
+		scoringService: fakeScoringService{},
+		rankingRules:   models.RankingRules{Mode: models.RankModeCompetition},
+	}
+
+	filter, _, err := s.newRatingFilter(context.Background(), requests.GetRating{
+		PeriodUUID:  "p",
+		OrgUnitUUID: "u2",
+		Winners:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(filter.OrgUnitUUIDs, []string{"u2"}) {
+		t.Errorf("org units = %v, want [u2]", filter.OrgUnitUUIDs)
+	}
+	if !slices.Equal(filter.WinnerUUIDs, []string{"a"}) {
+		t.Errorf("winners = %v, want [a]", filter.WinnerUUIDs)
+	}
+}
+
+// TestStoredSelectionRules checks that the stored rules pick the winners of
+// close-out and of the rating the same way.
+func TestStoredSelectionRules(t *testing.T) {
+	points := testAchievementPoints(
+		testStudent{uuid: "a", points: []float64{10}},
+		testStudent{uuid: "b", points: []float64{9}},
+		testStudent{uuid: "c", points: []float64{4}},
+	)
+	for i := range points {
+		points[i].CategoryUUID = "science"
+		if points[i].UserUUID == "b" {
+			points[i].CategoryUUID = "sport"
+		}
+	}
+
+	repos := &fakeRatingRepos{
+		period: models.Period{UUID: "p", Name: "Весна 2026", Status: models.PeriodStatusOpen, WinnerCount: 3},
+		points: points,
+	}
+	settingService := NewSettingService(newFakeSettingRepo(map[string]string{
+		settings.WinnerSelection: `{"min_points":5,"require_approved":true,"require_verified":false,"category_quotas":{"science":1}}`,
+	}))
+	ranking := models.RankingRules{Mode: models.RankModeCompetition}
+	want := []string{"a", "b"}
+
+	periods := &periodService{
+		orgUnitRepo:    repos,
+		settingService: settingService,
+		scoringService: fakeScoringService{},
+		rankingRules:   ranking,
+	}
+	rules, err := newSelectionRules(context.Background(), repos, settingService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeOut, err := periods.newPeriodCloseOut(context.Background(), repos.period, points, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closeOutWinners := make([]string, 0)
+	for _, entry := range closeOut.Snapshot.Entries {
+		if entry.IsWinner {
+			closeOutWinners = append(closeOutWinners, entry.UserUUID)
+		}
+	}
+	if !slices.Equal(closeOutWinners, want) {
+		t.Errorf("close-out winners = %v, want %v", closeOutWinners, want)
+	}
+	if !slices.Equal(closeOut.UsedAchievements, []string{"a-0", "b-0"}) {
+		t.Errorf("used achievements = %v, want the achievements of a and b", closeOut.UsedAchievements)
+	}
+
+	s := &ratingService{
+		periodRepo:     repos,
+		orgUnitRepo:    repos,
+		settingService: settingService,
+		scoringService: fakeScoringService{},
+		rankingRules:   ranking,
+	}
+	ratingWinners, err := s.winnerUUIDs(context.Background(), repos.period, repos.period.WinnerCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ratingWinners, want) {
+		t.Errorf("rating winners = %v, want %v", ratingWinners, want)
+	}
+}
+
+// TestWinnersAgree checks that close-out, the winners report and the rating
+// filtered by winners pick the same students on the same achievements. An
+// unapproved achievement would put b ahead of everyone if it counted.
+func TestWinnersAgree(t *testing.T) {
+	repos := &fakeRatingRepos{
+		period: models.Period{UUID: "p", Name: "Весна 2026", Status: models.PeriodStatusOpen, WinnerCount: 2},
+		points: testAchievementPoints(
+			testStudent{uuid: "a", points: []float64{10}},
+			testStudent{uuid: "b", points: []float64{6, 8}, statuses: []string{achievementStatusApproved, achievementStatusUnapproved}},
+			testStudent{uuid: "c", points: []float64{4, 5}, statuses: []string{achievementStatusUsed, achievementStatusApproved}},
+		),
+	}
+	settingService := NewSettingService(newFakeSettingRepo(nil))
+	ranking := models.RankingRules{Mode: models.RankModeCompetition}
+	want := []string{"a", "c"}
+
+	periods := &periodService{
+		periodRepo:     repos,
+		orgUnitRepo:    repos,
+		settingService: settingService,
+		scoringService: fakeScoringService{},
+		rankingRules:   ranking,
+	}
+	closeOut, err := periods.PreviewPeriodCloseOut(context.Background(), "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeOutWinners := make([]string, 0)
+	for _, entry := range closeOut.Ranking {
+		if entry.IsWinner {
+			closeOutWinners = append(closeOutWinners, entry.UserUUID)
+		}
+	}
+	if !slices.Equal(closeOutWinners, want) {
+		t.Errorf("close-out winners = %v, want %v", closeOutWinners, want)
+	}
+	if closeOut.UsedAchievementAmount != 2 {
+		t.Errorf("used achievements = %d, want the approved achievements of a and c", closeOut.UsedAchievementAmount)
+	}
+
+	users := &fakeRatingUserRepo{}
+	s := &ratingService{
+		userRepo:       users,
+		periodRepo:     repos,
+		orgUnitRepo:    repos,
+		settingService: settingService,
+		scoringService: fakeScoringService{},
+		rankingRules:   ranking,
+	}
+	report, err := s.reportWinners(context.Background(), repos.period, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportWinners := make([]string, len(report))
+	for i, entry := range report {
+		reportWinners[i] = entry.UserUUID
+	}
+	if !slices.Equal(reportWinners, want) {
+		t.Errorf("report winners = %v, want %v", reportWinners, want)
+	}
+
+	if _, _, err = s.GetRating(context.Background(), requests.GetRating{PeriodUUID: "p", Winners: true}); err != nil {
+		t.Fatal(err)
+	}
+	if ratingWinners := users.filters[0].WinnerUUIDs; !slices.Equal(ratingWinners, want) {
+		t.Errorf("rating winners = %v, want %v", ratingWinners, want)
+	}
+}
+
+// TestWinnerRules covers what the rule version of a snapshot depends on
+// besides the scoring rules.
+func TestWinnerRules(t *testing.T) {
+	ranking := models.RankingRules{Mode: models.RankModeCompetition, TieBreakers: []string{"achievement_amount"}}
+	rules := func() models.SelectionRules {
+		return models.SelectionRules{
+			WinnerCount:    3,
+			UnitQuotas:     map[string]int{"faculty": 2, "group": 1},
+			UnitParents:    map[string]string{"group": "faculty"},
+			CategoryQuotas: map[string]int{},
+		}
+	}
+	base := newWinnerRules(rules(), ranking)
+
+	reordered := rules()
+	reordered.UnitQuotas = map[string]int{"group": 1, "faculty": 2}
+	if got := newWinnerRules(reordered, ranking); got != base {
+		t.Fatalf("equal rules differ:\n%s\n%s", got, base)
+	}
+
+	changes := map[string]func(rules *models.SelectionRules, ranking *models.RankingRules){
+		"rank mode":        func(_ *models.SelectionRules, r *models.RankingRules) { r.Mode = models.RankModeDense },
+		"tie-breakers":     func(_ *models.SelectionRules, r *models.RankingRules) { r.TieBreakers = nil },
+		"winner count":     func(s *models.SelectionRules, _ *models.RankingRules) { s.WinnerCount = 4 },
+		"unit quota":       func(s *models.SelectionRules, _ *models.RankingRules) { s.UnitQuotas["group"] = 2 },
+		"min points":       func(s *models.SelectionRules, _ *models.RankingRules) { s.MinPoints = 5 },
+		"require approved": func(s *models.SelectionRules, _ *models.RankingRules) { s.RequireApproved = true },
+		"category quota":   func(s *models.SelectionRules, _ *models.RankingRules) { s.CategoryQuotas["science"] = 1 },
+	}
+	for name, change := range changes {
+		changed, changedRanking := rules(), ranking
+		changedRanking.TieBreakers = slices.Clone(ranking.TieBreakers)
+		change(&changed, &changedRanking)
+
+		if newWinnerRules(changed, changedRanking) == base {
+			t.Errorf("%s does not change the winner rules", name)
+		}
+	}
+}
+
+// fakeRatingUserRepo records the rating filters it is queried with.
+type fakeRatingUserRepo struct {
+	repository.UserRepository
+
+	filters []models.RatingFilter
+}
+
+func (r *fakeRatingUserRepo) GetRating(_ context.Context, filter models.RatingFilter) ([]responses.User, int, error) {
+	r.filters = append(r.filters, filter)
+	return nil, 0, nil
+}
+
+func (r *fakeRatingUserRepo) GetShortInfoRating(_ context.Context, _ string, filter models.RatingFilter) (responses.RatingShortInfo, error) {
+	r.filters = append(r.filters, filter)
+	return responses.RatingShortInfo{}, nil
+}
+
+// TestShortInfoMatchesRating covers the short info ranking the same students
+// the same way as the default rating.
+func TestShortInfoMatchesRating(t *testing.T) {
+	users := &fakeRatingUserRepo{}
+	s := &ratingService{
+		userRepo:     users,
+		rankingRules: models.RankingRules{Mode: models.RankModeDense, TieBreakers: []string{models.TieBreakerAchievements}},
+	}
+
+	if _, _, err := s.GetRating(context.Background(), requests.GetRating{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetShortInfo(context.Background(), testOwnerUUID); err != nil {
+		t.Fatal(err)
+	}
+
+	rating, shortInfo := users.filters[0], users.filters[1]
+	if rating.Valid != shortInfo.Valid || rating.Winners != shortInfo.Winners ||
+		!maps.Equal(rating.Scores, shortInfo.Scores) ||
+		!slices.Equal(rating.OrgUnitUUIDs, shortInfo.OrgUnitUUIDs) ||
+		rating.Ranking.Mode != shortInfo.Ranking.Mode ||
+		!slices.Equal(rating.Ranking.TieBreakers, shortInfo.Ranking.TieBreakers) {
+		t.Fatalf("short info filter %+v differs from the rating filter %+v", shortInfo, rating)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/scoring"
+	"github.com/igntnk/scholarship_point_system/service/models"
+)
+
+// ScoringService gives the API access to the scoring rules of the scoring
+// package. Achievement lists, the live rating, snapshots and period close-out
+// all take their points from it, the queries only load the score components.
+type ScoringService interface {
+	// ScoreAchievement applies the single achievement rules. The flag is false
+	// when the achievement is not scored.
+	ScoreAchievement(input models.AchievementScoreInput) (float64, bool)
+	// ScoreAchievements returns the points of the selected achievements by uuid
+	// before the category rules. Achievements that are not scored are left out.
+	ScoreAchievements(ctx context.Context, filter models.ScoringFilter) (map[string]float64, error)
+	// ScoreRating returns the points the selected achievements bring to the
+	// rating by uuid. Achievements that are not counted are left out.
+	ScoreRating(ctx context.Context, filter models.ScoringFilter) (map[string]float64, error)
+	// ScorePoints fills the rating points of achievements loaded with their
+	// score components and drops the ones that are not counted.
+	ScorePoints(points []models.AchievementPoints) []models.AchievementPoints
+	// ScoreCategories returns the rating points of the selected achievements
+	// per student and root category.
+	ScoreCategories(ctx context.Context, filter models.ScoringFilter) (models.CategoryScores, error)
+	// Breakdown explains the live rating total of the student.
+	Breakdown(ctx context.Context, userUUID string) (models.ScoreBreakdown, error)
+	// RebuildStudentScores recomputes the stored score of every student and
+	// returns how many students have one.
+	RebuildStudentScores(ctx context.Context) (int, error)
+	BackfillStudentScores(ctx context.Context) (int, error)
+}
+
+type scoringService struct {
+	scoringRepo repository.ScoringRepository
+}
+
+func NewScoringService(scoringRepo repository.ScoringRepository) ScoringService {
+	return &scoringService{
+		scoringRepo: scoringRepo,
+	}
+}
+
+func (s *scoringService) ScoreAchievement(input models.AchievementScoreInput) (float64, bool) {
+	return scoring.Score(input)
+}
+
+func (s *scoringService) ScoreAchievements(ctx context.Context, filter models.ScoringFilter) (map[string]float64, error) {
+	inputs, err := s.getScoreInputs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64, len(inputs))
+	for _, input := range inputs {
+		if points, ok := scoring.Score(input); ok {
+			scores[input.AchievementUUID] = points
+		}
+	}
+
+	return scores, nil
+}
+
+func (s *scoringService) ScoreRating(ctx context.Context, filter models.ScoringFilter) (map[string]float64, error) {
+	inputs, err := s.getScoreInputs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return scoring.ScoreRating(inputs), nil
+}
+
+func (s *scoringService) ScorePoints(points []models.AchievementPoints) []models.AchievementPoints {
+	inputs := make([]models.AchievementScoreInput, len(points))
+	for i, point := range points {
+		inputs[i] = point.ScoreInput
+	}
+
+	scores := scoring.ScoreRating(inputs)
+
+	scored := make([]models.AchievementPoints, 0, len(points))
+	for _, point := range points {
+		pointAmount, ok := scores[point.AchievementUUID]
+		if !ok {
+			continue
+		}
+
+		point.PointAmount = pointAmount
+		scored = append(scored, point)
+	}
+
+	return scored
+}
+
+func (s *scoringService) ScoreCategories(ctx context.Context, filter models.ScoringFilter) (models.CategoryScores, error) {
+	inputs, err := s.getScoreInputs(ctx, filter)
+	if err != nil {
+		return models.CategoryScores{}, err
+	}
+
+	return scoring.CategoryScores(inputs), nil
+}
+
+func (s *scoringService) Breakdown(ctx context.Context, userUUID string) (models.ScoreBreakdown, error) {
+	inputs, err := s.getScoreInputs(ctx, models.ScoringFilter{UserUUIDs: []string{userUUID}})
+	if err != nil {
+		return models.ScoreBreakdown{}, err
+	}
+
+	return scoring.Breakdown(userUUID, inputs), nil
+}
+
+func (s *scoringService) RebuildStudentScores(ctx context.Context) (int, error) {
+	return s.scoringRepo.RebuildStudentScores(ctx)
+}
+
+// BackfillStudentScores fills the stored scores once after they were
+// introduced. It does nothing while any score is stored, so running it on
+// every start is cheap.
+func (s *scoringService) BackfillStudentScores(ctx context.Context) (int, error) {
+	exists, err := s.scoringRepo.HasStudentScores(ctx)
+	if err != nil || exists {
+		return 0, err
+	}
+
+	return s.scoringRepo.RebuildStudentScores(ctx)
+}
+
+func (s *scoringService) getScoreInputs(ctx context.Context, filter models.ScoringFilter) ([]models.AchievementScoreInput, error) {
+	if filter.AchievementUUIDs != nil && len(filter.AchievementUUIDs) == 0 {
+		return []models.AchievementScoreInput{}, nil
+	}
+
+	return s.scoringRepo.GetAchievementScoreInputs(ctx, filter)
+}
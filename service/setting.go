@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/igntnk/scholarship_point_system/audit"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/controllers/responses"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/repository"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/igntnk/scholarship_point_system/settings"
+	"sync"
+	"time"
+)
+
+// settingCacheTTL bounds how long a cached value may miss a change made by
+// another instance of the server. Changes made through this instance drop the
+// cache at once.
+const settingCacheTTL = time.Minute
+
+// SettingService gives access to the settings declared in the settings
+// package. The typed getters are meant for the other services and read the
+// values from an in-memory cache.
+type SettingService interface {
+	ListSettings(ctx context.Context) ([]responses.Setting, error)
+	GetSetting(ctx context.Context, key string) (responses.Setting, error)
+	UpdateSetting(ctx context.Context, key string, req requests.UpdateSetting) (responses.Setting, error)
+	ListSettingChanges(ctx context.Context, key string, limit, offset int) ([]responses.SettingChange, int, error)
+
+	Int(ctx context.Context, key string) (int, error)
+	Float(ctx context.Context, key string) (float64, error)
+	Bool(ctx context.Context, key string) (bool, error)
+	String(ctx context.Context, key string) (string, error)
+	Duration(ctx context.Context, key string) (time.Duration, error)
+	// JSON unmarshals the value of a JSON setting into dst.
+	JSON(ctx context.Context, key string, dst any) error
+}
+
+type cachedSetting struct {
+	definition settings.Definition
+	value      any
+	stored     models.Setting
+	isDefault  bool
+}
+
+type settingState struct {
+	Value any `json:"value"`
+}
+
+type settingService struct {
+	settingRepo repository.SettingRepository
+
+	mu       sync.RWMutex
+	cache    map[string]cachedSetting
+	loadedAt time.Time
+	// generation changes on every invalidation, so a load that started before
+	// a change does not put the old values back.
+	generation int
+}
+
+func NewSettingService(settingRepo repository.SettingRepository) SettingService {
+	return &settingService{
+		settingRepo: settingRepo,
+	}
+}
+
+func (s *settingService) ListSettings(ctx context.Context) ([]responses.Setting, error) {
+	cache, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]responses.Setting, 0, len(cache))
+	for _, definition := range settings.Definitions() {
+		setting, err := newSettingResponse(cache[definition.Key])
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, setting)
+	}
+
+	return resp, nil
+}
+
+func (s *settingService) GetSetting(ctx context.Context, key string) (responses.Setting, error) {
+	cached, err := s.get(ctx, key)
+	if err != nil {
+		return responses.Setting{}, err
+	}
+
+	return newSettingResponse(cached)
+}
+
+func (s *settingService) UpdateSetting(ctx context.Context, key string, req requests.UpdateSetting) (responses.Setting, error) {
+	definition, err := settings.Lookup(key)
+	if err != nil {
+		return responses.Setting{}, errors.Join(validation.NoDataFoundErr, err)
+	}
+
+	raw, value, err := definition.Decode(req.Value)
+	if err != nil {
+		return responses.Setting{}, errors.Join(validation.WrongInputErr, err)
+	}
+
+	// The repository reads the old value under a row lock, so the history
+	// does not miss a value changed by another request or instance meanwhile.
+	change := models.SettingChange{
+		Key:       key,
+		OldValue:  definition.Default,
+		NewValue:  raw,
+		ChangedBy: audit.MetaFromContext(ctx).ActorUUID,
+	}
+
+	err = s.settingRepo.UpdateSetting(ctx, change, func(oldValue string) models.AuditEvent {
+		return newAuditEvent(ctx, audit.ActionSettingUpdate, audit.EntitySetting, key,
+			settingState{Value: exportStoredSetting(definition, oldValue)},
+			settingState{Value: settings.Export(value)},
+		)
+	})
+	s.invalidate()
+	if err != nil {
+		return responses.Setting{}, err
+	}
+
+	return s.GetSetting(ctx, key)
+}
+
+func (s *settingService) ListSettingChanges(ctx context.Context, key string, limit, offset int) ([]responses.SettingChange, int, error) {
+	definition, err := settings.Lookup(key)
+	if err != nil {
+		return nil, 0, errors.Join(validation.NoDataFoundErr, err)
+	}
+
+	changes, totalRecords, err := s.settingRepo.ListSettingChanges(ctx, key, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp := make([]responses.SettingChange, len(changes))
+	for i, change := range changes {
+		resp[i] = responses.SettingChange{
+			UUID:      change.UUID,
+			Key:       change.Key,
+			OldValue:  exportStoredSetting(definition, change.OldValue),
+			NewValue:  exportStoredSetting(definition, change.NewValue),
+			ChangedBy: change.ChangedBy,
+			ChangedAt: change.ChangedAt,
+		}
+	}
+
+	return resp, totalRecords, nil
+}
+
+func (s *settingService) Int(ctx context.Context, key string) (int, error) {
+	return settingValue[int](ctx, s, key)
+}
+
+func (s *settingService) Float(ctx context.Context, key string) (float64, error) {
+	return settingValue[float64](ctx, s, key)
+}
+
+func (s *settingService) Bool(ctx context.Context, key string) (bool, error) {
+	return settingValue[bool](ctx, s, key)
+}
+
+func (s *settingService) String(ctx context.Context, key string) (string, error) {
+	return settingValue[string](ctx, s, key)
+}
+
+func (s *settingService) Duration(ctx context.Context, key string) (time.Duration, error) {
+	return settingValue[time.Duration](ctx, s, key)
+}
+
+func (s *settingService) JSON(ctx context.Context, key string, dst any) error {
+	value, err := settingValue[json.RawMessage](ctx, s, key)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(value, dst); err != nil {
+		return errors.Join(err, unexpected.InternalErr)
+	}
+
+	return nil
+}
+
+// settingValue reads a setting for the code. Asking for an undeclared setting
+// or for a wrong type is a bug, so both are internal errors.
+func settingValue[T any](ctx context.Context, s *settingService, key string) (T, error) {
+	var typed T
+
+	if _, err := settings.Lookup(key); err != nil {
+		return typed, errors.Join(err, unexpected.InternalErr)
+	}
+
+	cache, err := s.load(ctx)
+	if err != nil {
+		return typed, err
+	}
+
+	cached := cache[key]
+	typed, ok := cached.value.(T)
+	if !ok {
+		return typed, errors.Join(unexpected.InternalErr, fmt.Errorf("setting %s is of type %s", key, cached.definition.Type))
+	}
+
+	return typed, nil
+}
+
+func (s *settingService) get(ctx context.Context, key string) (cachedSetting, error) {
+	if _, err := settings.Lookup(key); err != nil {
+		return cachedSetting{}, errors.Join(validation.NoDataFoundErr, err)
+	}
+
+	cache, err := s.load(ctx)
+	if err != nil {
+		return cachedSetting{}, err
+	}
+
+	return cache[key], nil
+}
+
+// load returns the values of all declared settings, reading them once per
+// settingCacheTTL. The map is never changed after it is built.
+func (s *settingService) load(ctx context.Context) (map[string]cachedSetting, error) {
+	s.mu.RLock()
+	cache, loadedAt, generation := s.cache, s.loadedAt, s.generation
+	s.mu.RUnlock()
+	if cache != nil && time.Since(loadedAt) < settingCacheTTL {
+		return cache, nil
+	}
+
+	stored, err := s.settingRepo.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storedByKey := make(map[string]models.Setting, len(stored))
+	for _, setting := range stored {
+		storedByKey[setting.Key] = setting
+	}
+
+	cache = make(map[string]cachedSetting, len(settings.Definitions()))
+	for _, definition := range settings.Definitions() {
+		cached := cachedSetting{definition: definition, isDefault: true}
+
+		raw := definition.Default
+		if setting, ok := storedByKey[definition.Key]; ok {
+			raw, cached.stored, cached.isDefault = setting.Value, setting, false
+		}
+
+		if cached.value, err = definition.Parse(raw); err != nil {
+			return nil, errors.Join(err, unexpected.InternalErr)
+		}
+
+		cache[definition.Key] = cached
+	}
+
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cache, s.loadedAt = cache, time.Now()
+	}
+	s.mu.Unlock()
+
+	return cache, nil
+}
+
+func (s *settingService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.generation++
+	s.mu.Unlock()
+}
+
+func newSettingResponse(cached cachedSetting) (responses.Setting, error) {
+	defaultValue, err := cached.definition.Parse(cached.definition.Default)
+	if err != nil {
+		return responses.Setting{}, errors.Join(err, unexpected.InternalErr)
+	}
+
+	return responses.Setting{
+		Key:         cached.definition.Key,
+		Type:        cached.definition.Type,
+		Description: cached.definition.Description,
+		Value:       settings.Export(cached.value),
+		Default:     settings.Export(defaultValue),
+		IsDefault:   cached.isDefault,
+		UpdatedAt:   cached.stored.UpdatedAt,
+		UpdatedBy:   cached.stored.UpdatedBy,
+	}, nil
+}
+
+// exportStoredSetting returns a value of the history in its JSON type. A
+// value the current definition no longer reads is returned as stored.
+func exportStoredSetting(definition settings.Definition, raw string) any {
+	value, err := definition.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	return settings.Export(value)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/igntnk/scholarship_point_system/controllers/requests"
+	"github.com/igntnk/scholarship_point_system/errors/unexpected"
+	"github.com/igntnk/scholarship_point_system/errors/validation"
+	"github.com/igntnk/scholarship_point_system/service/models"
+	"github.com/igntnk/scholarship_point_system/settings"
+	"slices"
+	"testing"
+)
+
+// fakeSettingRepo stores the settings by key and records the changes.
+type fakeSettingRepo struct {
+	stored  map[string]string
+	changes []models.SettingChange
+	events  []models.AuditEvent
+}
+
+func newFakeSettingRepo(stored map[string]string) *fakeSettingRepo {
+	if stored == nil {
+		stored = map[string]string{}
+	}
+	return &fakeSettingRepo{stored: stored}
+}
+
+func (r *fakeSettingRepo) ListSettings(context.Context) ([]models.Setting, error) {
+	settings := make([]models.Setting, 0, len(r.stored))
+	for key, value := range r.stored {
+		settings = append(settings, models.Setting{Key: key, Value: value})
+	}
+	return settings, nil
+}
+
+func (r *fakeSettingRepo) UpdateSetting(_ context.Context, change models.SettingChange, newEvent func(string) models.AuditEvent) error {
+	if stored, ok := r.stored[change.Key]; ok {
+		change.OldValue = stored
+	}
+
+	r.stored[change.Key] = change.NewValue
+	r.changes = append(r.changes, change)
+	r.events = append(r.events, newEvent(change.OldValue))
+	return nil
+}
+
+func (r *fakeSettingRepo) ListSettingChanges(context.Context, string, int, int) ([]models.SettingChange, int, error) {
+	return r.changes, len(r.changes), nil
+}
+
+func TestUpdateSettingHistory(t *testing.T) {
+	repo := newFakeSettingRepo(nil)
+	s := NewSettingService(repo)
+	ctx := actorContext(testReviewerUUID)
+
+	for _, value := range []string{`12`, `"15"`} {
+		if _, err := s.UpdateSetting(ctx, settings.WinnerCount, requests.UpdateSetting{Value: json.RawMessage(value)}); err != nil {
+			t.Fatalf("UpdateSetting(%s) = %v", value, err)
+		}
+	}
+
+	want := []models.SettingChange{
+		{Key: settings.WinnerCount, OldValue: "10", NewValue: "12", ChangedBy: testReviewerUUID},
+		{Key: settings.WinnerCount, OldValue: "12", NewValue: "15", ChangedBy: testReviewerUUID},
+	}
+	if !slices.Equal(repo.changes, want) {
+		t.Fatalf("changes = %+v, want %+v", repo.changes, want)
+	}
+
+	event := repo.events[1]
+	if event.Before != (settingState{Value: 12}) || event.After != (settingState{Value: 15}) {
+		t.Errorf("audit event = %+v -> %+v, want 12 -> 15", event.Before, event.After)
+	}
+
+	count, err := s.Int(ctx, settings.WinnerCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 15 {
+		t.Errorf("Int() = %d after the update, want 15", count)
+	}
+}
+
+func TestUpdateSettingRejected(t *testing.T) {
+	repo := newFakeSettingRepo(nil)
+	s := NewSettingService(repo)
+	ctx := actorContext(testReviewerUUID)
+
+	tests := []struct {
+		key     string
+		value   string
+		wantErr error
+	}{
+		{"no_such_setting", `1`, validation.NoDataFoundErr},
+		{settings.WinnerCount, `"ten"`, validation.WrongInputErr},
+		{settings.WinnerCount, `0`, validation.WrongInputErr},
+		{settings.WinnerSelection, `{"min_points":-1}`, validation.WrongInputErr},
+	}
+
+	for _, tt := range tests {
+		_, err := s.UpdateSetting(ctx, tt.key, requests.UpdateSetting{Value: json.RawMessage(tt.value)})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("UpdateSetting(%s, %s) = %v, want %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+	if len(repo.changes) != 0 {
+		t.Errorf("changes = %+v, want none", repo.changes)
+	}
+}
+
+func TestSettingJSON(t *testing.T) {
+	s := NewSettingService(newFakeSettingRepo(map[string]string{
+		settings.WinnerSelection: `{"min_points":2.5,"require_approved":false,"require_verified":true,"category_quotas":{"c":3}}`,
+	}))
+
+	var rules settings.SelectionRules
+	if err := s.JSON(context.Background(), settings.WinnerSelection, &rules); err != nil {
+		t.Fatal(err)
+	}
+	if rules.MinPoints != 2.5 || rules.RequireApproved || !rules.RequireVerified || rules.CategoryQuotas["c"] != 3 {
+		t.Errorf("rules = %+v", rules)
+	}
+
+	// Asking for a setting with the getter of another type is a bug.
+	if _, err := s.Int(context.Background(), settings.WinnerSelection); !errors.Is(err, unexpected.InternalErr) {
+		t.Errorf("Int() of a JSON setting = %v, want InternalErr", err)
+	}
+	if _, err := s.Duration(context.Background(), settings.WinnerCount); !errors.Is(err, unexpected.InternalErr) {
+		t.Errorf("Duration() of an int setting = %v, want InternalErr", err)
+	}
+}
@@ -0,0 +1,232 @@
+// Package settings is the registry of the runtime settings stored in the
+// setting table. Every setting is declared here with its type, default and
+// description, a stored value of an unknown setting is ignored.
+//
+// Values are stored as text:
+//
+//   - int, float and bool in their Go notation,
+//   - string as is,
+//   - duration in the notation of time.ParseDuration, like 36h or 90m,
+//   - JSON as a compact JSON document.
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	TypeInt      = "int"
+	TypeFloat    = "float"
+	TypeBool     = "bool"
+	TypeString   = "string"
+	TypeDuration = "duration"
+	TypeJSON     = "json"
+)
+
+// Keys of the declared settings.
+const (
+	// WinnerCount is the number of winners of the all-time rating and of the
+	// periods without their own winner count.
+	WinnerCount = "available_student_grades"
+	// WinnerSelection holds the SelectionRules winners are picked with on
+	// close-out, in snapshots, reports and the rating.
+	WinnerSelection = "winner_selection"
+)
+
+var (
+	ErrUnknownSetting = errors.New("Неизвестная настройка")
+	ErrWrongType      = errors.New("Значение не подходит по типу")
+)
+
+// Definition declares a setting. Validate, if set, checks a parsed value
+// before it is saved.
+type Definition struct {
+	Key         string
+	Type        string
+	Default     string
+	Description string
+	Validate    func(value any) error
+}
+
+var registry = []Definition{
+	{
+		Key:         WinnerCount,
+		Type:        TypeInt,
+		Default:     "10",
+		Description: "Количество победителей рейтинга, если у периода оно не задано",
+		Validate:    MinInt(1),
+	},
+	{
+		Key:         WinnerSelection,
+		Type:        TypeJSON,
+		Default:     `{"min_points":0,"require_approved":true,"require_verified":false,"category_quotas":{}}`,
+		Description: "Условия отбора победителей: минимум баллов, подтверждение студента, проверка достижений и квоты категорий",
+		Validate:    validSelectionRules,
+	},
+}
+
+// SelectionRules is the value of the WinnerSelection setting. CategoryQuotas
+// limit the winners by their leading root category, keyed by uuid. The quotas
+// of the org units are stored with the units.
+type SelectionRules struct {
+	MinPoints       float64        `json:"min_points"`
+	RequireApproved bool           `json:"require_approved"`
+	RequireVerified bool           `json:"require_verified"`
+	CategoryQuotas  map[string]int `json:"category_quotas"`
+}
+
+// Definitions returns the declared settings in the order they are declared.
+func Definitions() []Definition {
+	return registry
+}
+
+func Lookup(key string) (Definition, error) {
+	for _, definition := range registry {
+		if definition.Key == key {
+			return definition, nil
+		}
+	}
+
+	return Definition{}, fmt.Errorf("%w: %s", ErrUnknownSetting, key)
+}
+
+// Parse reads a stored value. An int is returned as int, a float as float64,
+// a duration as time.Duration and JSON as json.RawMessage.
+func (d Definition) Parse(raw string) (any, error) {
+	var (
+		value any
+		err   error
+	)
+	switch d.Type {
+	case TypeInt:
+		value, err = strconv.Atoi(raw)
+	case TypeFloat:
+		value, err = strconv.ParseFloat(raw, 64)
+	case TypeBool:
+		value, err = strconv.ParseBool(raw)
+	case TypeString:
+		value = raw
+	case TypeDuration:
+		value, err = time.ParseDuration(raw)
+	case TypeJSON:
+		if !json.Valid([]byte(raw)) {
+			err = errors.New("invalid JSON")
+		}
+		value = json.RawMessage(raw)
+	default:
+		err = fmt.Errorf("unknown type %s", d.Type)
+	}
+	if err != nil {
+		return nil, errors.Join(ErrWrongType, fmt.Errorf("%s: %w", d.Key, err))
+	}
+
+	return value, nil
+}
+
+// Decode reads a value sent in a request and returns it in the stored form.
+// Besides its own JSON type every type but JSON accepts a JSON string with the
+// stored form, so "10" is a valid int.
+func (d Definition) Decode(data json.RawMessage) (string, any, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return "", nil, errors.Join(ErrWrongType, fmt.Errorf("%s: no value", d.Key))
+	}
+
+	var raw string
+	switch {
+	case d.Type == TypeJSON:
+		compact := &bytes.Buffer{}
+		if err := json.Compact(compact, data); err != nil {
+			return "", nil, errors.Join(ErrWrongType, fmt.Errorf("%s: %w", d.Key, err))
+		}
+		raw = compact.String()
+	case data[0] == '"':
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return "", nil, errors.Join(ErrWrongType, fmt.Errorf("%s: %w", d.Key, err))
+		}
+	case d.Type == TypeString:
+		return "", nil, errors.Join(ErrWrongType, fmt.Errorf("%s: a string is expected", d.Key))
+	default:
+		raw = string(data)
+	}
+
+	value, err := d.Parse(raw)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if d.Validate != nil {
+		if err = d.Validate(value); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return d.Format(value), value, nil
+}
+
+// Format returns the stored form of a parsed value.
+func (d Definition) Format(value any) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Duration:
+		return v.String()
+	case json.RawMessage:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Export returns a parsed value in the shape of a JSON response. A duration
+// is written in the stored form, the rest keep their JSON type.
+func Export(value any) any {
+	if duration, ok := value.(time.Duration); ok {
+		return duration.String()
+	}
+
+	return value
+}
+
+// validSelectionRules checks a WinnerSelection value field by field, an
+// unknown field is most likely a typo.
+func validSelectionRules(value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(value.(json.RawMessage)))
+	decoder.DisallowUnknownFields()
+
+	var rules SelectionRules
+	if err := decoder.Decode(&rules); err != nil {
+		return fmt.Errorf("Неверные условия отбора: %w", err)
+	}
+
+	if rules.MinPoints < 0 {
+		return errors.New("Минимум баллов не может быть отрицательным")
+	}
+
+	for categoryUUID, quota := range rules.CategoryQuotas {
+		if quota <= 0 {
+			return fmt.Errorf("Квота категории %s должна быть больше нуля", categoryUUID)
+		}
+	}
+
+	return nil
+}
+
+// MinInt checks that an int setting is at least min.
+func MinInt(min int) func(value any) error {
+	return func(value any) error {
+		if v, ok := value.(int); ok && v < min {
+			return fmt.Errorf("Значение должно быть не меньше %d", min)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWinnerSelectionSetting(t *testing.T) {
+	definition, err := Lookup(WinnerSelection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaultValue, err := definition.Parse(definition.Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = definition.Validate(defaultValue); err != nil {
+		t.Fatalf("the default is invalid: %v", err)
+	}
+
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{`{"min_points":2.5,"require_approved":true,"require_verified":true,"category_quotas":{"c":2}}`, true},
+		{`{"min_points":0}`, true},
+		{`{"min_points":-1}`, false},
+		{`{"category_quotas":{"c":0}}`, false},
+		{`{"min_point":5}`, false},
+		{`{"min_points":"5"}`, false},
+		{`[]`, false},
+	}
+
+	for _, tt := range tests {
+		_, _, err := definition.Decode(json.RawMessage(tt.value))
+		if tt.valid && err != nil {
+			t.Errorf("Decode(%s) = %v, want nil", tt.value, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("Decode(%s) = nil, want an error", tt.value)
+		}
+	}
+}
+
+// TestDefinitionTypes decodes request values of every type and checks the
+// stored form, the parsed value and the value of the JSON response.
+func TestDefinitionTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition Definition
+		request    string
+		stored     string
+		exported   any
+	}{
+		{"int", Definition{Key: "i", Type: TypeInt}, `12`, "12", 12},
+		{"int in a string", Definition{Key: "i", Type: TypeInt}, `"12"`, "12", 12},
+		{"float", Definition{Key: "f", Type: TypeFloat}, `2.50`, "2.5", 2.5},
+		{"float in a string", Definition{Key: "f", Type: TypeFloat}, `"0.125"`, "0.125", 0.125},
+		{"bool", Definition{Key: "b", Type: TypeBool}, `true`, "true", true},
+		{"bool in a string", Definition{Key: "b", Type: TypeBool}, `"false"`, "false", false},
+		{"string", Definition{Key: "s", Type: TypeString}, `"Комиссия"`, "Комиссия", "Комиссия"},
+		{"duration", Definition{Key: "d", Type: TypeDuration}, `"90m"`, "1h30m0s", "1h30m0s"},
+		{"json", Definition{Key: "j", Type: TypeJSON}, `{ "a": [1, 2] }`, `{"a":[1,2]}`, json.RawMessage(`{"a":[1,2]}`)},
+		{"json string", Definition{Key: "j", Type: TypeJSON}, `"x"`, `"x"`, json.RawMessage(`"x"`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, value, err := tt.definition.Decode(json.RawMessage(tt.request))
+			if err != nil {
+				t.Fatalf("Decode(%s) = %v", tt.request, err)
+			}
+			if stored != tt.stored {
+				t.Errorf("stored = %q, want %q", stored, tt.stored)
+			}
+
+			parsed, err := tt.definition.Parse(stored)
+			if err != nil {
+				t.Fatalf("Parse(%q) = %v", stored, err)
+			}
+			if formatted := tt.definition.Format(parsed); formatted != stored {
+				t.Errorf("Format(Parse(%q)) = %q", stored, formatted)
+			}
+
+			got, err := json.Marshal(Export(value))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := json.Marshal(tt.exported)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Export = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDefinitionWrongType(t *testing.T) {
+	tests := []struct {
+		definition Definition
+		request    string
+	}{
+		{Definition{Key: "i", Type: TypeInt}, `1.5`},
+		{Definition{Key: "i", Type: TypeInt}, `"ten"`},
+		{Definition{Key: "f", Type: TypeFloat}, `true`},
+		{Definition{Key: "b", Type: TypeBool}, `"yes please"`},
+		{Definition{Key: "s", Type: TypeString}, `5`},
+		{Definition{Key: "d", Type: TypeDuration}, `90`},
+		{Definition{Key: "d", Type: TypeDuration}, `"1 day"`},
+		{Definition{Key: "j", Type: TypeJSON}, `{"a":`},
+		{Definition{Key: "i", Type: TypeInt}, `null`},
+		{Definition{Key: "i", Type: TypeInt}, ``},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := tt.definition.Decode(json.RawMessage(tt.request)); !errors.Is(err, ErrWrongType) {
+			t.Errorf("%s: Decode(%s) = %v, want ErrWrongType", tt.definition.Type, tt.request, err)
+		}
+	}
+}
+
+func TestDefinitionValidate(t *testing.T) {
+	definition, err := Lookup(WinnerCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = definition.Decode(json.RawMessage(`0`)); err == nil {
+		t.Error("Decode(0) = nil, want the MinInt error")
+	}
+	if _, _, err = definition.Decode(json.RawMessage(`1`)); err != nil {
+		t.Errorf("Decode(1) = %v, want nil", err)
+	}
+}
+
+func TestRegistryDefaults(t *testing.T) {
+	for _, definition := range Definitions() {
+		if _, err := definition.Parse(definition.Default); err != nil {
+			t.Errorf("default of %s: %v", definition.Key, err)
+		}
+	}
+
+	if _, err := Lookup("no_such_setting"); !errors.Is(err, ErrUnknownSetting) {
+		t.Errorf("Lookup() = %v, want ErrUnknownSetting", err)
+	}
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+// testBlobStore checks the behaviour every BlobStore must share.
+func testBlobStore(t *testing.T, store BlobStore) {
+	ctx := context.Background()
+	key := "achievements/test/" + t.Name()
+	content := []byte("%PDF-1.4 test evidence")
+
+	if err := store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put() = %v", err)
+	}
+
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf("read blob: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("Get() = %q, want %q", got, content)
+	}
+
+	if err = store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() = %v", err)
+	}
+
+	if _, err = store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() after Delete() = %v, want ErrNotFound", err)
+	}
+
+	for _, invalid := range []string{"", "/abs", "a/../b", "a//b", `a\b`, "./a"} {
+		if err = store.Put(ctx, invalid, bytes.NewReader(content), int64(len(content)), ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", invalid, err)
+		}
+		if _, err = store.Get(ctx, invalid); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) = %v, want ErrInvalidKey", invalid, err)
+		}
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() = %v", err)
+	}
+
+	testBlobStore(t, store)
+}
+
+// TestS3Store runs against an S3-compatible service, e.g. the minio service
+// of docker/docker-compose.yaml:
+//
+//	SPS_TEST_S3_ENDPOINT=localhost:9000 SPS_TEST_S3_ACCESS_KEY=minioadmin \
+//	SPS_TEST_S3_SECRET_KEY=minioadmin go test ./storage/
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("SPS_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("SPS_TEST_S3_ENDPOINT is not set")
+	}
+
+	store, err := NewS3Store(context.Background(), S3Config{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("SPS_TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("SPS_TEST_S3_SECRET_KEY"),
+		Bucket:    "sps-attachments-test",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store() = %v", err)
+	}
+
+	testBlobStore(t, store)
+}
@@ -27,6 +27,7 @@ func New(logger zerolog.Logger, port int, corsCfg config.CorsConfig,
 	r := gin.New()
 	r.Use(middleware.NewCORS(corsCfg))
 	r.Use(gin.Recovery())
+	r.Use(middleware.NewRequestMeta())
 
 	for i := 0; i < len(ctrl); i++ {
 		ctrl[i].Register(r)